@@ -0,0 +1,28 @@
+package ami
+
+import (
+	"fmt"
+)
+
+// Query identifies the AMI looked up for nodes, which is the latest amd64 one of the host OS for the region and the release channel
+type Query struct {
+	OS     string `json:"os" yaml:"os"`
+	Region string `json:"region" yaml:"region"`
+	// Channel is empty for the OSes without release channels
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+}
+
+func (q Query) String() string {
+	if q.Channel == "" {
+		return fmt.Sprintf("%s/%s", q.OS, q.Region)
+	}
+	return fmt.Sprintf("%s/%s/%s", q.OS, q.Region, q.Channel)
+}
+
+// Source looks up the latest AMIs of a host OS
+type Source interface {
+	// Lookup returns the ID of the latest AMI matching the query
+	Lookup(q Query) (string, error)
+	// HasChannels is true when the AMIs are released per release channel. Otherwise the channel of queries is ignored
+	HasChannels() bool
+}
@@ -0,0 +1,23 @@
+package ami
+
+import (
+	"github.com/kubernetes-incubator/kube-aws/flatcar/amiregistry"
+)
+
+// FlatcarFeed looks up the AMIs of Flatcar from the release feed of the channel
+type FlatcarFeed struct {
+	Feed *amiregistry.Feed
+}
+
+// NewFlatcarFeed returns the source looking up the AMIs from the feeds served by flatcar-linux.net
+func NewFlatcarFeed() FlatcarFeed {
+	return FlatcarFeed{Feed: amiregistry.DefaultFeed()}
+}
+
+func (s FlatcarFeed) Lookup(q Query) (string, error) {
+	return s.Feed.GetAMI(q.Region, q.Channel)
+}
+
+func (FlatcarFeed) HasChannels() bool {
+	return true
+}
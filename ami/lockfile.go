@@ -0,0 +1,82 @@
+package ami
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/go-yaml/yaml"
+)
+
+// LockfileName is the name of the lockfile read from the directory containing cluster.yaml
+const LockfileName = "ami-lock.yaml"
+
+const lockfileHeader = `# The AMIs pinned by kube-aws, which nodes run instead of the latest AMIs of their host OSes.
+# Commit this file along with cluster.yaml so that renders are reproducible, and run ` + "`kube-aws ami update`" + ` to upgrade them.
+`
+
+// Pin is an AMI pinned for the query
+type Pin struct {
+	Query `yaml:",inline"`
+	ID    string `yaml:"id"`
+}
+
+// Lockfile pins the AMIs looked up for nodes, so that nodes aren't replaced whenever a new AMI is released
+type Lockfile struct {
+	AMIs []Pin `yaml:"amis"`
+}
+
+// LoadLockfile reads the lockfile at `path`. A missing lockfile is read as an empty one
+func LoadLockfile(path string) (*Lockfile, error) {
+	l := &Lockfile{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(data, l); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	for _, p := range l.AMIs {
+		if p.ID == "" {
+			return nil, fmt.Errorf("invalid %s: id is missing for %s", path, p.Query)
+		}
+	}
+	return l, nil
+}
+
+// Find returns the ID of the AMI pinned for the query
+func (l *Lockfile) Find(q Query) (string, bool) {
+	for _, p := range l.AMIs {
+		if p.Query == q {
+			return p.ID, true
+		}
+	}
+	return "", false
+}
+
+// Pin pins the AMI for the query, replacing the one pinned before
+func (l *Lockfile) Pin(q Query, id string) {
+	for i := range l.AMIs {
+		if l.AMIs[i].Query == q {
+			l.AMIs[i].ID = id
+			return
+		}
+	}
+	l.AMIs = append(l.AMIs, Pin{Query: q, ID: id})
+}
+
+// Save writes the lockfile to `path`, sorting the pins so that diffs stay small
+func (l *Lockfile) Save(path string) error {
+	sort.Slice(l.AMIs, func(i, j int) bool {
+		return l.AMIs[i].Query.String() < l.AMIs[j].Query.String()
+	})
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %v", path, err)
+	}
+	return ioutil.WriteFile(path, append([]byte(lockfileHeader), data...), 0644)
+}
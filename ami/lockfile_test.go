@@ -0,0 +1,54 @@
+package ami
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube-aws-ami")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, LockfileName)
+
+	l, err := LoadLockfile(path)
+	require.NoError(t, err)
+	assert.Empty(t, l.AMIs)
+
+	flatcar := Query{OS: "flatcar", Region: "us-west-2", Channel: "stable"}
+	amazonLinux2 := Query{OS: "amazon-linux-2", Region: "us-west-2"}
+
+	l.Pin(flatcar, "ami-1")
+	l.Pin(amazonLinux2, "ami-2")
+	l.Pin(flatcar, "ami-3")
+	require.NoError(t, l.Save(path))
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, lockfileHeader+`amis:
+- os: amazon-linux-2
+  region: us-west-2
+  id: ami-2
+- os: flatcar
+  region: us-west-2
+  channel: stable
+  id: ami-3
+`, string(data))
+
+	l, err = LoadLockfile(path)
+	require.NoError(t, err)
+	id, ok := l.Find(flatcar)
+	assert.True(t, ok)
+	assert.Equal(t, "ami-3", id)
+	_, ok = l.Find(Query{OS: "flatcar", Region: "us-west-2", Channel: "beta"})
+	assert.False(t, ok)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("amis:\n- os: flatcar\n  region: us-west-2\n  channel: stable\n"), 0644))
+	_, err = LoadLockfile(path)
+	assert.EqualError(t, err, "invalid "+path+": id is missing for flatcar/us-west-2/stable")
+}
@@ -0,0 +1,73 @@
+package ami
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// ParameterStore gets the values of SSM parameters
+type ParameterStore interface {
+	GetParameter(region, name string) (string, error)
+}
+
+// awsParameterStore gets the parameters from AWS with the default credentials.
+// Values are cached so that a cluster with many node pools gets each parameter once
+type awsParameterStore struct {
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+var defaultParameterStore = &awsParameterStore{cache: map[string]string{}}
+
+func (s *awsParameterStore) GetParameter(region, name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := region + name
+	if v, ok := s.cache[key]; ok {
+		return v, nil
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            aws.Config{Region: aws.String(region)},
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to establish aws session: %v", err)
+	}
+	out, err := ssm.New(sess).GetParameter(&ssm.GetParameterInput{Name: aws.String(name)})
+	if err != nil {
+		return "", err
+	}
+
+	s.cache[key] = aws.StringValue(out.Parameter.Value)
+	return s.cache[key], nil
+}
+
+// SSMParameters looks up the AMIs from the SSM public parameters holding the ID of the latest AMI in each region
+type SSMParameters struct {
+	// Name is the name of the parameters
+	Name  string
+	Store ParameterStore
+}
+
+// NewSSMParameters returns the source getting the parameters named `name` from AWS
+func NewSSMParameters(name string) SSMParameters {
+	return SSMParameters{Name: name, Store: defaultParameterStore}
+}
+
+func (s SSMParameters) Lookup(q Query) (string, error) {
+	id, err := s.Store.GetParameter(q.Region, s.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the SSM parameter %s in region %s: %v", s.Name, q.Region, err)
+	}
+	return id, nil
+}
+
+func (SSMParameters) HasChannels() bool {
+	return false
+}
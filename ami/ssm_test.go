@@ -0,0 +1,33 @@
+package ami
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeParameterStore map[string]string
+
+func (s fakeParameterStore) GetParameter(region, name string) (string, error) {
+	v, ok := s[region+":"+name]
+	if !ok {
+		return "", fmt.Errorf("ParameterNotFound")
+	}
+	return v, nil
+}
+
+func TestSSMParameters(t *testing.T) {
+	source := SSMParameters{
+		Name:  "/latest",
+		Store: fakeParameterStore{"eu-west-1:/latest": "ami-latest"},
+	}
+
+	id, err := source.Lookup(Query{OS: "amazon-linux-2", Region: "eu-west-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "ami-latest", id)
+
+	_, err = source.Lookup(Query{OS: "amazon-linux-2", Region: "us-east-1"})
+	assert.EqualError(t, err, "failed to get the SSM parameter /latest in region us-east-1: ParameterNotFound")
+}
@@ -14,6 +14,7 @@ const (
 	EtcdStackTemplateTmplFile         = "stack-templates/etcd.json.tmpl"
 	NodePoolStackTemplateTmplFile     = "stack-templates/node-pool.json.tmpl"
 	RootStackTemplateTmplFile         = "stack-templates/root.json.tmpl"
+	PricingTableFile                  = "pricing.yaml"
 )
 
 func Box() *packr.Box {
@@ -0,0 +1,269 @@
+package cfndiff
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change is a difference found at a property path within a resource.
+// Old is nil for an added property and New is nil for a removed one.
+type Change struct {
+	Target   string      `json:"target" yaml:"target"`
+	Resource string      `json:"resource" yaml:"resource"`
+	Path     string      `json:"path" yaml:"path"`
+	Old      interface{} `json:"old" yaml:"old"`
+	New      interface{} `json:"new" yaml:"new"`
+}
+
+// Action returns one of "add", "remove" and "modify"
+func (c Change) Action() string {
+	if c.Old == nil {
+		return "add"
+	}
+	if c.New == nil {
+		return "remove"
+	}
+	return "modify"
+}
+
+// listKeys are the keys used to identify items in lists of maps, so that reordering the items doesn't result in changes.
+// `path` identifies cloud-config write_files while `name` identifies systemd units, volumes, tags and so on.
+var listKeys = []string{"path", "name", "Key"}
+
+// Templates compares two CloudFormation templates resource by resource.
+// Resources are identified by their logical IDs, whereas outputs, parameters and other top-level sections are
+// identified as "<Section>/<Name>".
+func Templates(target, current, desired string) ([]Change, error) {
+	c, err := parseJSON(current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse current %s template: %v", target, err)
+	}
+	d, err := parseJSON(desired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse desired %s template: %v", target, err)
+	}
+
+	changes := []Change{}
+	for _, section := range unionKeys(c, d) {
+		cs, csOK := c[section].(map[string]interface{})
+		ds, dsOK := d[section].(map[string]interface{})
+		if !csOK || !dsOK {
+			// Scalar sections like AWSTemplateFormatVersion and Description
+			changes = append(changes, Values(target, section, c[section], d[section])...)
+			continue
+		}
+		for _, name := range unionKeys(cs, ds) {
+			resource := name
+			if section != "Resources" {
+				resource = fmt.Sprintf("%s/%s", section, name)
+			}
+			changes = append(changes, Values(target, resource, cs[name], ds[name])...)
+		}
+	}
+	return changes, nil
+}
+
+// JSON compares two JSON documents which are not CloudFormation templates, like userdata rendered as JSON
+func JSON(target, resource, current, desired string) ([]Change, error) {
+	var c, d interface{}
+	if err := json.Unmarshal([]byte(current), &c); err != nil {
+		return nil, fmt.Errorf("failed to parse current %s: %v", resource, err)
+	}
+	if err := json.Unmarshal([]byte(desired), &d); err != nil {
+		return nil, fmt.Errorf("failed to parse desired %s: %v", resource, err)
+	}
+	return Values(target, resource, c, d), nil
+}
+
+// Text compares two texts as a whole, like shell scripts
+func Text(target, resource, current, desired string) []Change {
+	if current == desired {
+		return []Change{}
+	}
+	return []Change{{Target: target, Resource: resource, Old: current, New: desired}}
+}
+
+// Values recursively compares two values decoded from JSON or YAML and returns a change per differing leaf
+func Values(target, resource string, current, desired interface{}) []Change {
+	changes := []Change{}
+	walk(&changes, target, resource, "", decode(current), decode(desired))
+	return changes
+}
+
+func walk(changes *[]Change, target, resource, path string, current, desired interface{}) {
+	switch c := current.(type) {
+	case map[string]interface{}:
+		if d, ok := desired.(map[string]interface{}); ok {
+			for _, k := range unionKeys(c, d) {
+				walk(changes, target, resource, joinPath(path, k), c[k], d[k])
+			}
+			return
+		}
+	case []interface{}:
+		if d, ok := desired.([]interface{}); ok {
+			if key, ok := commonListKey(c, d); ok {
+				cm, dm := indexBy(c, key), indexBy(d, key)
+				for _, id := range unionKeys(cm, dm) {
+					walk(changes, target, resource, fmt.Sprintf("%s[%s=%s]", path, key, id), cm[id], dm[id])
+				}
+				return
+			}
+			n := len(c)
+			if len(d) > n {
+				n = len(d)
+			}
+			for i := 0; i < n; i++ {
+				var ci, di interface{}
+				if i < len(c) {
+					ci = c[i]
+				}
+				if i < len(d) {
+					di = d[i]
+				}
+				walk(changes, target, resource, fmt.Sprintf("%s[%d]", path, i), ci, di)
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(current, desired) {
+		*changes = append(*changes, Change{Target: target, Resource: resource, Path: path, Old: current, New: desired})
+	}
+}
+
+// commonListKey returns the key which uniquely identifies every item in both lists, if any
+func commonListKey(lists ...[]interface{}) (string, bool) {
+	for _, key := range listKeys {
+		ok := true
+		for _, l := range lists {
+			seen := map[string]bool{}
+			for _, item := range l {
+				m, isMap := item.(map[string]interface{})
+				if !isMap {
+					ok = false
+					break
+				}
+				id, isStr := m[key].(string)
+				if !isStr || seen[id] {
+					ok = false
+					break
+				}
+				seen[id] = true
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func indexBy(l []interface{}, key string) map[string]interface{} {
+	m := map[string]interface{}{}
+	for _, item := range l {
+		i := item.(map[string]interface{})
+		m[i[key].(string)] = i
+	}
+	return m
+}
+
+// decode recursively normalizes YAML maps into JSON-compatible maps and unzips gzip+base64 encoded strings
+func decode(v interface{}) interface{} {
+	return convert(v, true)
+}
+
+// normalize recursively converts YAML maps into JSON-compatible maps
+func normalize(v interface{}) interface{} {
+	return convert(v, false)
+}
+
+func convert(v interface{}, gunzip bool) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, v := range t {
+			m[fmt.Sprintf("%v", k)] = convert(v, gunzip)
+		}
+		return m
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for k, v := range t {
+			m[k] = convert(v, gunzip)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, v := range t {
+			l[i] = convert(v, gunzip)
+		}
+		return l
+	case string:
+		if !gunzip {
+			return t
+		}
+		if s, ok := gunzipBase64(t); ok {
+			return s
+		}
+	}
+	return v
+}
+
+// gunzipBase64 returns the decompressed string when the input is a base64-encoded gzip stream
+func gunzipBase64(s string) (string, bool) {
+	if len(s) < 20 || strings.ContainsAny(s, " \n") {
+		return "", false
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(b) < 2 || b[0] != 0x1f || b[1] != 0x8b {
+		return "", false
+	}
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return "", false
+	}
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
+
+func parseJSON(s string) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, m := range []map[string]interface{}{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,127 @@
+package cfndiff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-aws/gzipcompressor"
+)
+
+func TestTemplates(t *testing.T) {
+	current := `{
+  "Resources": {
+    "Controller": {"Type": "AWS::EC2::Instance", "Properties": {"ImageId": "ami-1", "InstanceType": "t2.medium",
+      "Tags": [{"Key": "a", "Value": "1"}, {"Key": "b", "Value": "2"}]}},
+    "Removed": {"Type": "AWS::SNS::Topic"}
+  },
+  "Outputs": {"StackName": {"Value": "foo"}}
+}`
+	desired := `{
+  "Outputs": {"StackName": {"Value": "bar"}},
+  "Resources": {
+    "Added": {"Type": "AWS::SNS::Topic"},
+    "Controller": {"Properties": {"Tags": [{"Value": "2", "Key": "b"}, {"Key": "a", "Value": "1"}],
+      "InstanceType": "t2.medium", "ImageId": "ami-2"}, "Type": "AWS::EC2::Instance"}
+  }
+}`
+
+	changes, err := Templates("controller", current, desired)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Change{
+		{Target: "controller", Resource: "Outputs/StackName", Path: "Value", Old: "foo", New: "bar"},
+		{Target: "controller", Resource: "Added", Path: "", Old: nil, New: map[string]interface{}{"Type": "AWS::SNS::Topic"}},
+		{Target: "controller", Resource: "Controller", Path: "Properties.ImageId", Old: "ami-1", New: "ami-2"},
+		{Target: "controller", Resource: "Removed", Path: "", Old: map[string]interface{}{"Type": "AWS::SNS::Topic"}, New: nil},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("unexpected changes:\nexpected=%+v\nactual=%+v", expected, changes)
+	}
+
+	if a := changes[1].Action(); a != "add" {
+		t.Errorf("expected add but got %s", a)
+	}
+	if a := changes[3].Action(); a != "remove" {
+		t.Errorf("expected remove but got %s", a)
+	}
+}
+
+func TestValuesDecodesGzippedStrings(t *testing.T) {
+	old, err := gzipcompressor.StringToGzippedBase64String("line1\nline2\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := gzipcompressor.StringToGzippedBase64String("line1\nline3\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := Values("worker", "Config", map[string]interface{}{"content": old}, map[string]interface{}{"content": new})
+	expected := []Change{{Target: "worker", Resource: "Config", Path: "content", Old: "line1\nline2\n", New: "line1\nline3\n"}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("unexpected changes:\nexpected=%+v\nactual=%+v", expected, changes)
+	}
+}
+
+func TestCloudConfigs(t *testing.T) {
+	encoded, err := gzipcompressor.StringToGzippedBase64String("foo=bar\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current := `#cloud-config
+coreos:
+  units:
+  - name: kubelet.service
+    command: start
+  - name: docker.service
+    command: start
+write_files:
+- path: /etc/foo
+  encoding: gzip+base64
+  content: ` + encoded + `
+- path: /etc/bar
+  content: |
+    bar
+`
+	desired := `#cloud-config
+coreos:
+  units:
+  - name: docker.service
+    command: start
+  - name: kubelet.service
+    command: restart
+write_files:
+- path: /etc/bar
+  content: |
+    baz
+- path: /etc/foo
+  content: |
+    foo=bar
+`
+
+	changes, err := CloudConfigs("worker", "userdata", current, desired)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Change{
+		{Target: "worker", Resource: "userdata", Path: "coreos.units[name=kubelet.service].command", Old: "start", New: "restart"},
+		{Target: "worker", Resource: "userdata", Path: "write_files[path=/etc/bar].content", Old: "bar\n", New: "baz\n"},
+		{Target: "worker", Resource: "userdata", Path: "write_files[path=/etc/foo].encoding", Old: "gzip+base64", New: nil},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("unexpected changes:\nexpected=%+v\nactual=%+v", expected, changes)
+	}
+}
+
+func TestText(t *testing.T) {
+	if changes := Text("etcd", "instance-script", "a", "a"); len(changes) != 0 {
+		t.Errorf("expected no changes but got %+v", changes)
+	}
+	if changes := Text("etcd", "instance-script", "a", "b"); len(changes) != 1 {
+		t.Errorf("expected a change but got %+v", changes)
+	}
+}
@@ -0,0 +1,63 @@
+package cfndiff
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/kubernetes-incubator/kube-aws/gzipcompressor"
+	"gopkg.in/yaml.v2"
+)
+
+// DecodeCloudConfig parses a cloud-config and decodes the content of every file in `write_files` according to its `encoding`
+func DecodeCloudConfig(cloudConfig string) (map[string]interface{}, error) {
+	raw := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(cloudConfig), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse cloud-config: %v", err)
+	}
+	cc := normalize(raw).(map[string]interface{})
+
+	files, ok := cc["write_files"].([]interface{})
+	if !ok {
+		return cc, nil
+	}
+	decoded := make([]interface{}, len(files))
+	for i, f := range files {
+		file, ok := f.(map[string]interface{})
+		if !ok {
+			decoded[i] = f
+			continue
+		}
+		content, _ := file["content"].(string)
+		switch file["encoding"] {
+		case "gzip+base64", "gz+base64", "gzip+b64", "gz+b64":
+			s, err := gzipcompressor.GzippedBase64StringToString(content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %v: %v", file["path"], err)
+			}
+			file["content"] = s
+		case "base64", "b64":
+			b, err := base64.StdEncoding.DecodeString(content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %v: %v", file["path"], err)
+			}
+			file["content"] = string(b)
+		}
+		decoded[i] = file
+	}
+	cc["write_files"] = decoded
+
+	return cc, nil
+}
+
+// CloudConfigs compares two cloud-configs after decoding the files written by them
+func CloudConfigs(target, resource, current, desired string) ([]Change, error) {
+	c, err := DecodeCloudConfig(current)
+	if err != nil {
+		return nil, fmt.Errorf("current %s: %v", resource, err)
+	}
+	d, err := DecodeCloudConfig(desired)
+	if err != nil {
+		return nil, fmt.Errorf("desired %s: %v", resource, err)
+	}
+	return Values(target, resource, c, d), nil
+}
@@ -25,6 +25,7 @@ type CRUDService interface {
 	UpdateStack(input *cloudformation.UpdateStackInput) (*cloudformation.UpdateStackOutput, error)
 	DescribeStacks(input *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error)
 	DescribeStackEvents(input *cloudformation.DescribeStackEventsInput) (*cloudformation.DescribeStackEventsOutput, error)
+	CancelUpdateStack(input *cloudformation.CancelUpdateStackInput) (*cloudformation.CancelUpdateStackOutput, error)
 	EstimateTemplateCost(input *cloudformation.EstimateTemplateCostInput) (*cloudformation.EstimateTemplateCostOutput, error)
 }
 
@@ -42,24 +43,33 @@ func StackEventErrMsgs(events []*cloudformation.StackEvent) []string {
 	var errMsgs []string
 
 	for _, event := range events {
-		if aws.StringValue(event.ResourceStatus) == cloudformation.ResourceStatusCreateFailed {
-			// Only show actual failures, not cancelled dependent resources.
-			if aws.StringValue(event.ResourceStatusReason) != "Resource creation cancelled" {
-				errMsgs = append(errMsgs,
-					strings.TrimSpace(
-						strings.Join([]string{
-							aws.StringValue(event.ResourceStatus),
-							aws.StringValue(event.ResourceType),
-							aws.StringValue(event.LogicalResourceId),
-							aws.StringValue(event.ResourceStatusReason),
-						}, " ")))
-			}
+		// Only show actual failures, not cancelled dependent resources.
+		if isFailure(event) {
+			errMsgs = append(errMsgs,
+				strings.TrimSpace(
+					strings.Join([]string{
+						aws.StringValue(event.ResourceStatus),
+						aws.StringValue(event.ResourceType),
+						aws.StringValue(event.LogicalResourceId),
+						aws.StringValue(event.ResourceStatusReason),
+					}, " ")))
 		}
 	}
 
 	return errMsgs
 }
 
+// isFailure returns true for the events of failed resources, but not of the resources whose operations were cancelled
+// because another resource failed
+func isFailure(event *cloudformation.StackEvent) bool {
+	switch aws.StringValue(event.ResourceStatus) {
+	case cloudformation.ResourceStatusCreateFailed, cloudformation.ResourceStatusUpdateFailed, cloudformation.ResourceStatusDeleteFailed:
+		reason := aws.StringValue(event.ResourceStatusReason)
+		return reason != "Resource creation cancelled" && reason != "Resource update cancelled"
+	}
+	return false
+}
+
 func NestedStackExists(cf CFInterrogator, parentStackName, stackName string) (bool, error) {
 	logger.Debugf("testing whether nested stack '%s' is present in parent stack '%s'", stackName, parentStackName)
 	parentExists, err := StackExists(cf, parentStackName)
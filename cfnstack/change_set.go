@@ -0,0 +1,242 @@
+package cfnstack
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+)
+
+// ChangeSetService is the subset of the CloudFormation API required to create, inspect and execute change sets
+type ChangeSetService interface {
+	CreateChangeSet(input *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error)
+	DescribeChangeSet(input *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error)
+	DeleteChangeSet(input *cloudformation.DeleteChangeSetInput) (*cloudformation.DeleteChangeSetOutput, error)
+	ExecuteChangeSet(input *cloudformation.ExecuteChangeSetInput) (*cloudformation.ExecuteChangeSetOutput, error)
+}
+
+// ChangeSetExecutionService is required to execute a change set and then wait for the stack operation to finish
+type ChangeSetExecutionService interface {
+	ChangeSetService
+	CRUDService
+}
+
+// ResourceChange is a single resource action planned in a change set
+type ResourceChange struct {
+	Action       string `json:"action"`
+	LogicalID    string `json:"logicalId"`
+	PhysicalID   string `json:"physicalId,omitempty"`
+	ResourceType string `json:"resourceType"`
+	// Replacement is one of "True", "False" or "Conditional" for modified resources
+	Replacement string   `json:"replacement,omitempty"`
+	Scope       []string `json:"scope,omitempty"`
+	// ChangedProperties lists the names of all the properties being modified
+	ChangedProperties []string `json:"changedProperties,omitempty"`
+	// CausingProperties lists the names of the properties whose modifications require the resource to be replaced
+	CausingProperties []string `json:"causingProperties,omitempty"`
+}
+
+// RequiresReplacement returns true when the resource is or may be replaced by the change
+func (c ResourceChange) RequiresReplacement() bool {
+	return c.Replacement == cloudformation.ReplacementTrue || c.Replacement == cloudformation.ReplacementConditional
+}
+
+// ChangeSet is the result of a change set creation, flattened into a list of resource changes
+type ChangeSet struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	StackID   string           `json:"stackId"`
+	StackName string           `json:"stackName"`
+	Changes   []ResourceChange `json:"changes"`
+}
+
+// HasChanges returns true when executing the change set would change any resource
+func (s *ChangeSet) HasChanges() bool {
+	return len(s.Changes) > 0
+}
+
+// CreateChangeSetAtURL creates a change set named `name` for the stack from the template located at `templateURL`.
+// `changeSetType` must be either cloudformation.ChangeSetTypeCreate or cloudformation.ChangeSetTypeUpdate.
+// It returns the ARN of the change set without waiting for its creation to complete.
+func (c *Provisioner) CreateChangeSetAtURL(cfSvc ChangeSetService, templateURL, name, changeSetType string, parameters []*cloudformation.Parameter) (string, error) {
+	var tags []*cloudformation.Tag
+	for k, v := range c.stackTags {
+		key := k
+		value := v
+		tags = append(tags, &cloudformation.Tag{Key: &key, Value: &value})
+	}
+
+	input := &cloudformation.CreateChangeSetInput{
+		Capabilities:  []*string{aws.String(cloudformation.CapabilityCapabilityIam), aws.String(cloudformation.CapabilityCapabilityNamedIam)},
+		ChangeSetName: aws.String(name),
+		ChangeSetType: aws.String(changeSetType),
+		Description:   aws.String(fmt.Sprintf("created by kube-aws for stack %s", c.stackName)),
+		Parameters:    parameters,
+		StackName:     aws.String(c.stackName),
+		Tags:          tags,
+		TemplateURL:   aws.String(templateURL),
+	}
+	if c.roleARN != "" {
+		input = input.SetRoleARN(c.roleARN)
+	}
+
+	out, err := cfSvc.CreateChangeSet(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to create change set for stack %s: %v", c.stackName, err)
+	}
+	return aws.StringValue(out.Id), nil
+}
+
+// ExecuteChangeSetAndWait executes the change set identified by `changeSetID` and waits until the stack gets created or updated
+func (c *Provisioner) ExecuteChangeSetAndWait(cfSvc ChangeSetExecutionService, changeSetID string, create bool) error {
+	desc, err := cfSvc.DescribeChangeSet(&cloudformation.DescribeChangeSetInput{ChangeSetName: aws.String(changeSetID)})
+	if err != nil {
+		return fmt.Errorf("failed to describe change set %s: %v", changeSetID, err)
+	}
+	if status := aws.StringValue(desc.ExecutionStatus); status != cloudformation.ExecutionStatusAvailable {
+		return fmt.Errorf("change set %s can not be executed: execution status is %s", changeSetID, status)
+	}
+
+	since := time.Now()
+	if _, err := cfSvc.ExecuteChangeSet(&cloudformation.ExecuteChangeSetInput{ChangeSetName: aws.String(changeSetID)}); err != nil {
+		return fmt.Errorf("failed to execute change set %s: %v", changeSetID, err)
+	}
+
+	if create {
+		return c.waitUntilStackGetsCreated(cfSvc, desc.StackId, since)
+	}
+	return c.waitUntilStackGetsUpdated(cfSvc, desc.StackId, since)
+}
+
+// isEmptyChangeSetReason returns true when CloudFormation failed a change set only because there was nothing to change
+func isEmptyChangeSetReason(reason string) bool {
+	return strings.Contains(reason, "didn't contain changes") || strings.Contains(reason, "No updates are to be performed")
+}
+
+// WaitForChangeSet waits until the change set gets created and then returns all the changes contained in it.
+// A change set failed only because it contained no changes is returned as an empty change set.
+func WaitForChangeSet(cfSvc ChangeSetService, changeSetID string) (*ChangeSet, error) {
+	for {
+		out, err := cfSvc.DescribeChangeSet(&cloudformation.DescribeChangeSetInput{ChangeSetName: aws.String(changeSetID)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe change set %s: %v", changeSetID, err)
+		}
+
+		status := aws.StringValue(out.Status)
+		switch status {
+		case cloudformation.ChangeSetStatusCreateComplete:
+			return describeAllChanges(cfSvc, out)
+		case cloudformation.ChangeSetStatusFailed:
+			reason := aws.StringValue(out.StatusReason)
+			if isEmptyChangeSetReason(reason) {
+				return &ChangeSet{
+					ID:        aws.StringValue(out.ChangeSetId),
+					Name:      aws.StringValue(out.ChangeSetName),
+					StackID:   aws.StringValue(out.StackId),
+					StackName: aws.StringValue(out.StackName),
+					Changes:   []ResourceChange{},
+				}, nil
+			}
+			return nil, fmt.Errorf("change set %s failed: %s", changeSetID, reason)
+		case cloudformation.ChangeSetStatusCreatePending, cloudformation.ChangeSetStatusCreateInProgress:
+			logger.Debugf("change set %s is %s. waiting...", changeSetID, status)
+			time.Sleep(3 * time.Second)
+		default:
+			return nil, fmt.Errorf("unexpected change set status: %s", status)
+		}
+	}
+}
+
+func describeAllChanges(cfSvc ChangeSetService, first *cloudformation.DescribeChangeSetOutput) (*ChangeSet, error) {
+	cs := &ChangeSet{
+		ID:        aws.StringValue(first.ChangeSetId),
+		Name:      aws.StringValue(first.ChangeSetName),
+		StackID:   aws.StringValue(first.StackId),
+		StackName: aws.StringValue(first.StackName),
+		Changes:   []ResourceChange{},
+	}
+
+	page := first
+	for {
+		for _, c := range page.Changes {
+			if c.ResourceChange == nil {
+				continue
+			}
+			cs.Changes = append(cs.Changes, NewResourceChange(c.ResourceChange))
+		}
+		if page.NextToken == nil {
+			break
+		}
+		var err error
+		page, err = cfSvc.DescribeChangeSet(&cloudformation.DescribeChangeSetInput{
+			ChangeSetName: first.ChangeSetId,
+			NextToken:     page.NextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe change set %s: %v", cs.ID, err)
+		}
+	}
+
+	return cs, nil
+}
+
+// NewResourceChange converts a resource change returned by the CloudFormation API
+func NewResourceChange(rc *cloudformation.ResourceChange) ResourceChange {
+	change := ResourceChange{
+		Action:       aws.StringValue(rc.Action),
+		LogicalID:    aws.StringValue(rc.LogicalResourceId),
+		PhysicalID:   aws.StringValue(rc.PhysicalResourceId),
+		ResourceType: aws.StringValue(rc.ResourceType),
+		Replacement:  aws.StringValue(rc.Replacement),
+		Scope:        aws.StringValueSlice(rc.Scope),
+	}
+
+	changed := map[string]bool{}
+	causing := map[string]bool{}
+	for _, d := range rc.Details {
+		if d.Target == nil {
+			continue
+		}
+		name := aws.StringValue(d.Target.Name)
+		if name == "" {
+			name = aws.StringValue(d.Target.Attribute)
+		}
+		changed[name] = true
+		if r := aws.StringValue(d.Target.RequiresRecreation); r == cloudformation.RequiresRecreationAlways || r == cloudformation.RequiresRecreationConditionally {
+			causing[name] = true
+		}
+	}
+	change.ChangedProperties = sortedKeys(changed)
+	change.CausingProperties = sortedKeys(causing)
+
+	return change
+}
+
+// DeleteChangeSet deletes the change set identified by `changeSetID`
+func DeleteChangeSet(cfSvc ChangeSetService, changeSetID string) error {
+	if changeSetID == "" {
+		return errors.New("[bug] DeleteChangeSet: changeSetID must not be empty")
+	}
+	_, err := cfSvc.DeleteChangeSet(&cloudformation.DeleteChangeSetInput{ChangeSetName: aws.String(changeSetID)})
+	if err != nil {
+		return fmt.Errorf("failed to delete change set %s: %v", changeSetID, err)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
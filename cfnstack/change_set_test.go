@@ -0,0 +1,171 @@
+package cfnstack
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+type dummyChangeSetService struct {
+	pages   map[string]*cloudformation.DescribeChangeSetOutput
+	deleted []string
+}
+
+func (s *dummyChangeSetService) CreateChangeSet(input *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
+	return &cloudformation.CreateChangeSetOutput{Id: aws.String("arn:changeset"), StackId: input.StackName}, nil
+}
+
+func (s *dummyChangeSetService) DescribeChangeSet(input *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error) {
+	token := aws.StringValue(input.NextToken)
+	page, ok := s.pages[token]
+	if !ok {
+		return nil, fmt.Errorf("unexpected token: %s", token)
+	}
+	return page, nil
+}
+
+func (s *dummyChangeSetService) DeleteChangeSet(input *cloudformation.DeleteChangeSetInput) (*cloudformation.DeleteChangeSetOutput, error) {
+	s.deleted = append(s.deleted, aws.StringValue(input.ChangeSetName))
+	return &cloudformation.DeleteChangeSetOutput{}, nil
+}
+
+func (s *dummyChangeSetService) ExecuteChangeSet(input *cloudformation.ExecuteChangeSetInput) (*cloudformation.ExecuteChangeSetOutput, error) {
+	return &cloudformation.ExecuteChangeSetOutput{}, nil
+}
+
+func resourceChange(action, logicalID, replacement string, details ...*cloudformation.ResourceChangeDetail) *cloudformation.Change {
+	return &cloudformation.Change{
+		Type: aws.String(cloudformation.ChangeTypeResource),
+		ResourceChange: &cloudformation.ResourceChange{
+			Action:            aws.String(action),
+			LogicalResourceId: aws.String(logicalID),
+			ResourceType:      aws.String("AWS::EC2::Instance"),
+			Replacement:       aws.String(replacement),
+			Details:           details,
+		},
+	}
+}
+
+func propertyDetail(name, recreation string) *cloudformation.ResourceChangeDetail {
+	return &cloudformation.ResourceChangeDetail{
+		Target: &cloudformation.ResourceTargetDefinition{
+			Attribute:          aws.String(cloudformation.ResourceAttributeProperties),
+			Name:               aws.String(name),
+			RequiresRecreation: aws.String(recreation),
+		},
+	}
+}
+
+func TestWaitForChangeSet(t *testing.T) {
+	t.Run("CollectsChangesFromAllPages", func(t *testing.T) {
+		svc := &dummyChangeSetService{
+			pages: map[string]*cloudformation.DescribeChangeSetOutput{
+				"": {
+					ChangeSetId:   aws.String("arn:changeset"),
+					ChangeSetName: aws.String("plan"),
+					StackId:       aws.String("arn:stack"),
+					StackName:     aws.String("mycluster"),
+					Status:        aws.String(cloudformation.ChangeSetStatusCreateComplete),
+					Changes: []*cloudformation.Change{
+						resourceChange(cloudformation.ChangeActionModify, "Controller", cloudformation.ReplacementTrue,
+							propertyDetail("ImageId", cloudformation.RequiresRecreationAlways),
+							propertyDetail("Tags", cloudformation.RequiresRecreationNever),
+						),
+					},
+					NextToken: aws.String("page2"),
+				},
+				"page2": {
+					ChangeSetId: aws.String("arn:changeset"),
+					Status:      aws.String(cloudformation.ChangeSetStatusCreateComplete),
+					Changes: []*cloudformation.Change{
+						resourceChange(cloudformation.ChangeActionAdd, "Worker", ""),
+					},
+				},
+			},
+		}
+
+		cs, err := WaitForChangeSet(svc, "arn:changeset")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cs.StackName != "mycluster" || cs.Name != "plan" {
+			t.Errorf("unexpected change set: %+v", cs)
+		}
+
+		if len(cs.Changes) != 2 {
+			t.Fatalf("expected 2 changes but got %d: %+v", len(cs.Changes), cs.Changes)
+		}
+
+		controller := cs.Changes[0]
+		if !controller.RequiresReplacement() {
+			t.Errorf("expected the controller to be replaced: %+v", controller)
+		}
+		if !reflect.DeepEqual(controller.ChangedProperties, []string{"ImageId", "Tags"}) {
+			t.Errorf("unexpected changed properties: %v", controller.ChangedProperties)
+		}
+		if !reflect.DeepEqual(controller.CausingProperties, []string{"ImageId"}) {
+			t.Errorf("unexpected causing properties: %v", controller.CausingProperties)
+		}
+
+		worker := cs.Changes[1]
+		if worker.Action != cloudformation.ChangeActionAdd || worker.RequiresReplacement() {
+			t.Errorf("unexpected worker change: %+v", worker)
+		}
+	})
+
+	t.Run("ReturnsEmptyChangeSetWhenNothingChanged", func(t *testing.T) {
+		svc := &dummyChangeSetService{
+			pages: map[string]*cloudformation.DescribeChangeSetOutput{
+				"": {
+					ChangeSetId:  aws.String("arn:changeset"),
+					StackName:    aws.String("mycluster"),
+					Status:       aws.String(cloudformation.ChangeSetStatusFailed),
+					StatusReason: aws.String("The submitted information didn't contain changes. Submit different information to create a change set."),
+				},
+			},
+		}
+
+		cs, err := WaitForChangeSet(svc, "arn:changeset")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cs.HasChanges() {
+			t.Errorf("expected no changes but got: %+v", cs.Changes)
+		}
+	})
+
+	t.Run("FailsOnOtherFailures", func(t *testing.T) {
+		svc := &dummyChangeSetService{
+			pages: map[string]*cloudformation.DescribeChangeSetOutput{
+				"": {
+					ChangeSetId:  aws.String("arn:changeset"),
+					Status:       aws.String(cloudformation.ChangeSetStatusFailed),
+					StatusReason: aws.String("Template format error"),
+				},
+			},
+		}
+
+		if _, err := WaitForChangeSet(svc, "arn:changeset"); err == nil {
+			t.Error("expected an error but got none")
+		}
+	})
+}
+
+func TestDeleteChangeSet(t *testing.T) {
+	svc := &dummyChangeSetService{}
+
+	if err := DeleteChangeSet(svc, ""); err == nil {
+		t.Error("expected an error for an empty change set id but got none")
+	}
+
+	if err := DeleteChangeSet(svc, "arn:changeset"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(svc.deleted, []string{"arn:changeset"}) {
+		t.Errorf("unexpected deleted change sets: %v", svc.deleted)
+	}
+}
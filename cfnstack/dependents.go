@@ -0,0 +1,141 @@
+package cfnstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/sjson"
+)
+
+var subVariablePattern = regexp.MustCompile(`\$\{([^!}][^}.]*)[^}]*\}`)
+
+// Dependents lists the resources and the outputs in a stack template which depend on some of its resources
+type Dependents struct {
+	// Resources maps the logical ID of a dependent resource to the logical IDs of the resources it depends on
+	Resources map[string][]string
+	// Outputs maps the name of a dependent output to the logical IDs of the resources it depends on
+	Outputs map[string][]string
+}
+
+// FindDependents returns the resources and the outputs within the template which refer to any of `logicalIDs`
+// via Ref, Fn::GetAtt, Fn::Sub or DependsOn. The resources identified by `logicalIDs` themselves are excluded.
+func FindDependents(template string, logicalIDs []string) (*Dependents, error) {
+	t := struct {
+		Resources map[string]interface{} `json:"Resources"`
+		Outputs   map[string]interface{} `json:"Outputs"`
+	}{}
+	if err := json.Unmarshal([]byte(template), &t); err != nil {
+		return nil, fmt.Errorf("failed to parse stack template: %v", err)
+	}
+
+	targets := map[string]bool{}
+	for _, id := range logicalIDs {
+		targets[id] = true
+	}
+
+	deps := &Dependents{
+		Resources: map[string][]string{},
+		Outputs:   map[string][]string{},
+	}
+	for name, r := range t.Resources {
+		if targets[name] {
+			continue
+		}
+		if refs := referencedTargets(r, targets); len(refs) > 0 {
+			deps.Resources[name] = refs
+		}
+	}
+	for name, o := range t.Outputs {
+		if refs := referencedTargets(o, targets); len(refs) > 0 {
+			deps.Outputs[name] = refs
+		}
+	}
+	return deps, nil
+}
+
+// RemoveResources removes the resources and the outputs from the template
+func RemoveResources(template string, logicalIDs []string, outputs []string) (string, error) {
+	var err error
+	for _, id := range logicalIDs {
+		if template, err = sjson.Delete(template, "Resources."+id); err != nil {
+			return "", fmt.Errorf("failed to remove resource %s: %v", id, err)
+		}
+	}
+	for _, name := range outputs {
+		if template, err = sjson.Delete(template, "Outputs."+name); err != nil {
+			return "", fmt.Errorf("failed to remove output %s: %v", name, err)
+		}
+	}
+	return template, nil
+}
+
+func referencedTargets(v interface{}, targets map[string]bool) []string {
+	found := map[string]bool{}
+	collectReferences(v, targets, found)
+	refs := []string{}
+	for id := range found {
+		refs = append(refs, id)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+func collectReferences(v interface{}, targets map[string]bool, found map[string]bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			switch k {
+			case "Ref":
+				if id, ok := child.(string); ok && targets[id] {
+					found[id] = true
+				}
+			case "Fn::GetAtt":
+				switch a := child.(type) {
+				case []interface{}:
+					if len(a) > 0 {
+						if id, ok := a[0].(string); ok && targets[id] {
+							found[id] = true
+						}
+					}
+				case string:
+					if id := strings.SplitN(a, ".", 2)[0]; targets[id] {
+						found[id] = true
+					}
+				}
+			case "Fn::Sub":
+				s, ok := child.(string)
+				if a, isList := child.([]interface{}); isList && len(a) > 0 {
+					s, ok = a[0].(string)
+				}
+				if ok {
+					for _, m := range subVariablePattern.FindAllStringSubmatch(s, -1) {
+						if targets[m[1]] {
+							found[m[1]] = true
+						}
+					}
+				}
+			case "DependsOn":
+				switch d := child.(type) {
+				case string:
+					if targets[d] {
+						found[d] = true
+					}
+				case []interface{}:
+					for _, i := range d {
+						if id, ok := i.(string); ok && targets[id] {
+							found[id] = true
+						}
+					}
+				}
+			}
+			collectReferences(child, targets, found)
+		}
+	case []interface{}:
+		for _, child := range t {
+			collectReferences(child, targets, found)
+		}
+	}
+}
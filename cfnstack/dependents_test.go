@@ -0,0 +1,84 @@
+package cfnstack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rootStackTemplate = `{
+  "Resources": {
+    "Network": {"Type": "AWS::CloudFormation::Stack"},
+    "Controlplane": {
+      "Type": "AWS::CloudFormation::Stack",
+      "Properties": {"Parameters": {"NetworkStackName": {"Fn::GetAtt": ["Network", "Outputs.StackName"]}}}
+    },
+    "Workers": {
+      "Type": "AWS::CloudFormation::Stack",
+      "Properties": {"Parameters": {"NetworkStackName": {"Fn::GetAtt": "Network.Outputs.StackName"}}},
+      "DependsOn": ["Controlplane"]
+    },
+    "Spot": {
+      "Type": "AWS::CloudFormation::Stack",
+      "Properties": {"Parameters": {"Name": {"Fn::Sub": "${Workers.Outputs.StackName}-${!Literal}"}}},
+      "DependsOn": "Controlplane"
+    }
+  },
+  "Outputs": {
+    "ControlPlaneStackName": {"Value": {"Fn::GetAtt": ["Controlplane", "Outputs.StackName"]}},
+    "NodePoolWorkersStackName": {"Value": {"Fn::GetAtt": ["Workers", "Outputs.StackName"]}},
+    "Version": {"Value": "v1"}
+  }
+}`
+
+func TestFindDependents(t *testing.T) {
+	t.Run("NodePool", func(t *testing.T) {
+		deps, err := FindDependents(rootStackTemplate, []string{"Workers"})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string][]string{"Spot": {"Workers"}}, deps.Resources)
+		assert.Equal(t, map[string][]string{"NodePoolWorkersStackName": {"Workers"}}, deps.Outputs)
+	})
+
+	t.Run("NodePoolsAltogether", func(t *testing.T) {
+		deps, err := FindDependents(rootStackTemplate, []string{"Workers", "Spot"})
+		require.NoError(t, err)
+
+		assert.Empty(t, deps.Resources)
+	})
+
+	t.Run("Network", func(t *testing.T) {
+		deps, err := FindDependents(rootStackTemplate, []string{"Network"})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string][]string{"Controlplane": {"Network"}, "Workers": {"Network"}}, deps.Resources)
+		assert.Empty(t, deps.Outputs)
+	})
+
+	t.Run("ControlPlane", func(t *testing.T) {
+		deps, err := FindDependents(rootStackTemplate, []string{"Controlplane"})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string][]string{"Workers": {"Controlplane"}, "Spot": {"Controlplane"}}, deps.Resources)
+		assert.Equal(t, map[string][]string{"ControlPlaneStackName": {"Controlplane"}}, deps.Outputs)
+	})
+}
+
+func TestRemoveResources(t *testing.T) {
+	updated, err := RemoveResources(rootStackTemplate, []string{"Workers", "Spot"}, []string{"NodePoolWorkersStackName"})
+	require.NoError(t, err)
+
+	tmpl := struct {
+		Resources map[string]interface{}
+		Outputs   map[string]interface{}
+	}{}
+	require.NoError(t, json.Unmarshal([]byte(updated), &tmpl))
+
+	assert.Len(t, tmpl.Resources, 2)
+	assert.Contains(t, tmpl.Resources, "Network")
+	assert.Contains(t, tmpl.Resources, "Controlplane")
+	assert.Len(t, tmpl.Outputs, 2)
+	assert.NotContains(t, tmpl.Outputs, "NodePoolWorkersStackName")
+}
@@ -0,0 +1,143 @@
+package cfnstack
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// DriftService is the subset of the CloudFormation API required to detect drift of stacks
+type DriftService interface {
+	DetectStackDrift(input *cloudformation.DetectStackDriftInput) (*cloudformation.DetectStackDriftOutput, error)
+	DescribeStackDriftDetectionStatus(input *cloudformation.DescribeStackDriftDetectionStatusInput) (*cloudformation.DescribeStackDriftDetectionStatusOutput, error)
+	DescribeStackResourceDrifts(input *cloudformation.DescribeStackResourceDriftsInput) (*cloudformation.DescribeStackResourceDriftsOutput, error)
+}
+
+// NamedStack is a stack labeled with a name like `network` or a node pool name
+type NamedStack struct {
+	Name      string
+	StackName string
+}
+
+// StackDrift is the result of drift detection on a stack
+type StackDrift struct {
+	Name      string `json:"name" yaml:"name"`
+	StackName string `json:"stackName" yaml:"stackName"`
+	// Status is either DRIFTED, IN_SYNC or NOT_CHECKED
+	Status string `json:"status" yaml:"status"`
+	// Reason explains why the detection failed for some resources, if any
+	Reason    string          `json:"reason,omitempty" yaml:"reason,omitempty"`
+	Resources []ResourceDrift `json:"resources" yaml:"resources"`
+}
+
+// ResourceDrift is a resource modified or deleted outside CloudFormation
+type ResourceDrift struct {
+	LogicalID  string `json:"logicalId" yaml:"logicalId"`
+	PhysicalID string `json:"physicalId" yaml:"physicalId"`
+	Type       string `json:"type" yaml:"type"`
+	// Status is either MODIFIED or DELETED
+	Status      string               `json:"status" yaml:"status"`
+	Differences []PropertyDifference `json:"differences,omitempty" yaml:"differences,omitempty"`
+}
+
+// PropertyDifference is a property whose actual value differs from the one in the stack template
+type PropertyDifference struct {
+	Path     string `json:"path" yaml:"path"`
+	Expected string `json:"expected" yaml:"expected"`
+	Actual   string `json:"actual" yaml:"actual"`
+	// Type is either ADD, REMOVE or NOT_EQUAL
+	Type string `json:"type" yaml:"type"`
+}
+
+// Drifted returns true when any resource in the stack has drifted
+func (d *StackDrift) Drifted() bool {
+	return len(d.Resources) > 0
+}
+
+// DetectDrift starts drift detection on all the stacks at once so that they are checked in parallel,
+// and then collects the results in the order of `stacks`
+func DetectDrift(svc DriftService, stacks []NamedStack, pollInterval time.Duration) ([]*StackDrift, error) {
+	ids := make([]string, len(stacks))
+	for i, s := range stacks {
+		out, err := svc.DetectStackDrift(&cloudformation.DetectStackDriftInput{StackName: aws.String(s.StackName)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to start drift detection on stack %s: %v", s.StackName, err)
+		}
+		ids[i] = aws.StringValue(out.StackDriftDetectionId)
+	}
+
+	drifts := []*StackDrift{}
+	for i, s := range stacks {
+		d, err := collectDrift(svc, s, ids[i], pollInterval)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, d)
+	}
+	return drifts, nil
+}
+
+func collectDrift(svc DriftService, stack NamedStack, id string, pollInterval time.Duration) (*StackDrift, error) {
+	var status *cloudformation.DescribeStackDriftDetectionStatusOutput
+	for {
+		var err error
+		status, err = svc.DescribeStackDriftDetectionStatus(&cloudformation.DescribeStackDriftDetectionStatusInput{StackDriftDetectionId: aws.String(id)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the status of drift detection on stack %s: %v", stack.StackName, err)
+		}
+		if aws.StringValue(status.DetectionStatus) != cloudformation.StackDriftDetectionStatusDetectionInProgress {
+			break
+		}
+		time.Sleep(pollInterval)
+	}
+
+	d := &StackDrift{
+		Name:      stack.Name,
+		StackName: stack.StackName,
+		Status:    aws.StringValue(status.StackDriftStatus),
+		Resources: []ResourceDrift{},
+	}
+	// Detection fails when some resources don't support drift detection, while the others are still checked
+	if aws.StringValue(status.DetectionStatus) == cloudformation.StackDriftDetectionStatusDetectionFailed {
+		d.Reason = aws.StringValue(status.DetectionStatusReason)
+	}
+
+	input := &cloudformation.DescribeStackResourceDriftsInput{
+		StackName: aws.String(stack.StackName),
+		StackResourceDriftStatusFilters: aws.StringSlice([]string{
+			cloudformation.StackResourceDriftStatusModified,
+			cloudformation.StackResourceDriftStatusDeleted,
+		}),
+	}
+	for {
+		out, err := svc.DescribeStackResourceDrifts(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe drifted resources of stack %s: %v", stack.StackName, err)
+		}
+		for _, r := range out.StackResourceDrifts {
+			rd := ResourceDrift{
+				LogicalID:   aws.StringValue(r.LogicalResourceId),
+				PhysicalID:  aws.StringValue(r.PhysicalResourceId),
+				Type:        aws.StringValue(r.ResourceType),
+				Status:      aws.StringValue(r.StackResourceDriftStatus),
+				Differences: []PropertyDifference{},
+			}
+			for _, p := range r.PropertyDifferences {
+				rd.Differences = append(rd.Differences, PropertyDifference{
+					Path:     aws.StringValue(p.PropertyPath),
+					Expected: aws.StringValue(p.ExpectedValue),
+					Actual:   aws.StringValue(p.ActualValue),
+					Type:     aws.StringValue(p.DifferenceType),
+				})
+			}
+			d.Resources = append(d.Resources, rd)
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return d, nil
+}
@@ -0,0 +1,121 @@
+package cfnstack
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dummyDriftService struct {
+	drifts   map[string][]*cloudformation.StackResourceDrift
+	polls    map[string]int
+	detected []string
+}
+
+func (s *dummyDriftService) DetectStackDrift(input *cloudformation.DetectStackDriftInput) (*cloudformation.DetectStackDriftOutput, error) {
+	s.detected = append(s.detected, aws.StringValue(input.StackName))
+	return &cloudformation.DetectStackDriftOutput{StackDriftDetectionId: aws.String("id-" + aws.StringValue(input.StackName))}, nil
+}
+
+func (s *dummyDriftService) DescribeStackDriftDetectionStatus(input *cloudformation.DescribeStackDriftDetectionStatusInput) (*cloudformation.DescribeStackDriftDetectionStatusOutput, error) {
+	id := aws.StringValue(input.StackDriftDetectionId)
+	stackName := id[len("id-"):]
+	s.polls[stackName]++
+	if s.polls[stackName] < 2 {
+		return &cloudformation.DescribeStackDriftDetectionStatusOutput{DetectionStatus: aws.String(cloudformation.StackDriftDetectionStatusDetectionInProgress)}, nil
+	}
+	status := cloudformation.StackDriftStatusInSync
+	if len(s.drifts[stackName]) > 0 {
+		status = cloudformation.StackDriftStatusDrifted
+	}
+	return &cloudformation.DescribeStackDriftDetectionStatusOutput{
+		DetectionStatus:  aws.String(cloudformation.StackDriftDetectionStatusDetectionComplete),
+		StackDriftStatus: aws.String(status),
+	}, nil
+}
+
+func (s *dummyDriftService) DescribeStackResourceDrifts(input *cloudformation.DescribeStackResourceDriftsInput) (*cloudformation.DescribeStackResourceDriftsOutput, error) {
+	// Return drifts one by one to exercise pagination
+	drifts := s.drifts[aws.StringValue(input.StackName)]
+	i := 0
+	if input.NextToken != nil {
+		for i < len(drifts) && aws.StringValue(drifts[i].LogicalResourceId) != aws.StringValue(input.NextToken) {
+			i++
+		}
+	}
+	out := &cloudformation.DescribeStackResourceDriftsOutput{}
+	if i < len(drifts) {
+		out.StackResourceDrifts = drifts[i : i+1]
+	}
+	if i+1 < len(drifts) {
+		out.NextToken = drifts[i+1].LogicalResourceId
+	}
+	return out, nil
+}
+
+func TestDetectDrift(t *testing.T) {
+	svc := &dummyDriftService{
+		polls: map[string]int{},
+		drifts: map[string][]*cloudformation.StackResourceDrift{
+			"mycluster-Network-ABC": {
+				{
+					LogicalResourceId:        aws.String("SecurityGroupWorker"),
+					PhysicalResourceId:       aws.String("sg-1"),
+					ResourceType:             aws.String("AWS::EC2::SecurityGroup"),
+					StackResourceDriftStatus: aws.String(cloudformation.StackResourceDriftStatusModified),
+					PropertyDifferences: []*cloudformation.PropertyDifference{
+						{
+							PropertyPath:   aws.String("/SecurityGroupIngress/0/CidrIp"),
+							ExpectedValue:  aws.String("10.0.0.0/16"),
+							ActualValue:    aws.String("0.0.0.0/0"),
+							DifferenceType: aws.String(cloudformation.DifferenceTypeNotEqual),
+						},
+					},
+				},
+				{
+					LogicalResourceId:        aws.String("RouteToInternet"),
+					PhysicalResourceId:       aws.String("route-1"),
+					ResourceType:             aws.String("AWS::EC2::Route"),
+					StackResourceDriftStatus: aws.String(cloudformation.StackResourceDriftStatusDeleted),
+				},
+			},
+		},
+	}
+
+	drifts, err := DetectDrift(svc, []NamedStack{
+		{Name: "root", StackName: "mycluster"},
+		{Name: "network", StackName: "mycluster-Network-ABC"},
+	}, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"mycluster", "mycluster-Network-ABC"}, svc.detected)
+	require.Len(t, drifts, 2)
+
+	assert.Equal(t, "root", drifts[0].Name)
+	assert.Equal(t, cloudformation.StackDriftStatusInSync, drifts[0].Status)
+	assert.False(t, drifts[0].Drifted())
+
+	assert.Equal(t, cloudformation.StackDriftStatusDrifted, drifts[1].Status)
+	assert.True(t, drifts[1].Drifted())
+	assert.Equal(t, []ResourceDrift{
+		{
+			LogicalID:  "SecurityGroupWorker",
+			PhysicalID: "sg-1",
+			Type:       "AWS::EC2::SecurityGroup",
+			Status:     cloudformation.StackResourceDriftStatusModified,
+			Differences: []PropertyDifference{
+				{Path: "/SecurityGroupIngress/0/CidrIp", Expected: "10.0.0.0/16", Actual: "0.0.0.0/0", Type: cloudformation.DifferenceTypeNotEqual},
+			},
+		},
+		{
+			LogicalID:   "RouteToInternet",
+			PhysicalID:  "route-1",
+			Type:        "AWS::EC2::Route",
+			Status:      cloudformation.StackResourceDriftStatusDeleted,
+			Differences: []PropertyDifference{},
+		},
+	}, drifts[1].Resources)
+}
@@ -0,0 +1,132 @@
+package cfnstack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// StackEventsService is the subset of the CloudFormation API required to describe the events of stacks
+type StackEventsService interface {
+	DescribeStackEvents(input *cloudformation.DescribeStackEventsInput) (*cloudformation.DescribeStackEventsOutput, error)
+}
+
+// StackFailures are the messages of the failed events of a stack
+type StackFailures struct {
+	StackName string
+	Messages  []string
+}
+
+// FailureSummary is the failures of a stack operation across the stack and its nested stacks
+type FailureSummary struct {
+	// RootCause is the first resource failed, which is usually the cause of all the other failures
+	RootCause *cloudformation.StackEvent
+	// Stacks are the failures per stack, the stack first and then its nested stacks in the order they failed
+	Stacks []StackFailures
+}
+
+// DescribeFailure summarizes the failed events of the stack identified by `stackID` and its nested stacks since `since`
+func DescribeFailure(cfSvc StackEventsService, stackID string, since time.Time) (*FailureSummary, error) {
+	summary := &FailureSummary{}
+	failures := []*cloudformation.StackEvent{}
+
+	var describe func(stackID string) error
+	describe = func(stackID string) error {
+		events, err := stackEventsSince(cfSvc, stackID, since)
+		if err != nil {
+			return err
+		}
+		// StackEventErrMsgs expects the most recent event first, as returned by CloudFormation, whereas the failures are
+		// listed in the order they happened
+		if msgs := StackEventErrMsgs(events); len(msgs) > 0 {
+			for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+				msgs[i], msgs[j] = msgs[j], msgs[i]
+			}
+			summary.Stacks = append(summary.Stacks, StackFailures{StackName: aws.StringValue(events[0].StackName), Messages: msgs})
+		}
+
+		nested := []*cloudformation.StackEvent{}
+		for _, e := range events {
+			if !isFailure(e) {
+				continue
+			}
+			if aws.StringValue(e.ResourceType) == resourceTypeStack {
+				if id := aws.StringValue(e.PhysicalResourceId); id != "" && id != stackID {
+					nested = append(nested, e)
+				}
+				continue
+			}
+			failures = append(failures, e)
+		}
+
+		sort.Slice(nested, func(i, j int) bool {
+			return aws.TimeValue(nested[i].Timestamp).Before(aws.TimeValue(nested[j].Timestamp))
+		})
+		for _, e := range nested {
+			if err := describe(aws.StringValue(e.PhysicalResourceId)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := describe(stackID); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(failures, func(i, j int) bool {
+		return aws.TimeValue(failures[i].Timestamp).Before(aws.TimeValue(failures[j].Timestamp))
+	})
+	if len(failures) > 0 {
+		summary.RootCause = failures[0]
+	}
+	return summary, nil
+}
+
+func (s *FailureSummary) String() string {
+	var b strings.Builder
+	if s.RootCause != nil {
+		fmt.Fprintf(&b, "Root cause: %s %s %s in stack %s: %s\n",
+			aws.StringValue(s.RootCause.ResourceStatus),
+			aws.StringValue(s.RootCause.ResourceType),
+			aws.StringValue(s.RootCause.LogicalResourceId),
+			aws.StringValue(s.RootCause.StackName),
+			aws.StringValue(s.RootCause.ResourceStatusReason),
+		)
+	} else {
+		b.WriteString("No failed resource was found\n")
+	}
+	for _, stack := range s.Stacks {
+		fmt.Fprintf(&b, "\nFailed stack events of %s:\n", stack.StackName)
+		for _, msg := range stack.Messages {
+			fmt.Fprintf(&b, "  %s\n", msg)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// stackEventsSince returns the events of the stack since `since`, the most recent first
+func stackEventsSince(cfSvc StackEventsService, stackID string, since time.Time) ([]*cloudformation.StackEvent, error) {
+	events := []*cloudformation.StackEvent{}
+	input := &cloudformation.DescribeStackEventsInput{StackName: aws.String(stackID)}
+	for {
+		out, err := cfSvc.DescribeStackEvents(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe events of stack %s: %v", stackID, err)
+		}
+		for _, e := range out.StackEvents {
+			if aws.TimeValue(e.Timestamp).Before(since) {
+				return events, nil
+			}
+			events = append(events, e)
+		}
+		if out.NextToken == nil {
+			return events, nil
+		}
+		input.NextToken = out.NextToken
+	}
+}
@@ -0,0 +1,79 @@
+package cfnstack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dummyStackEventsService struct {
+	// Pages are the pages of events of each stack, the most recent event first
+	Pages map[string][][]*cloudformation.StackEvent
+}
+
+func (s dummyStackEventsService) DescribeStackEvents(input *cloudformation.DescribeStackEventsInput) (*cloudformation.DescribeStackEventsOutput, error) {
+	pages := s.Pages[aws.StringValue(input.StackName)]
+	page := 0
+	if input.NextToken != nil {
+		page = 1
+	}
+	out := &cloudformation.DescribeStackEventsOutput{StackEvents: pages[page]}
+	if page+1 < len(pages) {
+		out.NextToken = aws.String("next")
+	}
+	return out, nil
+}
+
+func stackEvent(stack, logicalID, resourceType, physicalID, status, reason string, at time.Time) *cloudformation.StackEvent {
+	return &cloudformation.StackEvent{
+		StackName:            aws.String(stack),
+		LogicalResourceId:    aws.String(logicalID),
+		ResourceType:         aws.String(resourceType),
+		PhysicalResourceId:   aws.String(physicalID),
+		ResourceStatus:       aws.String(status),
+		ResourceStatusReason: aws.String(reason),
+		Timestamp:            aws.Time(at),
+	}
+}
+
+func TestDescribeFailure(t *testing.T) {
+	since := time.Date(2019, 1, 2, 3, 0, 0, 0, time.UTC)
+	at := func(min int) time.Time { return since.Add(time.Duration(min) * time.Minute) }
+
+	svc := dummyStackEventsService{Pages: map[string][][]*cloudformation.StackEvent{
+		"root-id": {
+			{
+				stackEvent("mycluster", "mycluster", resourceTypeStack, "root-id", "UPDATE_ROLLBACK_IN_PROGRESS", "The following resource(s) failed to update: [Nodepool1]", at(9)),
+				stackEvent("mycluster", "Nodepool1", resourceTypeStack, "pool-id", "UPDATE_FAILED", "Embedded stack pool-id was not successfully updated", at(8)),
+				stackEvent("mycluster", "Controlplane", resourceTypeStack, "cp-id", "UPDATE_FAILED", "Resource update cancelled", at(8)),
+			},
+			{
+				stackEvent("mycluster", "Nodepool1", resourceTypeStack, "pool-id", "UPDATE_IN_PROGRESS", "", at(1)),
+				stackEvent("mycluster", "Nodepool1", resourceTypeStack, "pool-id", "UPDATE_FAILED", "failed in a previous update", since.Add(-time.Hour)),
+			},
+		},
+		"pool-id": {
+			{
+				stackEvent("mycluster-Nodepool1", "Workers", "AWS::AutoScaling::AutoScalingGroup", "asg", "UPDATE_FAILED", "Received 0 SUCCESS signal(s) out of 1", at(7)),
+				stackEvent("mycluster-Nodepool1", "WorkersLC", "AWS::AutoScaling::LaunchConfiguration", "lc", "UPDATE_COMPLETE", "", at(2)),
+			},
+		},
+	}}
+
+	summary, err := DescribeFailure(svc, "root-id", since)
+	require.NoError(t, err)
+
+	require.NotNil(t, summary.RootCause)
+	assert.Equal(t, "Workers", aws.StringValue(summary.RootCause.LogicalResourceId))
+	assert.Equal(t, `Root cause: UPDATE_FAILED AWS::AutoScaling::AutoScalingGroup Workers in stack mycluster-Nodepool1: Received 0 SUCCESS signal(s) out of 1
+
+Failed stack events of mycluster:
+  UPDATE_FAILED AWS::CloudFormation::Stack Nodepool1 Embedded stack pool-id was not successfully updated
+
+Failed stack events of mycluster-Nodepool1:
+  UPDATE_FAILED AWS::AutoScaling::AutoScalingGroup Workers Received 0 SUCCESS signal(s) out of 1`, summary.String())
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -22,6 +23,7 @@ type Provisioner struct {
 	s3URI           string
 	roleARN         string
 	region          api.Region
+	wait            WaitOptions
 }
 
 func NewProvisioner(name string, stackTags map[string]string, s3URI string, region api.Region, stackPolicyBody string, session *session.Session, options ...string) *Provisioner {
@@ -81,18 +83,24 @@ func (c *Provisioner) EstimateTemplateCost(cfSvc CRUDService, body string, param
 }
 
 func (c *Provisioner) CreateStackAtURLAndWait(cfSvc CRUDService, templateURL string) error {
+	since := time.Now()
 	resp, err := c.createStackFromTemplateURL(cfSvc, templateURL)
 	if err != nil {
 		return err
 	}
-	return c.waitUntilStackGetsCreated(cfSvc, resp)
+	return c.waitUntilStackGetsCreated(cfSvc, resp.StackId, since)
 }
 
-func (c *Provisioner) waitUntilStackGetsCreated(cfSvc CRUDService, resp *cloudformation.CreateStackOutput) error {
+// waitUntilStackGetsCreated waits until the stack gets created. `since` is the time the creation started, from which
+// the failed stack events are summarized when the creation fails
+func (c *Provisioner) waitUntilStackGetsCreated(cfSvc CRUDService, stackID *string, since time.Time) error {
 	req := cloudformation.DescribeStacksInput{
-		StackName: resp.StackId,
+		StackName: stackID,
 	}
 
+	w := c.startWait()
+	defer w.stop()
+
 	for {
 		resp, err := cfSvc.DescribeStacks(&req)
 		if err != nil {
@@ -106,24 +114,12 @@ func (c *Provisioner) waitUntilStackGetsCreated(cfSvc CRUDService, resp *cloudfo
 		case cloudformation.ResourceStatusCreateComplete:
 			return nil
 		case cloudformation.ResourceStatusCreateFailed:
-			errMsg := fmt.Sprintf(
-				"Stack creation failed: %s : %s",
-				statusString,
-				aws.StringValue(resp.Stacks[0].StackStatusReason),
-			)
-			errMsg = errMsg + "\n\nPrinting the most recent failed stack events:\n"
-
-			stackEventsOutput, err := cfSvc.DescribeStackEvents(
-				&cloudformation.DescribeStackEventsInput{
-					StackName: resp.Stacks[0].StackName,
-				})
-			if err != nil {
-				return err
+			return failed(cfSvc, resp.Stacks[0], since, "creation")
+		case cloudformation.ResourceStatusCreateInProgress, cloudformation.StackStatusReviewInProgress:
+			if err := w.sleep(); err != nil {
+				w.stop()
+				return c.abortCreate(err)
 			}
-			errMsg = errMsg + strings.Join(StackEventErrMsgs(stackEventsOutput.StackEvents), "\n")
-			return errors.New(errMsg)
-		case cloudformation.ResourceStatusCreateInProgress:
-			time.Sleep(3 * time.Second)
 			continue
 		default:
 			return fmt.Errorf("unexpected stack status: %s", statusString)
@@ -179,44 +175,75 @@ func (c *Provisioner) baseUpdateStackInput() *cloudformation.UpdateStackInput {
 	return input
 }
 
+// isNoUpdatesError returns true when CloudFormation rejected an update only because it contained no changes
+func isNoUpdatesError(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == "ValidationError" && strings.Contains(aerr.Message(), "No updates are to be performed")
+	}
+	return false
+}
+
 func (c *Provisioner) updateStackWithTemplateURL(cfSvc UpdateService, templateURL string) (*cloudformation.UpdateStackOutput, error) {
 	input := c.baseUpdateStackInput()
 	input.TemplateURL = aws.String(templateURL)
 	return cfSvc.UpdateStack(input)
 }
 
+// UpdateStackAtURLAndWait updates the stack with the template at the URL and waits until the update completes.
+// An update containing no changes e.g. the one of an upgrade phase not affecting the stack succeeds with an empty report
 func (c *Provisioner) UpdateStackAtURLAndWait(cfSvc CRUDService, templateURL string) (string, error) {
+	since := time.Now()
 	updateOutput, err := c.updateStackWithTemplateURL(cfSvc, templateURL)
+	if isNoUpdatesError(err) {
+		logger.Infof("No updates are to be performed to stack %s\n", c.stackName)
+		return "", nil
+	}
 	if err != nil {
 		return "", fmt.Errorf("error updating cloudformation stack: %v", err)
 	}
-	return c.waitUntilStackGetsUpdated(cfSvc, updateOutput)
+	if err := c.waitUntilStackGetsUpdated(cfSvc, updateOutput.StackId, since); err != nil {
+		return "", err
+	}
+	return updateOutput.String(), nil
 }
 
-func (c *Provisioner) waitUntilStackGetsUpdated(cfSvc CRUDService, updateOutput *cloudformation.UpdateStackOutput) (string, error) {
+// waitUntilStackGetsUpdated waits until the stack gets updated. `since` is the time the update started, from which
+// the failed stack events are summarized when the update fails
+func (c *Provisioner) waitUntilStackGetsUpdated(cfSvc CRUDService, stackID *string, since time.Time) error {
 	req := cloudformation.DescribeStacksInput{
-		StackName: updateOutput.StackId,
+		StackName: stackID,
 	}
+
+	w := c.startWait()
+	defer w.stop()
+
 	for {
 		resp, err := cfSvc.DescribeStacks(&req)
 		if err != nil {
-			return "", err
+			return err
 		}
 		if len(resp.Stacks) == 0 {
-			return "", fmt.Errorf("stack not found")
+			return fmt.Errorf("stack not found")
 		}
 		statusString := aws.StringValue(resp.Stacks[0].StackStatus)
 		switch statusString {
 		case cloudformation.ResourceStatusUpdateComplete:
-			return updateOutput.String(), nil
-		case cloudformation.ResourceStatusUpdateFailed, cloudformation.StackStatusUpdateRollbackComplete, cloudformation.StackStatusUpdateRollbackFailed:
-			errMsg := fmt.Sprintf("Stack status: %s : %s", statusString, aws.StringValue(resp.Stacks[0].StackStatusReason))
-			return "", errors.New(errMsg)
+			return nil
+		case cloudformation.ResourceStatusUpdateFailed,
+			cloudformation.StackStatusUpdateRollbackInProgress,
+			cloudformation.StackStatusUpdateRollbackCompleteCleanupInProgress,
+			cloudformation.StackStatusUpdateRollbackComplete,
+			cloudformation.StackStatusUpdateRollbackFailed:
+			return failed(cfSvc, resp.Stacks[0], since, "update")
 		case cloudformation.ResourceStatusUpdateInProgress, cloudformation.StackStatusUpdateCompleteCleanupInProgress:
-			time.Sleep(3 * time.Second)
+			if err := w.sleep(); err != nil {
+				// Ctrl-C while asked whether to cancel the update exits kube-aws
+				w.stop()
+				return c.abortUpdate(cfSvc, stackID, err)
+			}
 			continue
 		default:
-			return "", fmt.Errorf("unexpected stack status: %s", statusString)
+			return fmt.Errorf("unexpected stack status: %s", statusString)
 		}
 	}
 }
@@ -265,7 +292,9 @@ func (c *Destroyer) Destroy() error {
 	return err
 }
 
-func (c *Provisioner) StreamEventsNested(q chan struct{}, f *cloudformation.CloudFormation, stackId string, headStackName string, t time.Time) error {
+// StreamEventsNested logs the events of the stack and its nested stacks since `t` until `q` receives, each as a record with the fields
+// `cluster`, `phase`, `stack`, `resource`, `resourceType`, `status`, `reason` and `elapsed`
+func (c *Provisioner) StreamEventsNested(q chan struct{}, f *cloudformation.CloudFormation, stackId string, headStackName string, phase string, t time.Time) error {
 	nestedStacks := make(map[string]bool)
 	nestedQuit := make(chan struct{}, 1)
 	var lastSeenEventId string
@@ -296,29 +325,40 @@ func (c *Provisioner) StreamEventsNested(q chan struct{}, f *cloudformation.Clou
 				e := events[i]
 				if *e.ResourceType == "AWS::CloudFormation::Stack" && *e.PhysicalResourceId != *e.StackId && !nestedStacks[*e.PhysicalResourceId] {
 					nestedStacks[*e.PhysicalResourceId] = true
-					go c.StreamEventsNested(nestedQuit, f, *e.PhysicalResourceId, headStackName, t)
+					go c.StreamEventsNested(nestedQuit, f, *e.PhysicalResourceId, headStackName, phase, t)
 				}
-				eventPrettyPrint(e, headStackName, t)
+				eventPrettyPrint(e, headStackName, phase, t)
 				lastSeenEventId = *e.EventId
 			}
 		}
 	}
 }
 
-func eventPrettyPrint(e cloudformation.StackEvent, n string, t time.Time) {
-	ns := strings.Split(strings.TrimLeft(*e.StackName, n), "-")
+func eventPrettyPrint(e cloudformation.StackEvent, cluster string, phase string, t time.Time) {
+	n := ""
+	ns := strings.Split(strings.TrimLeft(*e.StackName, cluster), "-")
 	if len(ns) > 2 {
-		n = "\t" + ns[len(ns)-2]
-	} else {
-		n = ""
+		n = ns[len(ns)-2]
 	}
 
 	s := int((*e.Timestamp).Sub(t).Seconds())
 	d := fmt.Sprintf("+%.2d:%.2d:%.2d", s/3600, (s/60)%60, s%60)
+	log := logger.With(logger.Fields{
+		"cluster":      cluster,
+		"phase":        phase,
+		"stack":        aws.StringValue(e.StackName),
+		"resource":     aws.StringValue(e.LogicalResourceId),
+		"resourceType": aws.StringValue(e.ResourceType),
+		"status":       aws.StringValue(e.ResourceStatus),
+		"elapsed":      d,
+	})
+	if n != "" {
+		n = "\t" + n
+	}
 	if e.ResourceStatusReason != nil {
-		logger.Infof("%s%s\t%s\t\t%s\t\"%s\"\n", d, n, resize(*e.ResourceStatus, 24), resize(*e.LogicalResourceId, 22), *e.ResourceStatusReason)
+		log.With(logger.Fields{"reason": *e.ResourceStatusReason}).Infof("%s%s\t%s\t\t%s\t\"%s\"\n", d, n, resize(*e.ResourceStatus, 24), resize(*e.LogicalResourceId, 22), *e.ResourceStatusReason)
 	} else {
-		logger.Infof("%s%s\t%s\t\t%s\n", d, n, resize(*e.ResourceStatus, 24), resize(*e.LogicalResourceId, 22))
+		log.Infof("%s%s\t%s\t\t%s\n", d, n, resize(*e.ResourceStatus, 24), resize(*e.LogicalResourceId, 22))
 	}
 }
 
@@ -3,7 +3,14 @@ package cfnstack
 import (
 	"bytes"
 	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type dummyS3ObjectPutterService struct {
@@ -64,3 +71,39 @@ func (s3Svc dummyS3ObjectPutterService) PutObject(input *s3.PutObjectInput) (*s3
 
 	return resp, nil
 }
+
+type dummyNoUpdatesService struct {
+	CRUDService
+	describeCalls int
+}
+
+func (s *dummyNoUpdatesService) UpdateStack(input *cloudformation.UpdateStackInput) (*cloudformation.UpdateStackOutput, error) {
+	return nil, awserr.New("ValidationError", "No updates are to be performed.", nil)
+}
+
+func (s *dummyNoUpdatesService) DescribeStacks(input *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+	s.describeCalls++
+	return nil, fmt.Errorf("unexpected call to DescribeStacks")
+}
+
+type dummyFailingUpdateService struct {
+	CRUDService
+}
+
+func (s dummyFailingUpdateService) UpdateStack(input *cloudformation.UpdateStackInput) (*cloudformation.UpdateStackOutput, error) {
+	return nil, awserr.New("ValidationError", "Template format error", nil)
+}
+
+// The etcd phase of an upgrade submits the same root stack template again, as etcd doesn't depend on the Kubernetes version
+func TestUpdateStackAtURLAndWaitWithNoUpdates(t *testing.T) {
+	p := NewProvisioner("mycluster", map[string]string{}, "s3://mybucket/mydir", api.RegionForName("us-west-1"), "", nil)
+
+	svc := &dummyNoUpdatesService{}
+	report, err := p.UpdateStackAtURLAndWait(svc, "https://mybucket.s3.amazonaws.com/mydir/stack.json")
+	require.NoError(t, err)
+	assert.Equal(t, "", report)
+	assert.Equal(t, 0, svc.describeCalls)
+
+	_, err = p.UpdateStackAtURLAndWait(dummyFailingUpdateService{}, "https://mybucket.s3.amazonaws.com/mydir/stack.json")
+	assert.EqualError(t, err, "error updating cloudformation stack: ValidationError: Template format error")
+}
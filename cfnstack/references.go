@@ -0,0 +1,351 @@
+package cfnstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ReferenceReport is the result of checking the references in a root stack template and its nested stack templates
+type ReferenceReport struct {
+	// Errors are references which can never resolve, including templates which aren't valid JSON
+	Errors []string
+	// Unchecked are imports of values exported from outside the cluster, or whose names can't be determined without AWS
+	Unchecked []string
+}
+
+// OK returns true when every reference has resolved
+func (r *ReferenceReport) OK() bool {
+	return len(r.Errors) == 0
+}
+
+type template struct {
+	name          string
+	stackName     string
+	rootStackName string
+	Parameters    map[string]map[string]interface{}
+	Resources     map[string]map[string]interface{}
+	Outputs       map[string]map[string]interface{}
+
+	// params are the values of the parameters passed from the root stack, which can be resolved offline
+	params map[string]string
+	// nested are the templates of the nested stacks keyed by their logical IDs in this template
+	nested map[string]*template
+}
+
+var subVariable = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// CheckReferences checks that every Ref, Fn::GetAtt and Fn::ImportValue in the root stack template and the nested stack templates
+// resolves against the parameters, resources, nested stack outputs and exports of the templates, without accessing AWS.
+// `nestedTemplates` are keyed by the TemplateURL of the nested stacks in the root stack template.
+func CheckReferences(rootStackName string, rootTemplate string, nestedTemplates map[string]string) *ReferenceReport {
+	r := &ReferenceReport{Errors: []string{}, Unchecked: []string{}}
+
+	root, err := parseTemplate(rootStackName, rootTemplate)
+	if err != nil {
+		r.Errors = append(r.Errors, err.Error())
+		return r
+	}
+	root.stackName = rootStackName
+	root.rootStackName = rootStackName
+	templates := []*template{root}
+
+	for _, id := range sortedTemplateKeys(root.Resources) {
+		res := root.Resources[id]
+		if res["Type"] != "AWS::CloudFormation::Stack" {
+			continue
+		}
+		props, _ := res["Properties"].(map[string]interface{})
+		url, _ := props["TemplateURL"].(string)
+		body, ok := nestedTemplates[url]
+		if !ok {
+			r.Errors = append(r.Errors, fmt.Sprintf("%s: Resources.%s: no template rendered for TemplateURL %q", root.name, id, url))
+			continue
+		}
+		t, err := parseTemplate(id, body)
+		if err != nil {
+			r.Errors = append(r.Errors, err.Error())
+			continue
+		}
+		// CloudFormation names nested stacks like <root stack name>-<logical ID>-<random suffix>
+		t.stackName = rootStackName + "-" + id
+		t.rootStackName = rootStackName
+		root.nested[id] = t
+		templates = append(templates, t)
+	}
+
+	for _, id := range sortedTemplateKeys(root.nested) {
+		t := root.nested[id]
+		props, _ := root.Resources[id]["Properties"].(map[string]interface{})
+		passed, _ := props["Parameters"].(map[string]interface{})
+		for _, p := range sortedTemplateKeys(passed) {
+			if _, ok := t.Parameters[p]; !ok {
+				r.Errors = append(r.Errors, fmt.Sprintf("%s: Resources.%s.Properties.Parameters.%s: parameter not declared in the nested stack", root.name, id, p))
+				continue
+			}
+			if v, ok := root.resolve(passed[p]); ok {
+				t.params[p] = v
+			}
+		}
+		for _, p := range sortedTemplateKeys(t.Parameters) {
+			_, hasDefault := t.Parameters[p]["Default"]
+			if _, ok := passed[p]; !ok && !hasDefault {
+				r.Errors = append(r.Errors, fmt.Sprintf("%s: Parameters.%s: no value is passed from %s and it has no default", t.name, p, root.name))
+			}
+		}
+	}
+
+	exports := map[string]bool{}
+	for _, t := range templates {
+		for _, o := range t.Outputs {
+			export, _ := o["Export"].(map[string]interface{})
+			if name, ok := t.resolve(export["Name"]); ok {
+				exports[name] = true
+			}
+		}
+	}
+
+	for _, t := range templates {
+		t.walk(t.sections(), "", r, exports)
+	}
+
+	return r
+}
+
+func parseTemplate(name string, body string) (*template, error) {
+	t := &template{name: name, params: map[string]string{}, nested: map[string]*template{}}
+	if err := json.Unmarshal([]byte(body), t); err != nil {
+		return nil, fmt.Errorf("%s: invalid template: %v", name, err)
+	}
+	return t, nil
+}
+
+func (t *template) sections() map[string]interface{} {
+	s := map[string]interface{}{}
+	for k, v := range t.Resources {
+		s["Resources."+k] = v
+	}
+	for k, v := range t.Outputs {
+		s["Outputs."+k] = v
+	}
+	return s
+}
+
+func (t *template) walk(v interface{}, path string, r *ReferenceReport, exports map[string]bool) {
+	switch v := v.(type) {
+	case []interface{}:
+		for i, e := range v {
+			t.walk(e, fmt.Sprintf("%s[%d]", path, i), r, exports)
+		}
+	case map[string]interface{}:
+		if len(v) == 1 {
+			if err := t.checkFunction(v, r, exports); err != "" {
+				r.Errors = append(r.Errors, fmt.Sprintf("%s: %s: %s", t.name, path, err))
+			}
+		}
+		for _, k := range sortedTemplateKeys(v) {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			t.walk(v[k], p, r, exports)
+		}
+	}
+}
+
+// checkFunction checks the intrinsic function `f` and returns the reason why it doesn't resolve, if any
+func (t *template) checkFunction(f map[string]interface{}, r *ReferenceReport, exports map[string]bool) string {
+	if name, ok := f["Ref"].(string); ok {
+		return t.checkRef(name)
+	}
+	if args, ok := f["Fn::GetAtt"]; ok {
+		switch args := args.(type) {
+		case string:
+			parts := strings.SplitN(args, ".", 2)
+			if len(parts) == 2 {
+				return t.checkGetAtt(parts[0], parts[1])
+			}
+			return fmt.Sprintf("invalid Fn::GetAtt %q", args)
+		case []interface{}:
+			if len(args) == 0 {
+				return "invalid Fn::GetAtt []"
+			}
+			res, _ := args[0].(string)
+			attr := ""
+			if len(args) > 1 {
+				attr, _ = args[1].(string)
+			}
+			return t.checkGetAtt(res, attr)
+		}
+	}
+	if args, ok := f["Fn::Sub"]; ok {
+		s, vars := "", map[string]interface{}{}
+		switch args := args.(type) {
+		case string:
+			s = args
+		case []interface{}:
+			if len(args) == 0 {
+				return "invalid Fn::Sub []"
+			}
+			s, _ = args[0].(string)
+			if len(args) > 1 {
+				vars, _ = args[1].(map[string]interface{})
+			}
+		}
+		for _, m := range subVariable.FindAllStringSubmatch(s, -1) {
+			name := m[1]
+			if strings.HasPrefix(name, "!") {
+				continue
+			}
+			if _, ok := vars[name]; ok {
+				continue
+			}
+			var err string
+			if parts := strings.SplitN(name, ".", 2); len(parts) == 2 && !strings.HasPrefix(name, "AWS::") {
+				err = t.checkGetAtt(parts[0], parts[1])
+			} else {
+				err = t.checkRef(name)
+			}
+			if err != "" {
+				return err
+			}
+		}
+	}
+	if args, ok := f["Fn::ImportValue"]; ok {
+		name, ok := t.resolve(args)
+		switch {
+		case !ok:
+			r.Unchecked = append(r.Unchecked, fmt.Sprintf("%s: import whose name can't be determined offline: %s", t.name, toJSON(args)))
+		case exports[name]:
+		case t.isClusterExport(name):
+			return fmt.Sprintf("Fn::ImportValue of %q which no stack in the cluster exports", name)
+		default:
+			r.Unchecked = append(r.Unchecked, fmt.Sprintf("%s: import of %q exported from outside the cluster", t.name, name))
+		}
+	}
+	return ""
+}
+
+func (t *template) checkRef(name string) string {
+	if strings.HasPrefix(name, "AWS::") {
+		return ""
+	}
+	if _, ok := t.Parameters[name]; ok {
+		return ""
+	}
+	if _, ok := t.Resources[name]; ok {
+		return ""
+	}
+	return fmt.Sprintf("Ref to undefined parameter or resource %q", name)
+}
+
+func (t *template) checkGetAtt(res, attr string) string {
+	if _, ok := t.Resources[res]; !ok {
+		return fmt.Sprintf("Fn::GetAtt of undefined resource %q", res)
+	}
+	if nested, ok := t.nested[res]; ok && strings.HasPrefix(attr, "Outputs.") {
+		output := strings.TrimPrefix(attr, "Outputs.")
+		if _, ok := nested.Outputs[output]; !ok {
+			return fmt.Sprintf("Fn::GetAtt of undefined output %q of nested stack %s", output, res)
+		}
+	}
+	return ""
+}
+
+// isClusterExport returns true when `name` is prefixed with the name of a stack in the cluster, so that it must be exported by the cluster
+func (t *template) isClusterExport(name string) bool {
+	return strings.HasPrefix(name, t.rootStackName+"-")
+}
+
+// resolve returns the string value of `v` when it can be determined from the template alone
+func (t *template) resolve(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case map[string]interface{}:
+		if len(v) != 1 {
+			return "", false
+		}
+		if name, ok := v["Ref"].(string); ok {
+			return t.resolveVariable(name)
+		}
+		if args, ok := v["Fn::Sub"].(string); ok {
+			unresolved := false
+			s := subVariable.ReplaceAllStringFunc(args, func(m string) string {
+				name := m[2 : len(m)-1]
+				if strings.HasPrefix(name, "!") {
+					return "${" + name[1:] + "}"
+				}
+				value, ok := t.resolveVariable(name)
+				unresolved = unresolved || !ok
+				return value
+			})
+			return s, !unresolved
+		}
+		if args, ok := v["Fn::Join"].([]interface{}); ok && len(args) == 2 {
+			sep, _ := args[0].(string)
+			items, _ := args[1].([]interface{})
+			parts := []string{}
+			for _, item := range items {
+				p, ok := t.resolve(item)
+				if !ok {
+					return "", false
+				}
+				parts = append(parts, p)
+			}
+			return strings.Join(parts, sep), true
+		}
+		if args, ok := v["Fn::GetAtt"].([]interface{}); ok && len(args) == 2 {
+			res, _ := args[0].(string)
+			attr, _ := args[1].(string)
+			nested, ok := t.nested[res]
+			if !ok || !strings.HasPrefix(attr, "Outputs.") {
+				return "", false
+			}
+			output, ok := nested.Outputs[strings.TrimPrefix(attr, "Outputs.")]
+			if !ok {
+				return "", false
+			}
+			return nested.resolve(output["Value"])
+		}
+	}
+	return "", false
+}
+
+func (t *template) resolveVariable(name string) (string, bool) {
+	if name == "AWS::StackName" {
+		return t.stackName, true
+	}
+	v, ok := t.params[name]
+	return v, ok
+}
+
+func sortedTemplateKeys(m interface{}) []string {
+	keys := []string{}
+	switch m := m.(type) {
+	case map[string]interface{}:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]map[string]interface{}:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*template:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,137 @@
+package cfnstack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const referencesRootTemplate = `{
+  "Resources": {
+    "Network": {
+      "Type": "AWS::CloudFormation::Stack",
+      "Properties": {"TemplateURL": "https://s3.amazonaws.com/b/network/stack.json"}
+    },
+    "Controlplane": {
+      "Type": "AWS::CloudFormation::Stack",
+      "Properties": {
+        "Parameters": {
+          "NetworkStackName": {"Fn::GetAtt": ["Network", "Outputs.StackName"]}
+        },
+        "TemplateURL": "https://s3.amazonaws.com/b/control-plane/stack.json"
+      }
+    }
+  },
+  "Outputs": {
+    "ControlPlaneStackName": {"Value": {"Fn::GetAtt": ["Controlplane", "Outputs.StackName"]}}
+  }
+}`
+
+const referencesNetworkTemplate = `{
+  "Resources": {
+    "VPC": {"Type": "AWS::EC2::VPC", "Properties": {"CidrBlock": "10.0.0.0/16"}}
+  },
+  "Outputs": {
+    "StackName": {"Value": {"Ref": "AWS::StackName"}},
+    "VPC": {"Value": {"Ref": "VPC"}, "Export": {"Name": {"Fn::Sub": "${AWS::StackName}-VPC"}}}
+  }
+}`
+
+func TestCheckReferences(t *testing.T) {
+	testCases := []struct {
+		context      string
+		controlPlane string
+		errors       []string
+		unchecked    []string
+	}{
+		{
+			context: "Resolved",
+			controlPlane: `{
+  "Parameters": {"NetworkStackName": {"Type": "String"}},
+  "Resources": {
+    "SecurityGroup": {
+      "Type": "AWS::EC2::SecurityGroup",
+      "Properties": {
+        "GroupDescription": {"Fn::Sub": "${AWS::StackName} in ${NetworkStackName}"},
+        "VpcId": {"Fn::ImportValue": {"Fn::Sub": "${NetworkStackName}-VPC"}},
+        "Tags": [{"Key": "Shared", "Value": {"Fn::ImportValue": "shared-stack-Tag"}}]
+      }
+    }
+  },
+  "Outputs": {
+    "StackName": {"Value": {"Ref": "AWS::StackName"}},
+    "SecurityGroupId": {"Value": {"Fn::GetAtt": ["SecurityGroup", "GroupId"]}}
+  }
+}`,
+			errors:    []string{},
+			unchecked: []string{`Controlplane: import of "shared-stack-Tag" exported from outside the cluster`},
+		},
+		{
+			context: "Unresolved",
+			controlPlane: `{
+  "Parameters": {"NetworkStackName": {"Type": "String"}, "KeyName": {"Type": "String"}},
+  "Resources": {
+    "SecurityGroup": {
+      "Type": "AWS::EC2::SecurityGroup",
+      "Properties": {
+        "GroupDescription": {"Ref": "Descripton"},
+        "VpcId": {"Fn::ImportValue": {"Fn::Sub": "${NetworkStackName}-Vpc"}},
+        "Tags": [{"Key": "Role", "Value": {"Fn::Sub": "${Role.Name}"}}]
+      }
+    }
+  },
+  "Outputs": {
+    "SecurityGroupId": {"Value": {"Fn::GetAtt": "SecurityGroups.GroupId"}}
+  }
+}`,
+			errors: []string{
+				`Controlplane: Parameters.KeyName: no value is passed from mycluster and it has no default`,
+				`mycluster: Outputs.ControlPlaneStackName.Value: Fn::GetAtt of undefined output "StackName" of nested stack Controlplane`,
+				`Controlplane: Outputs.SecurityGroupId.Value: Fn::GetAtt of undefined resource "SecurityGroups"`,
+				`Controlplane: Resources.SecurityGroup.Properties.GroupDescription: Ref to undefined parameter or resource "Descripton"`,
+				`Controlplane: Resources.SecurityGroup.Properties.Tags[0].Value: Fn::GetAtt of undefined resource "Role"`,
+				`Controlplane: Resources.SecurityGroup.Properties.VpcId: Fn::ImportValue of "mycluster-Network-Vpc" which no stack in the cluster exports`,
+			},
+			unchecked: []string{},
+		},
+		{
+			context: "EmptyArguments",
+			controlPlane: `{
+  "Parameters": {"NetworkStackName": {"Type": "String"}},
+  "Resources": {
+    "SecurityGroup": {
+      "Type": "AWS::EC2::SecurityGroup",
+      "Properties": {"GroupDescription": {"Fn::Sub": []}}
+    }
+  },
+  "Outputs": {
+    "StackName": {"Value": {"Ref": "AWS::StackName"}},
+    "SecurityGroupId": {"Value": {"Fn::GetAtt": []}}
+  }
+}`,
+			errors: []string{
+				`Controlplane: Outputs.SecurityGroupId.Value: invalid Fn::GetAtt []`,
+				`Controlplane: Resources.SecurityGroup.Properties.GroupDescription: invalid Fn::Sub []`,
+			},
+			unchecked: []string{},
+		},
+		{
+			context:      "InvalidJSON",
+			controlPlane: `{"Resources": {}`,
+			errors:       []string{"Controlplane: invalid template: unexpected end of JSON input"},
+			unchecked:    []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			r := CheckReferences("mycluster", referencesRootTemplate, map[string]string{
+				"https://s3.amazonaws.com/b/network/stack.json":       referencesNetworkTemplate,
+				"https://s3.amazonaws.com/b/control-plane/stack.json": tc.controlPlane,
+			})
+			assert.Equal(t, tc.errors, r.Errors)
+			assert.Equal(t, tc.unchecked, r.Unchecked)
+			assert.Equal(t, len(tc.errors) == 0, r.OK())
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package cfnstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// RetainedResource is a resource left behind when its stack is deleted, or a snapshot of it
+type RetainedResource struct {
+	StackName  string
+	LogicalID  string
+	PhysicalID string
+	Type       string
+	// DeletionPolicy is either `Retain` or `Snapshot`
+	DeletionPolicy string
+}
+
+func (r RetainedResource) String() string {
+	what := r.PhysicalID
+	if r.DeletionPolicy == "Snapshot" {
+		what = fmt.Sprintf("a snapshot of %s", r.PhysicalID)
+	}
+	return fmt.Sprintf("%s (%s %s in stack %s)", what, r.Type, r.LogicalID, r.StackName)
+}
+
+// RetainedResources returns the resources in the template with the `Retain` or `Snapshot` deletion policy, sorted by logical ID.
+// The stack name and the physical IDs are left for the caller to fill.
+func RetainedResources(template string) ([]RetainedResource, error) {
+	parsed := struct {
+		Resources map[string]struct {
+			Type           string
+			DeletionPolicy string
+		}
+	}{}
+	if err := json.Unmarshal([]byte(template), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse template: %v", err)
+	}
+
+	retained := []RetainedResource{}
+	for id, r := range parsed.Resources {
+		if r.DeletionPolicy == "Retain" || r.DeletionPolicy == "Snapshot" {
+			retained = append(retained, RetainedResource{LogicalID: id, Type: r.Type, DeletionPolicy: r.DeletionPolicy})
+		}
+	}
+	sort.Slice(retained, func(i, j int) bool { return retained[i].LogicalID < retained[j].LogicalID })
+	return retained, nil
+}
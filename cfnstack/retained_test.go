@@ -0,0 +1,28 @@
+package cfnstack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetainedResources(t *testing.T) {
+	retained, err := RetainedResources(`{
+  "Resources": {
+    "Etcd1EBS": {"Type": "AWS::EC2::Volume", "DeletionPolicy": "Snapshot"},
+    "Etcd0EBS": {"Type": "AWS::EC2::Volume", "DeletionPolicy": "Retain", "UpdateReplacePolicy": "Retain"},
+    "Etcd0": {"Type": "AWS::AutoScaling::AutoScalingGroup"},
+    "EtcdLogs": {"Type": "AWS::Logs::LogGroup", "DeletionPolicy": "Delete"}
+  }
+}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []RetainedResource{
+		{LogicalID: "Etcd0EBS", Type: "AWS::EC2::Volume", DeletionPolicy: "Retain"},
+		{LogicalID: "Etcd1EBS", Type: "AWS::EC2::Volume", DeletionPolicy: "Snapshot"},
+	}, retained)
+
+	retained[1].StackName, retained[1].PhysicalID = "mycluster-Etcd-1A2B3C", "vol-0123"
+	assert.Equal(t, "a snapshot of vol-0123 (AWS::EC2::Volume Etcd1EBS in stack mycluster-Etcd-1A2B3C)", retained[1].String())
+}
@@ -0,0 +1,206 @@
+package cfnstack
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	resourceTypeStack            = "AWS::CloudFormation::Stack"
+	resourceTypeAutoScalingGroup = "AWS::AutoScaling::AutoScalingGroup"
+	resourceTypeSpotFleet        = "AWS::EC2::SpotFleet"
+)
+
+// StackStatusService is the subset of the CloudFormation API required to describe the status of a stack
+type StackStatusService interface {
+	DescribeStacks(input *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error)
+	ListStackResources(input *cloudformation.ListStackResourcesInput) (*cloudformation.ListStackResourcesOutput, error)
+}
+
+// AutoScalingGroupDescriber is the subset of the Auto Scaling API required to count instances in auto scaling groups
+type AutoScalingGroupDescriber interface {
+	DescribeAutoScalingGroups(input *autoscaling.DescribeAutoScalingGroupsInput) (*autoscaling.DescribeAutoScalingGroupsOutput, error)
+}
+
+// SpotFleetDescriber is the subset of the EC2 API required to count instances in spot fleets
+type SpotFleetDescriber interface {
+	DescribeSpotFleetRequests(input *ec2.DescribeSpotFleetRequestsInput) (*ec2.DescribeSpotFleetRequestsOutput, error)
+	DescribeSpotFleetInstances(input *ec2.DescribeSpotFleetInstancesInput) (*ec2.DescribeSpotFleetInstancesOutput, error)
+}
+
+// StackStatus is the status of a stack and the instance groups managed by it
+type StackStatus struct {
+	Name         string        `json:"name" yaml:"name"`
+	StackName    string        `json:"stackName" yaml:"stackName"`
+	Status       string        `json:"status" yaml:"status"`
+	StatusReason string        `json:"statusReason,omitempty" yaml:"statusReason,omitempty"`
+	LastUpdated  time.Time     `json:"lastUpdated" yaml:"lastUpdated"`
+	InProgress   bool          `json:"inProgress" yaml:"inProgress"`
+	Groups       []GroupStatus `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// GroupStatus is the number of instances in an auto scaling group or a spot fleet
+type GroupStatus struct {
+	LogicalID  string `json:"logicalId" yaml:"logicalId"`
+	PhysicalID string `json:"physicalId" yaml:"physicalId"`
+	Type       string `json:"type" yaml:"type"`
+	Desired    int64  `json:"desired" yaml:"desired"`
+	InService  int64  `json:"inService" yaml:"inService"`
+	Pending    int64  `json:"pending" yaml:"pending"`
+}
+
+// Unhealthy returns the reasons why the stack is unhealthy, or nothing when the last operation on the stack has succeeded
+// and all the desired instances are in service
+func (s *StackStatus) Unhealthy() []string {
+	reasons := []string{}
+	if s.Status != cloudformation.StackStatusCreateComplete && s.Status != cloudformation.StackStatusUpdateComplete {
+		reasons = append(reasons, fmt.Sprintf("stack %s is %s", s.StackName, s.Status))
+	}
+	for _, g := range s.Groups {
+		if g.InService < g.Desired {
+			reasons = append(reasons, fmt.Sprintf("%d of %d instances in %s are in service", g.InService, g.Desired, g.LogicalID))
+		}
+	}
+	return reasons
+}
+
+// NestedStacks maps logical IDs of nested stacks to their physical IDs
+type NestedStacks map[string]string
+
+// StatusDescriber describes stacks along with the auto scaling groups and the spot fleets in them
+type StatusDescriber struct {
+	CloudFormation StackStatusService
+	AutoScaling    AutoScalingGroupDescriber
+	EC2            SpotFleetDescriber
+}
+
+// Describe returns the status of the stack named `stackName`, labeled with `name`, and its nested stacks keyed by logical IDs
+func (d StatusDescriber) Describe(name string, stackName string) (*StackStatus, NestedStacks, error) {
+	resp, err := d.CloudFormation.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error describing stack %s: %v", stackName, err)
+	}
+	if len(resp.Stacks) == 0 {
+		return nil, nil, fmt.Errorf("could not find a stack with name %s", stackName)
+	}
+	stack := resp.Stacks[0]
+
+	status := &StackStatus{
+		Name:         name,
+		StackName:    aws.StringValue(stack.StackName),
+		Status:       aws.StringValue(stack.StackStatus),
+		StatusReason: aws.StringValue(stack.StackStatusReason),
+		Groups:       []GroupStatus{},
+	}
+	status.InProgress = strings.HasSuffix(status.Status, "_IN_PROGRESS")
+	if stack.LastUpdatedTime != nil {
+		status.LastUpdated = *stack.LastUpdatedTime
+	} else {
+		status.LastUpdated = aws.TimeValue(stack.CreationTime)
+	}
+
+	nested := NestedStacks{}
+	input := &cloudformation.ListStackResourcesInput{StackName: stack.StackId}
+	for {
+		out, err := d.CloudFormation.ListStackResources(input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing resources of stack %s: %v", stackName, err)
+		}
+		for _, r := range out.StackResourceSummaries {
+			physicalID := aws.StringValue(r.PhysicalResourceId)
+			if physicalID == "" {
+				continue
+			}
+			logicalID := aws.StringValue(r.LogicalResourceId)
+			switch aws.StringValue(r.ResourceType) {
+			case resourceTypeStack:
+				nested[logicalID] = physicalID
+			case resourceTypeAutoScalingGroup:
+				g, err := d.describeAutoScalingGroup(logicalID, physicalID)
+				if err != nil {
+					return nil, nil, err
+				}
+				status.Groups = append(status.Groups, *g)
+			case resourceTypeSpotFleet:
+				g, err := d.describeSpotFleet(logicalID, physicalID)
+				if err != nil {
+					return nil, nil, err
+				}
+				status.Groups = append(status.Groups, *g)
+			}
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+
+	return status, nested, nil
+}
+
+func (d StatusDescriber) describeAutoScalingGroup(logicalID, name string) (*GroupStatus, error) {
+	g := &GroupStatus{LogicalID: logicalID, PhysicalID: name, Type: resourceTypeAutoScalingGroup}
+
+	resp, err := d.AutoScaling.DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{aws.String(name)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing auto scaling group %s: %v", name, err)
+	}
+	if len(resp.AutoScalingGroups) == 0 {
+		return g, nil
+	}
+	asg := resp.AutoScalingGroups[0]
+
+	g.Desired = aws.Int64Value(asg.DesiredCapacity)
+	for _, i := range asg.Instances {
+		state := aws.StringValue(i.LifecycleState)
+		switch {
+		case state == autoscaling.LifecycleStateInService:
+			g.InService++
+		case strings.HasPrefix(state, "Pending"):
+			g.Pending++
+		}
+	}
+	return g, nil
+}
+
+func (d StatusDescriber) describeSpotFleet(logicalID, id string) (*GroupStatus, error) {
+	g := &GroupStatus{LogicalID: logicalID, PhysicalID: id, Type: resourceTypeSpotFleet}
+
+	resp, err := d.EC2.DescribeSpotFleetRequests(&ec2.DescribeSpotFleetRequestsInput{
+		SpotFleetRequestIds: []*string{aws.String(id)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing spot fleet request %s: %v", id, err)
+	}
+	if len(resp.SpotFleetRequestConfigs) == 0 || resp.SpotFleetRequestConfigs[0].SpotFleetRequestConfig == nil {
+		return g, nil
+	}
+	config := resp.SpotFleetRequestConfigs[0].SpotFleetRequestConfig
+
+	g.Desired = aws.Int64Value(config.TargetCapacity)
+	if pending := g.Desired - int64(aws.Float64Value(config.FulfilledCapacity)); pending > 0 {
+		g.Pending = pending
+	}
+
+	input := &ec2.DescribeSpotFleetInstancesInput{SpotFleetRequestId: aws.String(id)}
+	for {
+		out, err := d.EC2.DescribeSpotFleetInstances(input)
+		if err != nil {
+			return nil, fmt.Errorf("error describing instances of spot fleet request %s: %v", id, err)
+		}
+		g.InService += int64(len(out.ActiveInstances))
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return g, nil
+}
@@ -0,0 +1,136 @@
+package cfnstack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dummyStackStatusService struct {
+	stack     *cloudformation.Stack
+	resources []*cloudformation.StackResourceSummary
+}
+
+func (s dummyStackStatusService) DescribeStacks(input *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+	return &cloudformation.DescribeStacksOutput{Stacks: []*cloudformation.Stack{s.stack}}, nil
+}
+
+func (s dummyStackStatusService) ListStackResources(input *cloudformation.ListStackResourcesInput) (*cloudformation.ListStackResourcesOutput, error) {
+	// Return resources one by one to exercise pagination
+	i := 0
+	if input.NextToken != nil {
+		for i < len(s.resources) && aws.StringValue(s.resources[i].LogicalResourceId) != aws.StringValue(input.NextToken) {
+			i++
+		}
+	}
+	out := &cloudformation.ListStackResourcesOutput{StackResourceSummaries: s.resources[i : i+1]}
+	if i+1 < len(s.resources) {
+		out.NextToken = s.resources[i+1].LogicalResourceId
+	}
+	return out, nil
+}
+
+type dummyAutoScalingGroupDescriber struct{}
+
+func (d dummyAutoScalingGroupDescriber) DescribeAutoScalingGroups(input *autoscaling.DescribeAutoScalingGroupsInput) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+	return &autoscaling.DescribeAutoScalingGroupsOutput{
+		AutoScalingGroups: []*autoscaling.Group{
+			{
+				AutoScalingGroupName: input.AutoScalingGroupNames[0],
+				DesiredCapacity:      aws.Int64(3),
+				Instances: []*autoscaling.Instance{
+					{LifecycleState: aws.String(autoscaling.LifecycleStateInService)},
+					{LifecycleState: aws.String(autoscaling.LifecycleStateInService)},
+					{LifecycleState: aws.String(autoscaling.LifecycleStatePendingWait)},
+				},
+			},
+		},
+	}, nil
+}
+
+type dummySpotFleetDescriber struct{}
+
+func (d dummySpotFleetDescriber) DescribeSpotFleetRequests(input *ec2.DescribeSpotFleetRequestsInput) (*ec2.DescribeSpotFleetRequestsOutput, error) {
+	return &ec2.DescribeSpotFleetRequestsOutput{
+		SpotFleetRequestConfigs: []*ec2.SpotFleetRequestConfig{
+			{
+				SpotFleetRequestConfig: &ec2.SpotFleetRequestConfigData{
+					TargetCapacity:    aws.Int64(4),
+					FulfilledCapacity: aws.Float64(1),
+				},
+			},
+		},
+	}, nil
+}
+
+func (d dummySpotFleetDescriber) DescribeSpotFleetInstances(input *ec2.DescribeSpotFleetInstancesInput) (*ec2.DescribeSpotFleetInstancesOutput, error) {
+	return &ec2.DescribeSpotFleetInstancesOutput{
+		ActiveInstances: []*ec2.ActiveInstance{{InstanceId: aws.String("i-1")}},
+	}, nil
+}
+
+func TestStatusDescriber(t *testing.T) {
+	updated := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	describer := StatusDescriber{
+		CloudFormation: dummyStackStatusService{
+			stack: &cloudformation.Stack{
+				StackId:         aws.String("arn:stack"),
+				StackName:       aws.String("mycluster-Workers-ABC"),
+				StackStatus:     aws.String(cloudformation.StackStatusUpdateInProgress),
+				CreationTime:    aws.Time(updated.Add(-time.Hour)),
+				LastUpdatedTime: aws.Time(updated),
+			},
+			resources: []*cloudformation.StackResourceSummary{
+				{LogicalResourceId: aws.String("Workers"), PhysicalResourceId: aws.String("workers-asg"), ResourceType: aws.String(resourceTypeAutoScalingGroup)},
+				{LogicalResourceId: aws.String("SpotFleet"), PhysicalResourceId: aws.String("sfr-1"), ResourceType: aws.String(resourceTypeSpotFleet)},
+				{LogicalResourceId: aws.String("Nested"), PhysicalResourceId: aws.String("arn:nested"), ResourceType: aws.String(resourceTypeStack)},
+				{LogicalResourceId: aws.String("NotYetCreated"), ResourceType: aws.String(resourceTypeAutoScalingGroup)},
+			},
+		},
+		AutoScaling: dummyAutoScalingGroupDescriber{},
+		EC2:         dummySpotFleetDescriber{},
+	}
+
+	status, nested, err := describer.Describe("workers", "arn:stack")
+	require.NoError(t, err)
+
+	assert.Equal(t, "workers", status.Name)
+	assert.Equal(t, "mycluster-Workers-ABC", status.StackName)
+	assert.Equal(t, cloudformation.StackStatusUpdateInProgress, status.Status)
+	assert.True(t, status.InProgress)
+	assert.Equal(t, updated, status.LastUpdated)
+
+	assert.Equal(t, []GroupStatus{
+		{LogicalID: "Workers", PhysicalID: "workers-asg", Type: resourceTypeAutoScalingGroup, Desired: 3, InService: 2, Pending: 1},
+		{LogicalID: "SpotFleet", PhysicalID: "sfr-1", Type: resourceTypeSpotFleet, Desired: 4, InService: 1, Pending: 3},
+	}, status.Groups)
+
+	assert.Equal(t, NestedStacks{"Nested": "arn:nested"}, nested)
+
+	assert.Equal(t, []string{
+		"stack mycluster-Workers-ABC is UPDATE_IN_PROGRESS",
+		"2 of 3 instances in Workers are in service",
+		"1 of 4 instances in SpotFleet are in service",
+	}, status.Unhealthy())
+}
+
+func TestStackStatusUnhealthy(t *testing.T) {
+	status := &StackStatus{
+		StackName: "mycluster-Workers-ABC",
+		Status:    cloudformation.StackStatusUpdateComplete,
+		Groups: []GroupStatus{
+			{LogicalID: "Workers", Desired: 3, InService: 3},
+		},
+	}
+	assert.Empty(t, status.Unhealthy())
+
+	status.Status = cloudformation.StackStatusUpdateRollbackComplete
+	assert.Equal(t, []string{"stack mycluster-Workers-ABC is UPDATE_ROLLBACK_COMPLETE"}, status.Unhealthy())
+}
@@ -0,0 +1,95 @@
+package cfnstack
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+const waitInterval = 3 * time.Second
+
+// WaitOptions controls how long the provisioner waits for a stack to get created or updated, and what it does with the
+// stack operation in progress when the wait is interrupted by SIGINT or SIGTERM or times out
+type WaitOptions struct {
+	// Timeout is the time to wait for the stack operation to finish. Zero waits forever
+	Timeout time.Duration
+	// ConfirmCancel is asked with the reason of the interruption whether to cancel the stack update in progress.
+	// When nil or returning false, the update is left running
+	ConfirmCancel func(reason string) bool
+}
+
+// WithWaitOptions sets the options used by the provisioner to wait for stack operations
+func (c *Provisioner) WithWaitOptions(opts WaitOptions) *Provisioner {
+	c.wait = opts
+	return c
+}
+
+// waiter sleeps between polls of a stack operation until it is interrupted or times out
+type waiter struct {
+	timeout  time.Duration
+	deadline time.Time
+	signals  chan os.Signal
+}
+
+func (c *Provisioner) startWait() *waiter {
+	w := &waiter{
+		timeout:  c.wait.Timeout,
+		deadline: time.Now().Add(c.wait.Timeout),
+		signals:  make(chan os.Signal, 1),
+	}
+	signal.Notify(w.signals, os.Interrupt, syscall.SIGTERM)
+	return w
+}
+
+// stop restores the default handling of the signals, so that e.g. pressing Ctrl-C again exits kube-aws
+func (w *waiter) stop() {
+	signal.Stop(w.signals)
+}
+
+// sleep returns an error describing why the wait should be aborted, or nil when the stack operation should be polled again
+func (w *waiter) sleep() error {
+	select {
+	case s := <-w.signals:
+		name := "SIGINT"
+		if s == syscall.SIGTERM {
+			name = "SIGTERM"
+		}
+		return fmt.Errorf("interrupted by %s", name)
+	case <-time.After(waitInterval):
+	}
+	if w.timeout > 0 && time.Now().After(w.deadline) {
+		return fmt.Errorf("timed out after %s", w.timeout)
+	}
+	return nil
+}
+
+// abortCreate gives up waiting for the stack creation, which can't be cancelled but only deleted once finished
+func (c *Provisioner) abortCreate(reason error) error {
+	return fmt.Errorf("%v: the creation of stack %s is still in progress. Run `kube-aws status` to follow it, or `kube-aws destroy` to delete the stack once it has finished", reason, c.stackName)
+}
+
+// abortUpdate gives up waiting for the stack update, cancelling it when confirmed
+func (c *Provisioner) abortUpdate(cfSvc CRUDService, stackID *string, reason error) error {
+	if c.wait.ConfirmCancel == nil || !c.wait.ConfirmCancel(fmt.Sprintf("Stopped waiting for the update of stack %s: %v", c.stackName, reason)) {
+		return fmt.Errorf("%v: the update of stack %s is still in progress. Run `kube-aws status` to follow it", reason, c.stackName)
+	}
+	if _, err := cfSvc.CancelUpdateStack(&cloudformation.CancelUpdateStackInput{StackName: stackID}); err != nil {
+		return fmt.Errorf("%v: failed to cancel the update of stack %s: %v", reason, c.stackName, err)
+	}
+	return fmt.Errorf("%v: cancelled the update of stack %s, which is rolling back. Run `kube-aws status` to follow the rollback", reason, c.stackName)
+}
+
+// failed returns the error for the failed stack operation along with the summary of the failures in the stack and its nested stacks
+func failed(cfSvc CRUDService, stack *cloudformation.Stack, since time.Time, operation string) error {
+	msg := fmt.Sprintf("Stack %s failed: %s : %s", operation, aws.StringValue(stack.StackStatus), aws.StringValue(stack.StackStatusReason))
+	summary, err := DescribeFailure(cfSvc, aws.StringValue(stack.StackId), since)
+	if err != nil {
+		return fmt.Errorf("%s\n\nfailed to summarize the failure: %v", msg, err)
+	}
+	return fmt.Errorf("%s\n\n%s", msg, summary)
+}
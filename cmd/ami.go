@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cmdAMI = &cobra.Command{
+		Use:   "ami",
+		Short: "Manage the AMIs pinned for nodes",
+		Long:  `Nodes without amiId run the latest amd64 AMI of their host OS and release channel, or the one pinned in ami-lock.yaml next to cluster.yaml`,
+	}
+
+	cmdAMIList = &cobra.Command{
+		Use:          "list",
+		Short:        "List the AMIs of the nodes with the pinned and the latest ones",
+		Long:         ``,
+		RunE:         runCmdAMIList,
+		SilenceUsage: true,
+		Annotations:  structuredOutput,
+	}
+
+	cmdAMIPin = &cobra.Command{
+		Use:          "pin",
+		Short:        "Pin the latest AMIs of the nodes which aren't pinned yet",
+		Long:         ``,
+		RunE:         runCmdAMIPin,
+		SilenceUsage: true,
+	}
+
+	cmdAMIUpdate = &cobra.Command{
+		Use:          "update",
+		Short:        "Pin the latest AMIs of all the nodes, replacing the ones pinned before",
+		Long:         ``,
+		RunE:         runCmdAMIUpdate,
+		SilenceUsage: true,
+	}
+
+	amiOpts = struct {
+		offline bool
+	}{}
+)
+
+func init() {
+	RootCmd.AddCommand(cmdAMI)
+	cmdAMI.AddCommand(cmdAMIList)
+	cmdAMI.AddCommand(cmdAMIPin)
+	cmdAMI.AddCommand(cmdAMIUpdate)
+
+	cmdAMIList.Flags().BoolVar(&amiOpts.offline, "offline", false, "List the pinned AMIs only, without looking up the latest ones")
+}
+
+func runCmdAMIList(_ *cobra.Command, _ []string) error {
+	amis, err := root.ListAMIs(configPath, amiOpts.offline)
+	if err != nil {
+		return fmt.Errorf("failed to list AMIs: %v", err)
+	}
+
+	return printResult(amis, func() {
+		buf := new(bytes.Buffer)
+		w := new(tabwriter.Writer)
+		w.Init(buf, 0, 8, 2, ' ', 0)
+		fmt.Fprintf(w, "OS\tREGION\tCHANNEL\tPINNED\tLATEST\n")
+		for _, a := range amis {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.OS, a.Region, orNone(a.Channel), orNone(a.Pinned), orNone(a.Latest))
+		}
+		w.Flush()
+		logger.Info(buf.String())
+	})
+}
+
+func runCmdAMIPin(_ *cobra.Command, _ []string) error {
+	return pinAMIs(false)
+}
+
+func runCmdAMIUpdate(_ *cobra.Command, _ []string) error {
+	return pinAMIs(true)
+}
+
+func pinAMIs(update bool) error {
+	amis, err := root.PinAMIs(configPath, update)
+	if err != nil {
+		return fmt.Errorf("failed to pin AMIs: %v", err)
+	}
+
+	if len(amis) == 0 {
+		logger.Info("No AMIs are looked up, as amiId is specified for all the nodes\n")
+		return nil
+	}
+	for _, a := range amis {
+		switch {
+		case a.Pinned == "":
+			logger.Infof("%s is pinned to %s\n", a.Query, a.Latest)
+		case a.Latest == "" || a.Latest == a.Pinned:
+			logger.Infof("%s is pinned to %s\n", a.Query, a.Pinned)
+		default:
+			logger.Infof("%s is updated from %s to %s\n", a.Query, a.Pinned, a.Latest)
+		}
+	}
+	return nil
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"bufio"
 	"os"
@@ -24,8 +25,11 @@ var (
 	applyOpts = struct {
 		awsDebug, prettyPrint, skipWait, export bool
 		force                                   bool
+		plan                                    string
 		profile                                 string
 		targets                                 []string
+		timeout                                 time.Duration
+		onInterrupt                             string
 	}{}
 )
 
@@ -36,39 +40,64 @@ func init() {
 	cmdApply.Flags().BoolVar(&applyOpts.prettyPrint, "pretty-print", false, "Pretty print the resulting CloudFormation")
 	cmdApply.Flags().BoolVar(&applyOpts.skipWait, "skip-wait", false, "Don't wait the resources finish")
 	cmdApply.Flags().BoolVar(&applyOpts.force, "force", false, "Don't ask for confirmation")
+	cmdApply.Flags().StringVar(&applyOpts.plan, "plan", "", "Execute the plan saved by `kube-aws plan --out` instead of updating the cluster to match cluster.yaml")
 	cmdApply.Flags().StringVar(&applyOpts.profile, "profile", "", "The AWS profile to use from credentials file")
 	cmdApply.Flags().StringSliceVar(&applyOpts.targets, "targets", root.AllOperationTargetsAsStringSlice(), "Update nothing but specified sub-stacks.  Specify `all` or any combination of `etcd`, `control-plane`, and node pool names. Defaults to `all`")
+	cmdApply.Flags().DurationVar(&applyOpts.timeout, "timeout", 0, "How long to wait for the cluster to get created or updated. 0 waits forever")
+	cmdApply.Flags().StringVar(&applyOpts.onInterrupt, "on-interrupt", onInterruptAsk, "What to do with the update in progress when waiting for it is interrupted by Ctrl-C or SIGTERM, or times out. Specify one of `ask`, `cancel` and `leave`")
 }
 
 func runCmdApply(_ *cobra.Command, _ []string) error {
+	confirmCancel, err := cancelConfirmation(applyOpts.onInterrupt)
+	if err != nil {
+		return err
+	}
+
 	if !applyOpts.force && !applyConfirmation() {
 		logger.Info("Operation cancelled")
 		return nil
 	}
 
 	opts := root.NewOptions(applyOpts.prettyPrint, applyOpts.skipWait, applyOpts.profile)
+	opts.Timeout = applyOpts.timeout
+	opts.ConfirmCancel = confirmCancel
 
 	cluster, err := root.LoadClusterFromFile(configPath, opts, applyOpts.awsDebug)
 	if err != nil {
 		return fmt.Errorf("failed to read cluster config: %v", err)
 	}
 
-	targets := root.OperationTargetsFromStringSlice(applyOpts.targets)
+	if applyOpts.plan != "" {
+		if applyOpts.export {
+			return fmt.Errorf("--export can not be used with --plan")
+		}
 
-	if _, err := cluster.ValidateStack(targets); err != nil {
-		return err
-	}
+		plan, err := root.ReadPlanFromFile(applyOpts.plan)
+		if err != nil {
+			return err
+		}
+
+		if err := cluster.ApplyPlan(plan); err != nil {
+			return fmt.Errorf("error applying plan: %v", err)
+		}
+	} else {
+		targets := root.OperationTargetsFromStringSlice(applyOpts.targets)
 
-	if applyOpts.export {
-		if err := cluster.Export(); err != nil {
+		if _, err := cluster.ValidateStack(targets); err != nil {
 			return err
 		}
-		return nil
-	}
 
-	err = cluster.Apply(targets)
-	if err != nil {
-		return fmt.Errorf("error updating cluster: %v", err)
+		if applyOpts.export {
+			if err := cluster.Export(); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		err = cluster.Apply(targets)
+		if err != nil {
+			return fmt.Errorf("error updating cluster: %v", err)
+		}
 	}
 
 	info, err := cluster.Info()
@@ -92,3 +121,30 @@ func applyConfirmation() bool {
 
 	return text == "y" || text == "yes"
 }
+
+const (
+	onInterruptAsk    = "ask"
+	onInterruptCancel = "cancel"
+	onInterruptLeave  = "leave"
+)
+
+// cancelConfirmation returns the function deciding whether to cancel the update in progress when waiting for it is
+// interrupted or times out, according to `--on-interrupt`
+func cancelConfirmation(onInterrupt string) (func(reason string) bool, error) {
+	switch onInterrupt {
+	case onInterruptAsk:
+		return func(reason string) bool {
+			reader := bufio.NewReader(os.Stdin)
+			fmt.Printf("%s\nCancel the update to roll the stack back, or leave the update running? [c,l]: ", reason)
+			text, _ := reader.ReadString('\n')
+			text = strings.TrimSuffix(strings.ToLower(text), "\n")
+
+			return text == "c" || text == "cancel"
+		}, nil
+	case onInterruptCancel:
+		return func(string) bool { return true }, nil
+	case onInterruptLeave:
+		return func(string) bool { return false }, nil
+	}
+	return nil, fmt.Errorf("unknown --on-interrupt value: %s. Specify one of %s, %s, %s", onInterrupt, onInterruptAsk, onInterruptCancel, onInterruptLeave)
+}
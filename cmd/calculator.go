@@ -2,28 +2,39 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
+	awspricing "github.com/aws/aws-sdk-go/service/pricing"
+	"github.com/kubernetes-incubator/kube-aws/awsconn"
 	"github.com/kubernetes-incubator/kube-aws/core/root"
 	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/pricing"
 	"github.com/spf13/cobra"
 )
 
-//TODO this is a first step to calculate the stack cost
-//this command could scrap aws to print the total cost, rather just showing the link
+// The AWS Price List API is served only from a few regions
+const pricingAPIRegion = "us-east-1"
 
 var (
 	cmdCalculator = &cobra.Command{
-		Use:          "calculator",
-		Short:        "Discover the monthly cost of your cluster",
-		Long:         ``,
+		Use:   "calculator",
+		Short: "Discover the monthly cost of your cluster",
+		Long: `Estimates the monthly cost of the controller nodes, etcd nodes, node pools, EBS volumes, NAT gateways and load balancers of your cluster
+from cluster.yaml and a pricing table, without calling AWS.`,
 		RunE:         runCmdCalculator,
 		SilenceUsage: true,
+		Annotations:  structuredOutput,
 	}
 
 	calculatorOpts = struct {
-		profile  string
-		awsDebug bool
+		profile        string
+		awsDebug       bool
+		pricingFile    string
+		refreshPricing bool
+		urls           bool
 	}{}
 )
 
@@ -31,10 +42,74 @@ func init() {
 	RootCmd.AddCommand(cmdCalculator)
 	cmdCalculator.Flags().StringVar(&calculatorOpts.profile, "profile", "", "The AWS profile to use from credentials file")
 	cmdCalculator.Flags().BoolVar(&calculatorOpts.awsDebug, "aws-debug", false, "Log debug information from aws-sdk-go library")
+	cmdCalculator.Flags().StringVar(&calculatorOpts.pricingFile, "pricing-file", "", "Path to the pricing table. Defaults to the one bundled with kube-aws")
+	cmdCalculator.Flags().BoolVar(&calculatorOpts.refreshPricing, "refresh-pricing", false, "Retrieve the current instance prices from the AWS Price List API and save them to --pricing-file before estimating")
+	cmdCalculator.Flags().BoolVar(&calculatorOpts.urls, "urls", false, "Print links to the AWS Simple Monthly Calculator instead. Requires AWS credentials")
 }
 
 func runCmdCalculator(_ *cobra.Command, _ []string) error {
+	if calculatorOpts.urls {
+		if outputFormat != outputTable {
+			return fmt.Errorf("--urls can't be used with --output %s", outputFormat)
+		}
+		return runCmdCalculatorURLs()
+	}
+
+	if calculatorOpts.refreshPricing && calculatorOpts.pricingFile == "" {
+		return fmt.Errorf("--refresh-pricing requires --pricing-file to save the refreshed prices to")
+	}
+
+	resources, err := root.BillableResourcesFromFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read cluster config: %v", err)
+	}
+
+	table, err := loadPricingTable(resources.InstanceTypes())
+	if err != nil {
+		return err
+	}
+
+	estimate, err := table.Estimate(*resources)
+	if err != nil {
+		return fmt.Errorf("failed to estimate cost: %v", err)
+	}
+
+	return printResult(estimate, func() {
+		logger.Heading("Estimated monthly cost of your cluster")
+		logger.Info(estimate.String())
+	})
+}
+
+func loadPricingTable(instanceTypes []string) (*pricing.Table, error) {
+	if !calculatorOpts.refreshPricing {
+		return pricing.LoadTable(calculatorOpts.pricingFile)
+	}
+
+	// Start from the bundled table when the pricing file is yet to be created
+	path := calculatorOpts.pricingFile
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		path = ""
+	}
+	table, err := pricing.LoadTable(path)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := awsconn.NewSessionFromRegion(api.RegionForName(pricingAPIRegion), calculatorOpts.awsDebug, calculatorOpts.profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to establish aws session: %v", err)
+	}
+	logger.Infof("Refreshing instance prices in %s ...\n", calculatorOpts.pricingFile)
+	if err := table.Refresh(awspricing.New(session), instanceTypes, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := table.WriteToFile(calculatorOpts.pricingFile); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
 
+func runCmdCalculatorURLs() error {
 	opts := root.NewOptions(false, false, calculatorOpts.profile)
 
 	cluster, err := root.LoadClusterFromFile(configPath, opts, calculatorOpts.awsDebug)
@@ -28,6 +28,7 @@ func init() {
 	cmdDestroy.Flags().StringVar(&destroyOpts.Profile, "profile", "", "The AWS profile to use from credentials file")
 	cmdDestroy.Flags().BoolVar(&destroyOpts.AwsDebug, "aws-debug", false, "Log debug information from aws-sdk-go library")
 	cmdDestroy.Flags().BoolVar(&destroyOpts.Force, "force", false, "Don't ask for confirmation")
+	cmdDestroy.Flags().StringSliceVar(&destroyOpts.Targets, "targets", root.AllOperationTargetsAsStringSlice(), "Destroy nothing but specified sub-stacks.  Specify `all` or any combination of `network`, `etcd`, `control-plane`, and node pool names. Defaults to `all`")
 }
 
 func runCmdDestroy(_ *cobra.Command, _ []string) error {
@@ -45,13 +46,20 @@ func runCmdDestroy(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("failed destroying cluster: %v", err)
 	}
 
-	logger.Info("CloudFormation stack is being destroyed. This will take several minutes")
+	if root.OperationTargetsFromStringSlice(destroyOpts.Targets).IsAll() {
+		logger.Info("CloudFormation stack is being destroyed. This will take several minutes")
+	}
 	return nil
 }
 
 func destroyConfirmation() bool {
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("This operation will destroy the cluster. Are you sure? [y,n]: ")
+	targets := root.OperationTargetsFromStringSlice(destroyOpts.Targets)
+	if targets.IsAll() {
+		fmt.Print("This operation will destroy the cluster. Are you sure? [y,n]: ")
+	} else {
+		fmt.Printf("This operation will destroy %s in the cluster. Are you sure? [y,n]: ", targets.String())
+	}
 	text, _ := reader.ReadString('\n')
 	text = strings.TrimSuffix(strings.ToLower(text), "\n")
 
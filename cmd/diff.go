@@ -17,10 +17,12 @@ var (
 		Long:         ``,
 		RunE:         runCmdDiff,
 		SilenceUsage: true,
+		Annotations:  structuredOutput,
 	}
 
 	diffOpts = struct {
 		awsDebug, prettyPrint, skipWait, export bool
+		detectDrift                             bool
 		context                                 int
 		profile                                 string
 		targets                                 []string
@@ -42,6 +44,7 @@ func init() {
 	cmdDiff.Flags().StringVar(&diffOpts.profile, "profile", "", "The AWS profile to use from credentials file")
 	cmdDiff.Flags().StringSliceVar(&diffOpts.targets, "targets", root.AllOperationTargetsAsStringSlice(), "Diff nothing but specified sub-stacks.  Specify `all` or any combination of `etcd`, `control-plane`, and node pool names. Defaults to `all`")
 	cmdDiff.Flags().IntVarP(&diffOpts.context, "context", "C", -1, "output NUM lines of context around changes")
+	cmdDiff.Flags().BoolVar(&diffOpts.detectDrift, "detect-drift", true, "Warn about resources modified outside kube-aws, which the next apply overwrites. Disable to skip CloudFormation drift detection, which may take minutes")
 }
 
 func runCmdDiff(c *cobra.Command, _ []string) error {
@@ -63,8 +66,20 @@ func runCmdDiff(c *cobra.Command, _ []string) error {
 		return fmt.Errorf("error comparing cluster states: %v", err)
 	}
 
-	for _, diff := range diffs {
-		logger.Infof("Detected changes in: %s\n%s", diff.Target, diff.String())
+	if diffs == nil {
+		diffs = []*root.DiffResult{}
+	}
+	err = printResult(diffs, func() {
+		for _, diff := range diffs {
+			logger.Infof("Detected changes in: %s\n%s", diff.Target, diff.String())
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if diffOpts.detectDrift {
+		warnDrift(cluster, targets)
 	}
 
 	names := make([]string, len(diffs))
@@ -79,3 +94,15 @@ func runCmdDiff(c *cobra.Command, _ []string) error {
 
 	return nil
 }
+
+// warnDrift warns about changes made outside kube-aws, without failing the diff when drift detection itself fails
+func warnDrift(cluster *root.Cluster, targets root.OperationTargets) {
+	report, err := cluster.DetectDrift(targets)
+	if err != nil {
+		logger.Warnf("failed to detect drift: %v\n", err)
+		return
+	}
+	if report.Drifted() {
+		logger.Warnf("Resources in %s have been changed outside kube-aws. `kube-aws apply` overwrites the changes:\n%s", strings.Join(report.DriftedStacks(), ", "), report.String())
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cmdDrift = &cobra.Command{
+		Use:          "drift",
+		Short:        "Detect changes made to the cluster outside kube-aws",
+		Long:         `Runs CloudFormation drift detection on the root stack and the nested stacks, and exits with 2 when any resource has been modified or deleted outside kube-aws.`,
+		RunE:         runCmdDrift,
+		SilenceUsage: true,
+		Annotations:  structuredOutput,
+	}
+
+	driftOpts = struct {
+		awsDebug bool
+		profile  string
+		targets  []string
+	}{}
+)
+
+func init() {
+	RootCmd.AddCommand(cmdDrift)
+	cmdDrift.Flags().BoolVar(&driftOpts.awsDebug, "aws-debug", false, "Log debug information from aws-sdk-go library")
+	cmdDrift.Flags().StringVar(&driftOpts.profile, "profile", "", "The AWS profile to use from credentials file")
+	cmdDrift.Flags().StringSliceVar(&driftOpts.targets, "targets", root.AllOperationTargetsAsStringSlice(), "Check nothing but specified sub-stacks.  Specify `all` or any combination of `network`, `etcd`, `control-plane`, and node pool names. Defaults to `all`")
+}
+
+func runCmdDrift(c *cobra.Command, _ []string) error {
+	opts := root.NewOptions(false, false, driftOpts.profile)
+
+	cluster, err := root.LoadClusterFromFile(configPath, opts, driftOpts.awsDebug)
+	if err != nil {
+		return fmt.Errorf("failed to read cluster config: %v", err)
+	}
+
+	report, err := cluster.DetectDrift(root.OperationTargetsFromStringSlice(driftOpts.targets))
+	if err != nil {
+		return fmt.Errorf("error detecting drift: %v", err)
+	}
+
+	err = printResult(report, func() {
+		logger.Info(report.String())
+	})
+	if err != nil {
+		return err
+	}
+
+	if report.Drifted() {
+		c.SilenceErrors = true
+		return &ExitError{fmt.Sprintf("Detected drift in: %s", strings.Join(report.DriftedStacks(), ", ")), 2}
+	}
+
+	return nil
+}
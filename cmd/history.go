@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/revision"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cmdHistory = &cobra.Command{
+		Use:          "history",
+		Short:        "List the revisions of your cluster archived by successful applies",
+		Long:         ``,
+		RunE:         runCmdHistory,
+		SilenceUsage: true,
+		Annotations:  structuredOutput,
+	}
+
+	historyOpts = struct {
+		awsDebug bool
+		profile  string
+		show     int
+	}{}
+)
+
+func init() {
+	RootCmd.AddCommand(cmdHistory)
+	cmdHistory.Flags().BoolVar(&historyOpts.awsDebug, "aws-debug", false, "Log debug information from aws-sdk-go library")
+	cmdHistory.Flags().StringVar(&historyOpts.profile, "profile", "", "The AWS profile to use from credentials file")
+	cmdHistory.Flags().IntVar(&historyOpts.show, "show", 0, "Print the cluster.yaml archived in the specified revision instead")
+}
+
+func runCmdHistory(_ *cobra.Command, _ []string) error {
+	opts := root.NewOptions(false, false, historyOpts.profile)
+	cluster, err := root.LoadClusterFromFile(configPath, opts, historyOpts.awsDebug)
+	if err != nil {
+		return fmt.Errorf("failed to read cluster config: %v", err)
+	}
+
+	if historyOpts.show > 0 {
+		content, err := cluster.RevisionFile(historyOpts.show, revision.ClusterConfigFile)
+		if err != nil {
+			return err
+		}
+		fmt.Print(content)
+		return nil
+	}
+
+	revisions, err := cluster.History()
+	if err != nil {
+		return fmt.Errorf("failed to list revisions: %v", err)
+	}
+
+	return printResult(revisions, func() {
+		printRevisions(revisions)
+	})
+}
+
+func printRevisions(revisions []*revision.Revision) {
+	if len(revisions) == 0 {
+		logger.Info("No revisions found. Revisions are archived on every successful `kube-aws apply`")
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	w := new(tabwriter.Writer)
+	w.Init(buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "REVISION\tCREATED\tKUBE-AWS\tKUBERNETES\tTARGETS\tDESCRIPTION\n")
+	for _, r := range revisions {
+		description := "apply"
+		if r.RollbackOf > 0 {
+			description = fmt.Sprintf("rollback to %d", r.RollbackOf)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", r.Number, r.CreatedAt.Format(time.RFC3339), r.KubeAWSVersion, r.KubernetesVersion, strings.Join(r.Targets, ","), description)
+	}
+	w.Flush()
+	logger.Info(buf.String())
+}
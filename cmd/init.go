@@ -3,6 +3,8 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/kubernetes-incubator/kube-aws/builtin"
 	"github.com/kubernetes-incubator/kube-aws/core/root/config"
@@ -22,6 +24,9 @@ var (
 	}
 
 	initOpts = config.InitialConfig{}
+
+	initNodePools   []string
+	initInteractive bool
 )
 
 const (
@@ -35,25 +40,52 @@ func init() {
 	cmdInit.Flags().StringVar(&initOpts.ExternalDNSName, "external-dns-name", "", "The hostname that will route to the api server")
 	cmdInit.Flags().StringVar(&initOpts.HostedZoneID, "hosted-zone-id", "", "The hosted zone in which a Route53 record set for a k8s API endpoint is created")
 	cmdInit.Flags().StringVar(&initOpts.Region.Name, "region", "", "The AWS region to deploy to")
-	cmdInit.Flags().StringVar(&initOpts.AvailabilityZone, "availability-zone", "", "The AWS availability-zone to deploy to")
+	cmdInit.Flags().StringSliceVar(&initOpts.AvailabilityZones, "availability-zone", nil, "The AWS availability-zone to deploy to. Specify 2 or more comma-separated zones for a multi-AZ cluster")
+	cmdInit.Flags().StringVar(&initOpts.Topology, "topology", config.TopologyPublic, "Either `public` to place nodes in public subnets, or `private` to place them in private subnets behind NAT gateways")
+	cmdInit.Flags().IntVar(&initOpts.ControllerCount, "controller-count", 1, "The number of controller nodes")
+	cmdInit.Flags().IntVar(&initOpts.EtcdCount, "etcd-count", 1, "The number of etcd nodes. Must be an odd number")
+	cmdInit.Flags().StringSliceVar(&initNodePools, "node-pools", []string{"nodepool1"}, "The worker node pools specified like `name` or `name:count`, comma-separated")
+	cmdInit.Flags().StringVar(&initOpts.VPCID, "vpc-id", "", "The ID of an existing VPC to deploy to. A new VPC is created if empty")
+	cmdInit.Flags().StringVar(&initOpts.VPCCIDR, "vpc-cidr", config.DefaultVPCCIDR, "The CIDR of the VPC, from which the CIDRs of subnets are allocated. Must match the existing VPC when --vpc-id is specified")
+	cmdInit.Flags().StringVar(&initOpts.InternetGatewayID, "internet-gateway-id", "", "The ID of the internet gateway attached to the existing VPC. Required with --vpc-id")
 	cmdInit.Flags().StringVar(&initOpts.KeyName, "key-name", "", "The AWS key-pair for ssh access to nodes")
 	cmdInit.Flags().StringVar(&initOpts.KMSKeyARN, "kms-key-arn", "", "The ARN of the AWS KMS key for encrypting TLS assets")
 	cmdInit.Flags().StringVar(&initOpts.AmiId, "ami-id", "", "The AMI ID of CoreOS. Last CoreOS Stable Channel selected by default if empty")
 	cmdInit.Flags().BoolVar(&initOpts.NoRecordSet, "no-record-set", false, "Instruct kube-aws to not manage Route53 record sets for your K8S API endpoints")
+	cmdInit.Flags().BoolVarP(&initInteractive, "interactive", "i", false, "Prompt for every setting, using the values of the other flags as defaults")
 }
 
 func runCmdInit(_ *cobra.Command, _ []string) error {
+	if initInteractive {
+		if err := newPrompter(os.Stdin, os.Stdout).promptInitialConfig(&initOpts, &initNodePools); err != nil {
+			return err
+		}
+	}
+
 	// Validate flags.
 	if err := validateRequired(
 		flag{"--s3-uri", initOpts.S3URI},
 		flag{"--cluster-name", initOpts.ClusterName},
 		flag{"--external-dns-name", initOpts.ExternalDNSName},
 		flag{"--region", initOpts.Region.Name},
-		flag{"--availability-zone", initOpts.AvailabilityZone},
+		flag{"--availability-zone", strings.Join(initOpts.AvailabilityZones, ",")},
 	); err != nil {
 		return err
 	}
 
+	initOpts.NodePools = nil
+	for _, s := range initNodePools {
+		np, err := config.ParseInitialNodePool(s)
+		if err != nil {
+			return err
+		}
+		initOpts.NodePools = append(initOpts.NodePools, np)
+	}
+
+	if err := initOpts.Prepare(); err != nil {
+		return fmt.Errorf("invalid settings: %v", err)
+	}
+
 	if initOpts.AmiId == "" {
 		amiID, err := amiregistry.GetAMI(initOpts.Region.Name, defaultReleaseChannel)
 		initOpts.AmiId = amiID
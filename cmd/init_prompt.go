@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+)
+
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// ask prints the question with the default answer and returns the answer, which is the default when empty.
+// A required question is asked again until it's answered
+func (p *prompter) ask(question, def string, required bool) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+		line, err := p.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", fmt.Errorf("failed to read answer to %q: %v", question, err)
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = def
+		}
+		if answer != "" || !required {
+			return answer, nil
+		}
+		fmt.Fprintln(p.out, "This setting is required.")
+	}
+}
+
+func (p *prompter) askInt(question string, def int) (int, error) {
+	for {
+		answer, err := p.ask(question, strconv.Itoa(def), true)
+		if err != nil {
+			return 0, err
+		}
+		n, err := strconv.Atoi(answer)
+		if err == nil {
+			return n, nil
+		}
+		fmt.Fprintf(p.out, "%q is not a number.\n", answer)
+	}
+}
+
+func (p *prompter) askList(question string, def []string, required bool) ([]string, error) {
+	answer, err := p.ask(question, strings.Join(def, ","), required)
+	if err != nil {
+		return nil, err
+	}
+	list := []string{}
+	for _, item := range strings.Split(answer, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list, nil
+}
+
+// promptInitialConfig asks for every setting of the initial cluster.yaml, using the values given by flags as defaults
+func (p *prompter) promptInitialConfig(c *config.InitialConfig, nodePools *[]string) error {
+	var err error
+	if c.ClusterName, err = p.ask("Cluster name", c.ClusterName, true); err != nil {
+		return err
+	}
+	if c.Region.Name, err = p.ask("AWS region", c.Region.Name, true); err != nil {
+		return err
+	}
+	if c.AvailabilityZones, err = p.askList("Availability zones, comma-separated", c.AvailabilityZones, true); err != nil {
+		return err
+	}
+	if c.Topology, err = p.ask("Topology (public or private)", c.Topology, true); err != nil {
+		return err
+	}
+	if c.ControllerCount, err = p.askInt("Number of controller nodes", c.ControllerCount); err != nil {
+		return err
+	}
+	if c.EtcdCount, err = p.askInt("Number of etcd nodes", c.EtcdCount); err != nil {
+		return err
+	}
+	if *nodePools, err = p.askList("Node pools like name or name:count, comma-separated", *nodePools, true); err != nil {
+		return err
+	}
+	if c.VPCID, err = p.ask("Existing VPC ID (empty to create a new VPC)", c.VPCID, false); err != nil {
+		return err
+	}
+	if c.VPCID != "" {
+		if c.InternetGatewayID, err = p.ask("Internet gateway ID attached to the VPC", c.InternetGatewayID, true); err != nil {
+			return err
+		}
+	}
+	if c.VPCCIDR, err = p.ask("VPC CIDR", c.VPCCIDR, true); err != nil {
+		return err
+	}
+	if c.ExternalDNSName, err = p.ask("DNS name of the Kubernetes API endpoint", c.ExternalDNSName, true); err != nil {
+		return err
+	}
+	if !c.NoRecordSet {
+		if c.HostedZoneID, err = p.ask("Route 53 hosted zone ID for the API endpoint (empty to manage the DNS record yourself)", c.HostedZoneID, false); err != nil {
+			return err
+		}
+		c.NoRecordSet = c.HostedZoneID == ""
+	}
+	if c.KeyName, err = p.ask("EC2 key pair name for SSH access", c.KeyName, false); err != nil {
+		return err
+	}
+	if c.KMSKeyARN, err = p.ask("KMS key ARN for encrypting credentials", c.KMSKeyARN, false); err != nil {
+		return err
+	}
+	if c.S3URI, err = p.ask("S3 URI to upload stack templates to e.g. s3://mybucket/mydir", c.S3URI, true); err != nil {
+		return err
+	}
+	if c.AmiId, err = p.ask(fmt.Sprintf("AMI ID (empty to use the latest Flatcar %s AMI)", defaultReleaseChannel), c.AmiId, false); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrompter(t *testing.T) {
+	testCases := []struct {
+		context  string
+		input    string
+		ask      func(p *prompter) (interface{}, error)
+		expected interface{}
+		output   string
+	}{
+		{
+			context: "Default",
+			input:   "\n",
+			ask: func(p *prompter) (interface{}, error) {
+				return p.ask("Cluster name", "mycluster", true)
+			},
+			expected: "mycluster",
+			output:   "Cluster name [mycluster]: ",
+		},
+		{
+			context: "RequiredAskedAgain",
+			input:   "\nmycluster\n",
+			ask: func(p *prompter) (interface{}, error) {
+				return p.ask("Cluster name", "", true)
+			},
+			expected: "mycluster",
+			output:   "Cluster name: This setting is required.\nCluster name: ",
+		},
+		{
+			context: "Optional",
+			input:   "\n",
+			ask: func(p *prompter) (interface{}, error) {
+				return p.ask("Key name", "", false)
+			},
+			expected: "",
+			output:   "Key name: ",
+		},
+		{
+			context: "IntAskedAgain",
+			input:   "three\n3\n",
+			ask: func(p *prompter) (interface{}, error) {
+				return p.askInt("Number of etcd nodes", 1)
+			},
+			expected: 3,
+			output:   "Number of etcd nodes [1]: \"three\" is not a number.\nNumber of etcd nodes [1]: ",
+		},
+		{
+			context: "List",
+			input:   " us-west-1a, ,us-west-1b \n",
+			ask: func(p *prompter) (interface{}, error) {
+				return p.askList("Availability zones", []string{"us-west-1a"}, true)
+			},
+			expected: []string{"us-west-1a", "us-west-1b"},
+			output:   "Availability zones [us-west-1a]: ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			answer, err := tc.ask(newPrompter(strings.NewReader(tc.input), out))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, answer)
+			assert.Equal(t, tc.output, out.String())
+		})
+	}
+}
+
+func TestPromptInitialConfig(t *testing.T) {
+	c := config.InitialConfig{
+		Region:          api.RegionForName("us-west-1"),
+		Topology:        config.TopologyPublic,
+		ControllerCount: 1,
+		EtcdCount:       1,
+		VPCCIDR:         config.DefaultVPCCIDR,
+	}
+	nodePools := []string{"nodepool1"}
+
+	answers := []string{
+		"mycluster",             // Cluster name
+		"",                      // AWS region
+		"us-west-1a,us-west-1b", // Availability zones
+		"private",               // Topology
+		"2",                     // Number of controller nodes
+		"3",                     // Number of etcd nodes
+		"pool1:2,pool2",         // Node pools
+		"vpc-12345678",          // Existing VPC ID
+		"igw-12345678",          // Internet gateway ID
+		"",                      // VPC CIDR
+		"mycluster.example.com", // DNS name
+		"",                      // Route 53 hosted zone ID
+		"mykey",                 // EC2 key pair name
+		"",                      // KMS key ARN
+		"s3://mybucket/mydir",   // S3 URI
+		"",                      // AMI ID
+	}
+	p := newPrompter(strings.NewReader(strings.Join(answers, "\n")+"\n"), new(bytes.Buffer))
+	require.NoError(t, p.promptInitialConfig(&c, &nodePools))
+
+	assert.Equal(t, "mycluster", c.ClusterName)
+	assert.Equal(t, "us-west-1", c.Region.Name)
+	assert.Equal(t, []string{"us-west-1a", "us-west-1b"}, c.AvailabilityZones)
+	assert.Equal(t, config.TopologyPrivate, c.Topology)
+	assert.Equal(t, 2, c.ControllerCount)
+	assert.Equal(t, 3, c.EtcdCount)
+	assert.Equal(t, []string{"pool1:2", "pool2"}, nodePools)
+	assert.Equal(t, "vpc-12345678", c.VPCID)
+	assert.Equal(t, "igw-12345678", c.InternetGatewayID)
+	assert.Equal(t, config.DefaultVPCCIDR, c.VPCCIDR)
+	assert.Equal(t, "mycluster.example.com", c.ExternalDNSName)
+	assert.True(t, c.NoRecordSet)
+	assert.Equal(t, "mykey", c.KeyName)
+	assert.Equal(t, "", c.KMSKeyARN)
+	assert.Equal(t, "s3://mybucket/mydir", c.S3URI)
+	assert.Equal(t, "", c.AmiId)
+}
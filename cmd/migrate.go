@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cmdMigrate = &cobra.Command{
+		Use:          "migrate",
+		Short:        "Rewrite deprecated keys in cluster.yaml",
+		Long:         `Rewrites the deprecated keys in cluster.yaml like vpcId with the keys replacing them, keeping comments and the order of keys.`,
+		RunE:         runCmdMigrate,
+		SilenceUsage: true,
+	}
+
+	migrateOpts = struct {
+		check bool
+	}{}
+)
+
+func init() {
+	RootCmd.AddCommand(cmdMigrate)
+	cmdMigrate.Flags().BoolVar(&migrateOpts.check, "check", false, "Don't rewrite cluster.yaml but exit with 2 when it contains deprecated keys")
+}
+
+func runCmdMigrate(c *cobra.Command, _ []string) error {
+	migrated, changes, err := config.MigrateFromFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to migrate %s: %v", configPath, err)
+	}
+
+	if len(changes) == 0 {
+		logger.Infof("%s contains no deprecated keys\n", configPath)
+		return nil
+	}
+
+	if migrateOpts.check {
+		for _, change := range changes {
+			logger.Infof("%s: %s\n", configPath, change)
+		}
+		c.SilenceErrors = true
+		return &ExitError{fmt.Sprintf("%s contains %d deprecated keys. Run `kube-aws migrate` to rewrite them", configPath, len(changes)), 2}
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(configPath, migrated, info.Mode()); err != nil {
+		return fmt.Errorf("failed to write %s: %v", configPath, err)
+	}
+	for _, change := range changes {
+		logger.Infof("%s: %s\n", configPath, change)
+	}
+	logger.Infof("Migrated %s\n", configPath)
+	return nil
+}
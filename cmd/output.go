@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// outputFormat is the format in which read-only commands print their results
+var outputFormat string
+
+// structuredOutputAnnotation marks the commands supporting `--output json` and `--output yaml`
+const structuredOutputAnnotation = "kube-aws.io/structured-output"
+
+// structuredOutput is the annotations of the commands supporting `--output json` and `--output yaml`
+var structuredOutput = map[string]string{structuredOutputAnnotation: "true"}
+
+// checkOutputFormat fails when `cmd` can't print its result in the format specified by --output
+func checkOutputFormat(cmd *cobra.Command) error {
+	switch outputFormat {
+	case "text":
+		// Accepted for compatibility with the per-command --output flags replaced by the global one
+		outputFormat = outputTable
+	case outputTable:
+	case outputJSON, outputYAML:
+		if _, ok := cmd.Annotations[structuredOutputAnnotation]; !ok {
+			return fmt.Errorf("%s doesn't support --output %s", cmd.CommandPath(), outputFormat)
+		}
+		// Keep stdout parsable
+		logger.Silent = true
+		logger.WarnToStdErr()
+	default:
+		return fmt.Errorf("unsupported output format: %s", outputFormat)
+	}
+	return nil
+}
+
+// printResult prints `result` as JSON or YAML as specified by --output, or calls `table` to print it for humans
+func printResult(result interface{}, table func()) error {
+	switch outputFormat {
+	case outputJSON:
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal result: %v", err)
+		}
+		fmt.Println(string(out))
+	case outputYAML:
+		out, err := yaml.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to marshal result: %v", err)
+		}
+		fmt.Print(string(out))
+	default:
+		table()
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cmdPlan = &cobra.Command{
+		Use:          "plan",
+		Short:        "Show the resources to be added, modified, replaced or removed by the next apply",
+		Long:         ``,
+		RunE:         runCmdPlan,
+		SilenceUsage: true,
+	}
+
+	planOpts = struct {
+		awsDebug, prettyPrint, skipWait bool
+		out                             string
+		profile                         string
+		targets                         []string
+	}{}
+)
+
+func init() {
+	RootCmd.AddCommand(cmdPlan)
+	cmdPlan.Flags().BoolVar(&planOpts.awsDebug, "aws-debug", false, "Log debug information from aws-sdk-go library")
+	cmdPlan.Flags().BoolVar(&planOpts.prettyPrint, "pretty-print", false, "Pretty print the resulting CloudFormation")
+	cmdPlan.Flags().StringVar(&planOpts.out, "out", "", "Save the plan to the file so that it can be executed by `kube-aws apply --plan`")
+	cmdPlan.Flags().StringVar(&planOpts.profile, "profile", "", "The AWS profile to use from credentials file")
+	cmdPlan.Flags().StringSliceVar(&planOpts.targets, "targets", root.AllOperationTargetsAsStringSlice(), "Plan nothing but specified sub-stacks.  Specify `all` or any combination of `etcd`, `control-plane`, and node pool names. Defaults to `all`")
+}
+
+func runCmdPlan(_ *cobra.Command, _ []string) error {
+	opts := root.NewOptions(planOpts.prettyPrint, planOpts.skipWait, planOpts.profile)
+
+	cluster, err := root.LoadClusterFromFile(configPath, opts, planOpts.awsDebug)
+	if err != nil {
+		return fmt.Errorf("failed to read cluster config: %v", err)
+	}
+
+	targets := root.OperationTargetsFromStringSlice(planOpts.targets)
+
+	plan, err := cluster.Plan(targets)
+	if err != nil {
+		return fmt.Errorf("error planning cluster changes: %v", err)
+	}
+
+	logger.Infof("\n%s", plan.String())
+
+	if replacements := plan.Replacements(); len(replacements) > 0 {
+		logger.Warnf("The following resources will be replaced: %s\n", strings.Join(replacements, ", "))
+	}
+
+	if planOpts.out != "" {
+		if err := plan.WriteToFile(planOpts.out); err != nil {
+			return err
+		}
+		logger.Infof("Saved the plan to %s. Run `kube-aws apply --plan %s` to execute it.\n", planOpts.out, planOpts.out)
+	}
+
+	return nil
+}
@@ -3,8 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/kubernetes-incubator/kube-aws/core/root"
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
 	"github.com/kubernetes-incubator/kube-aws/credential"
 	"github.com/kubernetes-incubator/kube-aws/logger"
 	"github.com/spf13/cobra"
@@ -29,6 +31,8 @@ var (
 
 	renderCredentialsOpts = credential.GeneratorOptions{}
 
+	renderMerged bool
+
 	cmdRenderStack = &cobra.Command{
 		Use:          "stack",
 		Short:        "Render CloudFormation stack template and coreos-cloudinit userdata",
@@ -36,6 +40,20 @@ var (
 		RunE:         runCmdRenderStack,
 		SilenceUsage: true,
 	}
+
+	cmdRenderUserData = &cobra.Command{
+		Use:          "userdata",
+		Short:        "Render the userdata of a controller, etcd or worker node",
+		Long:         `Print the instance part run on boot followed by the cloud-config it fetches from S3, with the contents of the files decompressed`,
+		RunE:         runCmdRenderUserData,
+		SilenceUsage: true,
+	}
+
+	renderUserDataOpts = struct {
+		role, pool, profile string
+		index               int
+		awsDebug, offline   bool
+	}{}
 )
 
 func init() {
@@ -43,6 +61,9 @@ func init() {
 
 	cmdRender.AddCommand(cmdRenderCredentials)
 	cmdRender.AddCommand(cmdRenderStack)
+	cmdRender.AddCommand(cmdRenderUserData)
+
+	cmdRender.Flags().BoolVar(&renderMerged, "merged", false, "Print cluster.yaml merged with the overlays specified by --env and --overlay instead of rendering anything")
 
 	cmdRenderCredentials.Flags().BoolVar(&renderCredentialsOpts.GenerateCA, "generate-ca", false, "if generating credentials, generate root CA key and cert. NOT RECOMMENDED FOR PRODUCTION USE- use '-ca-key-path' and '-ca-cert-path' options to provide your own certificate authority assets")
 	cmdRenderCredentials.Flags().StringVar(&renderCredentialsOpts.CaKeyPath, "ca-key-path", "./credentials/ca-key.pem", "path to pem-encoded CA RSA key")
@@ -59,6 +80,13 @@ func init() {
 	cmdRenderCredentials.Flags().StringVar(&renderCredentialsOpts.WorkerKeyPath, "worker-key-path", "", "path to pem-encoded worker RSA key")
 	cmdRenderCredentials.Flags().BoolVar(&renderCredentialsOpts.AwsDebug, "aws-debug", false, "Log debug information from aws-sdk-go library")
 
+	cmdRenderUserData.Flags().StringVar(&renderUserDataOpts.role, "role", "", fmt.Sprintf("The role of the node. One of %s", strings.Join(root.UserDataRoles, ", ")))
+	cmdRenderUserData.Flags().StringVar(&renderUserDataOpts.pool, "pool", "", "The node pool of the worker. Required only when there is more than one node pool")
+	cmdRenderUserData.Flags().IntVar(&renderUserDataOpts.index, "index", 0, "The index of the etcd node")
+	cmdRenderUserData.Flags().BoolVar(&renderUserDataOpts.offline, "offline", false, "Render without accessing AWS. The AMI IDs and credentials are replaced with placeholders")
+	cmdRenderUserData.Flags().StringVar(&renderUserDataOpts.profile, "profile", "", "The AWS profile to use from credentials file")
+	cmdRenderUserData.Flags().BoolVar(&renderUserDataOpts.awsDebug, "aws-debug", false, "Log debug information from aws-sdk-go library")
+
 }
 
 func runCmdRender(_ *cobra.Command, args []string) error {
@@ -66,6 +94,15 @@ func runCmdRender(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("render takes no arguments\n")
 	}
 
+	if renderMerged {
+		data, err := config.ReadConfigFile(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to read cluster config: %v", err)
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
 	if err := runCmdRenderStack(cmdRenderCredentials, args); err != nil {
 		return err
 	}
@@ -98,6 +135,37 @@ Next steps:
 	return nil
 }
 
+func runCmdRenderUserData(c *cobra.Command, _ []string) error {
+	if renderUserDataOpts.role == "" {
+		return fmt.Errorf("--role is required. Specify one of %s", strings.Join(root.UserDataRoles, ", "))
+	}
+	if c.Flags().Changed("pool") && renderUserDataOpts.role != "worker" {
+		return fmt.Errorf("--pool can be used only with --role worker")
+	}
+	if c.Flags().Changed("index") && renderUserDataOpts.role != "etcd" {
+		return fmt.Errorf("--index can be used only with --role etcd")
+	}
+
+	// Keep stdout for nothing but the userdata
+	logger.Silent = true
+	logger.WarnToStdErr()
+
+	opts := root.NewOptions(renderUserDataOpts.awsDebug, false, renderUserDataOpts.profile)
+	opts.Offline = renderUserDataOpts.offline
+
+	cluster, err := root.LoadClusterFromFile(configPath, opts, renderUserDataOpts.awsDebug)
+	if err != nil {
+		return fmt.Errorf("failed to initialize cluster driver: %v", err)
+	}
+
+	userdata, err := cluster.RenderUserData(renderUserDataOpts.role, renderUserDataOpts.pool, renderUserDataOpts.index)
+	if err != nil {
+		return fmt.Errorf("failed to render userdata: %v", err)
+	}
+	fmt.Print(userdata)
+	return nil
+}
+
 func runCmdRenderCredentials(_ *cobra.Command, _ []string) error {
 	if _, err := os.Stat(renderCredentialsOpts.CaKeyPath); os.IsNotExist(err) {
 		renderCredentialsOpts.GenerateCA = true
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cmdRollback = &cobra.Command{
+		Use:          "rollback",
+		Short:        "Roll back your cluster to a previous revision",
+		Long:         `Re-applies the stack templates archived in a previous revision listed by "kube-aws history".`,
+		RunE:         runCmdRollback,
+		SilenceUsage: true,
+	}
+
+	rollbackOpts = struct {
+		awsDebug bool
+		force    bool
+		profile  string
+		to       int
+	}{}
+)
+
+func init() {
+	RootCmd.AddCommand(cmdRollback)
+	cmdRollback.Flags().BoolVar(&rollbackOpts.awsDebug, "aws-debug", false, "Log debug information from aws-sdk-go library")
+	cmdRollback.Flags().BoolVar(&rollbackOpts.force, "force", false, "Don't ask for confirmation")
+	cmdRollback.Flags().StringVar(&rollbackOpts.profile, "profile", "", "The AWS profile to use from credentials file")
+	cmdRollback.Flags().IntVar(&rollbackOpts.to, "to", 0, "The number of the revision to roll back to")
+}
+
+func runCmdRollback(_ *cobra.Command, _ []string) error {
+	if rollbackOpts.to <= 0 {
+		return fmt.Errorf("specify the revision to roll back to with --to. Run `kube-aws history` to list revisions")
+	}
+
+	if !rollbackOpts.force && !rollbackConfirmation() {
+		logger.Info("Operation cancelled")
+		return nil
+	}
+
+	opts := root.NewOptions(false, false, rollbackOpts.profile)
+
+	cluster, err := root.LoadClusterFromFile(configPath, opts, rollbackOpts.awsDebug)
+	if err != nil {
+		return fmt.Errorf("failed to read cluster config: %v", err)
+	}
+
+	if err := cluster.Rollback(rollbackOpts.to); err != nil {
+		return fmt.Errorf("error rolling back cluster: %v", err)
+	}
+
+	logger.Infof("Rolled back to revision %d. Restore the cluster.yaml of the revision with `kube-aws history --show %d`, or the next `kube-aws apply` undoes the rollback\n", rollbackOpts.to, rollbackOpts.to)
+	return nil
+}
+
+func rollbackConfirmation() bool {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("This operation will roll back the cluster to revision %d. Are you sure? [y,n]: ", rollbackOpts.to)
+	text, _ := reader.ReadString('\n')
+	text = strings.TrimSuffix(strings.ToLower(text), "\n")
+
+	return text == "y" || text == "yes"
+}
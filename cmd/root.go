@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
 	"github.com/kubernetes-incubator/kube-aws/logger"
 	"github.com/mgutz/ansi"
 	"github.com/spf13/cobra"
@@ -11,18 +15,64 @@ var (
 		Use:   "kube-aws",
 		Short: "Manage Kubernetes clusters on AWS",
 		Long:  ``,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			colorEnabled, err := cmd.Flags().GetBool("color")
 			if err != nil {
 				panic(err)
 			}
 			ansi.DisableColors(!colorEnabled)
+			if err := setLogger(); err != nil {
+				return err
+			}
+			if err := checkOutputFormat(cmd); err != nil {
+				return err
+			}
+			return setConfigOverlays()
 		},
 	}
 
 	configPath = "cluster.yaml"
+
+	configEnv      string
+	configOverlays []string
+
+	logFormat string
+	logLevel  string
 )
 
+// setLogger makes the logger write records at --log-level or more severe in --log-format
+func setLogger() error {
+	level, err := logger.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	logger.MinLevel = level
+
+	switch logFormat {
+	case "text":
+	case "json":
+		// Keep stdout for the output of commands
+		logger.SetSink(logger.NewJSONSink(os.Stderr))
+	default:
+		return fmt.Errorf("unsupported log format: %s", logFormat)
+	}
+	return nil
+}
+
+// setConfigOverlays makes the overlay for --env and the ones specified with --overlay merged into cluster.yaml in that order
+func setConfigOverlays() error {
+	overlays := []string{}
+	if configEnv != "" {
+		path := config.EnvOverlayPath(configPath, configEnv)
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("overlay for environment %s not found: %v", configEnv, err)
+		}
+		overlays = append(overlays, path)
+	}
+	config.Overlays = append(overlays, configOverlays...)
+	return nil
+}
+
 func init() {
 	RootCmd.SetOutput(logger.Writer(logger.StdErrOutput))
 	RootCmd.PersistentFlags().BoolVarP(
@@ -46,4 +96,35 @@ func init() {
 		false,
 		"use color for messages",
 	)
+	RootCmd.PersistentFlags().StringVar(
+		&logFormat,
+		"log-format",
+		"text",
+		"write log messages in the format. Either `text` or `json`, which writes every message as a JSON object with fields such as cluster, stack and phase to stderr",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&logLevel,
+		"log-level",
+		"info",
+		"write log messages at the level or more severe. One of `debug`, `info`, `warn` and `error`",
+	)
+	RootCmd.PersistentFlags().StringVarP(
+		&outputFormat,
+		"output",
+		"o",
+		outputTable,
+		"print the results of read-only commands in the format. One of `table`, `json` and `yaml`",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&configEnv,
+		"env",
+		"",
+		"merge the overlay for the environment e.g. cluster.prod.yaml for prod into cluster.yaml",
+	)
+	RootCmd.PersistentFlags().StringSliceVar(
+		&configOverlays,
+		"overlay",
+		nil,
+		"merge the overlay file into cluster.yaml. Can be specified multiple times to merge overlays in order",
+	)
 }
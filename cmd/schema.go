@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/schema"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cmdSchema = &cobra.Command{
+		Use:          "schema [cluster|plugin]",
+		Short:        "Print the JSON Schema of cluster.yaml or plugin.yaml",
+		Long:         `Prints the JSON Schema of cluster.yaml, or plugin.yaml when "plugin" is given, for editors to validate and complete the files.`,
+		Args:         cobra.MaximumNArgs(1),
+		ValidArgs:    []string{"cluster", "plugin"},
+		RunE:         runCmdSchema,
+		SilenceUsage: true,
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(cmdSchema)
+}
+
+func runCmdSchema(_ *cobra.Command, args []string) error {
+	kind := "cluster"
+	if len(args) > 0 {
+		kind = args[0]
+	}
+
+	var s *schema.Schema
+	switch kind {
+	case "cluster":
+		s = api.ClusterSchema()
+	case "plugin":
+		s = api.PluginSchema()
+	default:
+		return fmt.Errorf("unknown schema: %s. Specify either `cluster` or `plugin`", kind)
+	}
+
+	out, err := s.JSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema: %v", err)
+	}
+	fmt.Println(out)
+	return nil
+}
@@ -23,6 +23,7 @@ var (
 Issuer, Validity, Subject and DNS Names fields`,
 		RunE:         runCmdShowCertificates,
 		SilenceUsage: true,
+		Annotations:  structuredOutput,
 	}
 )
 
@@ -37,16 +38,17 @@ func runCmdShowCertificates(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
-	keys := sortedKeys(certs)
-	for _, k := range keys {
-		cert := certs[k]
-		logger.Headingf("--- %s ---\n", k)
-		for _, v := range cert {
-			logger.Info(v)
+	return printResult(certs, func() {
+		keys := sortedKeys(certs)
+		for _, k := range keys {
+			cert := certs[k]
+			logger.Headingf("--- %s ---\n", k)
+			for _, v := range cert {
+				logger.Info(v)
+			}
+			logger.Info("")
 		}
-		logger.Info("")
-	}
-	return nil
+	})
 }
 
 func sortedKeys(m map[string]pki.Certificates) []string {
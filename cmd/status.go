@@ -15,6 +15,7 @@ var (
 		Long:         ``,
 		RunE:         runCmdStatus,
 		SilenceUsage: true,
+		Annotations:  structuredOutput,
 	}
 
 	statusOpts = struct {
@@ -39,6 +40,7 @@ func runCmdStatus(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("failed fetching cluster info: %v", err)
 	}
 
-	logger.Info(info)
-	return nil
+	return printResult(info, func() {
+		logger.Info(info)
+	})
 }
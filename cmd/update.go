@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"bufio"
 	"os"
@@ -26,6 +27,8 @@ var (
 		force                           bool
 		profile                         string
 		targets                         []string
+		timeout                         time.Duration
+		onInterrupt                     string
 	}{}
 )
 
@@ -37,18 +40,27 @@ func init() {
 	cmdUpdate.Flags().BoolVar(&updateOpts.force, "force", false, "Don't ask for confirmation")
 	cmdUpdate.Flags().StringVar(&updateOpts.profile, "profile", "", "The AWS profile to use from credentials file")
 	cmdUpdate.Flags().StringSliceVar(&updateOpts.targets, "targets", root.AllOperationTargetsAsStringSlice(), "Update nothing but specified sub-stacks.  Specify `all` or any combination of `etcd`, `control-plane`, and node pool names. Defaults to `all`")
+	cmdUpdate.Flags().DurationVar(&updateOpts.timeout, "timeout", 0, "How long to wait for the cluster to get updated. 0 waits forever")
+	cmdUpdate.Flags().StringVar(&updateOpts.onInterrupt, "on-interrupt", onInterruptAsk, "What to do with the update in progress when waiting for it is interrupted by Ctrl-C or SIGTERM, or times out. Specify one of `ask`, `cancel` and `leave`")
 }
 
 func runCmdUpdate(_ *cobra.Command, _ []string) error {
 	logger.Warnf("WARNING! kube-aws 'update' command is deprecated and will be removed in future versions")
 	logger.Warnf("Please use 'apply' to update your cluster")
 
+	confirmCancel, err := cancelConfirmation(updateOpts.onInterrupt)
+	if err != nil {
+		return err
+	}
+
 	if !updateOpts.force && !updateConfirmation() {
 		logger.Info("Operation cancelled")
 		return nil
 	}
 
 	opts := root.NewOptions(updateOpts.prettyPrint, updateOpts.skipWait, updateOpts.profile)
+	opts.Timeout = updateOpts.timeout
+	opts.ConfirmCancel = confirmCancel
 
 	cluster, err := root.LoadClusterFromFile(configPath, opts, updateOpts.awsDebug)
 	if err != nil {
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root"
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/upgrade"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cmdUpgrade = &cobra.Command{
+		Use:          "upgrade",
+		Short:        "Upgrade Kubernetes on your cluster phase by phase",
+		Long:         `Updates kubernetesVersion in cluster.yaml and updates etcd, the control plane and each node pool in this order, waiting for each phase to become healthy before starting the next one.`,
+		RunE:         runCmdUpgrade,
+		SilenceUsage: true,
+	}
+
+	upgradeOpts = struct {
+		awsDebug      bool
+		force         bool
+		healthTimeout time.Duration
+		pause         bool
+		profile       string
+		resume        bool
+		to            string
+	}{}
+)
+
+func init() {
+	RootCmd.AddCommand(cmdUpgrade)
+	cmdUpgrade.Flags().BoolVar(&upgradeOpts.awsDebug, "aws-debug", false, "Log debug information from aws-sdk-go library")
+	cmdUpgrade.Flags().BoolVar(&upgradeOpts.force, "force", false, "Don't ask for confirmation")
+	cmdUpgrade.Flags().DurationVar(&upgradeOpts.healthTimeout, "health-timeout", 15*time.Minute, "How long to wait for the stacks updated in a phase to become healthy")
+	cmdUpgrade.Flags().BoolVar(&upgradeOpts.pause, "pause", false, "Stop after each phase. Run `kube-aws upgrade --resume` to start the next phase")
+	cmdUpgrade.Flags().StringVar(&upgradeOpts.profile, "profile", "", "The AWS profile to use from credentials file")
+	cmdUpgrade.Flags().BoolVar(&upgradeOpts.resume, "resume", false, "Resume the paused or failed upgrade recorded in "+upgrade.StateFile)
+	cmdUpgrade.Flags().StringVar(&upgradeOpts.to, "to", "", "The Kubernetes version to upgrade to e.g. v1.14.1")
+}
+
+func runCmdUpgrade(_ *cobra.Command, _ []string) error {
+	state, err := upgrade.ReadState(upgrade.StateFile)
+	if err != nil {
+		return err
+	}
+
+	opts := root.NewOptions(false, false, upgradeOpts.profile)
+
+	if upgradeOpts.resume {
+		if state == nil {
+			return fmt.Errorf("no upgrade to resume: %s does not exist", upgrade.StateFile)
+		}
+		if upgradeOpts.to != "" && upgradeOpts.to != state.To {
+			return fmt.Errorf("the upgrade in progress is to %s, not %s", state.To, upgradeOpts.to)
+		}
+		logger.Infof("Resuming the upgrade of cluster %s from %s to %s\n", state.ClusterName, state.From, state.To)
+	} else {
+		if state != nil {
+			return fmt.Errorf("an upgrade from %s to %s is in progress. Run `kube-aws upgrade --resume` to continue it, or remove %s to abandon it", state.From, state.To, upgrade.StateFile)
+		}
+		if upgradeOpts.to == "" {
+			return fmt.Errorf("specify the Kubernetes version to upgrade to with --to")
+		}
+
+		cluster, err := root.LoadClusterFromFile(configPath, opts, upgradeOpts.awsDebug)
+		if err != nil {
+			return fmt.Errorf("failed to read cluster config: %v", err)
+		}
+
+		state, err = cluster.PlanUpgrade(upgradeOpts.to)
+		if err != nil {
+			return fmt.Errorf("unable to upgrade: %v", err)
+		}
+
+		if !upgradeOpts.force && !upgradeConfirmation(state) {
+			logger.Info("Operation cancelled")
+			return nil
+		}
+
+		// Update the overlay setting kubernetesVersion, if any, so that the new version takes effect in the merged cluster.yaml
+		path, err := config.FileDefiningKey(configPath, "kubernetesVersion")
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		if err := ioutil.WriteFile(path, upgrade.SetKubernetesVersion(data, state.To), 0644); err != nil {
+			return fmt.Errorf("failed to update %s: %v", path, err)
+		}
+		if err := state.WriteToFile(upgrade.StateFile); err != nil {
+			return err
+		}
+		logger.Infof("Updated kubernetesVersion in %s to %s\n", path, state.To)
+	}
+
+	// Reload cluster.yaml so that the new kubernetesVersion takes effect
+	cluster, err := root.LoadClusterFromFile(configPath, opts, upgradeOpts.awsDebug)
+	if err != nil {
+		return fmt.Errorf("failed to read cluster config: %v", err)
+	}
+
+	done, err := cluster.Upgrade(state, upgrade.StateFile, root.UpgradeOptions{
+		HealthTimeout: upgradeOpts.healthTimeout,
+		Pause:         upgradeOpts.pause,
+	})
+	if err != nil {
+		return fmt.Errorf("error upgrading cluster: %v. Fix the problem and run `kube-aws upgrade --resume` to retry", err)
+	}
+	if !done {
+		logger.Infof("Paused before upgrading %s. Run `kube-aws upgrade --resume` to continue\n", state.Next().Name)
+		return nil
+	}
+
+	if err := os.Remove(upgrade.StateFile); err != nil {
+		return fmt.Errorf("failed to remove %s: %v", upgrade.StateFile, err)
+	}
+	logger.Infof("Success! Cluster %s has been upgraded to Kubernetes %s\n", state.ClusterName, state.To)
+	return nil
+}
+
+func upgradeConfirmation(state *upgrade.State) bool {
+	names := []string{}
+	for _, p := range state.Phases {
+		names = append(names, p.Name)
+	}
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("This operation will upgrade Kubernetes from %s to %s by updating %s in this order. Are you sure? [y,n]: ", state.From, state.To, strings.Join(names, ", "))
+	text, _ := reader.ReadString('\n')
+	text = strings.TrimSuffix(strings.ToLower(text), "\n")
+
+	return text == "y" || text == "yes"
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/kubernetes-incubator/kube-aws/core/root"
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
 	"github.com/kubernetes-incubator/kube-aws/logger"
 	"github.com/spf13/cobra"
 )
@@ -15,12 +17,13 @@ var (
 		Long:         ``,
 		RunE:         runCmdValidate,
 		SilenceUsage: true,
+		Annotations:  structuredOutput,
 	}
 
 	validateOpts = struct {
-		awsDebug, skipWait bool
-		profile            string
-		targets            []string
+		awsDebug, skipWait, offline bool
+		profile                     string
+		targets                     []string
 	}{}
 )
 
@@ -32,6 +35,12 @@ func init() {
 		false,
 		"Log debug information from aws-sdk-go library",
 	)
+	cmdValidate.Flags().BoolVar(
+		&validateOpts.offline,
+		"offline",
+		false,
+		"Render and check all the stacks locally without accessing AWS. The AMI IDs and credentials are replaced with placeholders",
+	)
 	cmdValidate.Flags().StringVar(&validateOpts.profile, "profile", "", "The AWS profile to use from credentials file")
 	cmdValidate.Flags().StringSliceVar(
 		&validateOpts.targets,
@@ -40,8 +49,24 @@ func init() {
 		"Validate nothing but specified sub-stacks. Specify `all` or any combination of `etcd`, `control-plane`, and node pool names. Defaults to `all`")
 }
 
-func runCmdValidate(_ *cobra.Command, _ []string) error {
+func runCmdValidate(c *cobra.Command, _ []string) error {
+	if validateOpts.offline && c.Flags().Changed("targets") {
+		return fmt.Errorf("--targets can't be used with --offline, which validates all the stacks at once")
+	}
+
+	logger.Info("Validating cluster.yaml and plugins against their schemas...\n")
+
+	violations, err := config.ValidateSchemaFromFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to validate schema: %v", err)
+	}
+	if len(violations) > 0 {
+		result := &root.ValidationResult{Offline: validateOpts.offline, Errors: violations}
+		return validationFailed(result, fmt.Errorf("invalid configuration:\n  %s", strings.Join(violations, "\n  ")))
+	}
+
 	opts := root.NewOptions(validateOpts.awsDebug, validateOpts.skipWait, validateOpts.profile)
+	opts.Offline = validateOpts.offline
 
 	cluster, err := root.LoadClusterFromFile(configPath, opts, validateOpts.awsDebug)
 	if err != nil {
@@ -50,17 +75,37 @@ func runCmdValidate(_ *cobra.Command, _ []string) error {
 
 	logger.Info("Validating UserData and stack template...\n")
 
-	targets := root.OperationTargetsFromStringSlice(validateOpts.targets)
-
-	report, err := cluster.ValidateStack(targets)
-	if report != "" {
-		logger.Infof("Validation Report: %s\n", report)
+	var report string
+	if validateOpts.offline {
+		report, err = cluster.ValidateStackOffline()
+	} else {
+		targets := root.OperationTargetsFromStringSlice(validateOpts.targets)
+		report, err = cluster.ValidateStack(targets)
 	}
+	result := &root.ValidationResult{Offline: validateOpts.offline, Errors: []string{}, Report: report}
 	if err != nil {
-		return err
+		result.Errors = append(result.Errors, err.Error())
+		return validationFailed(result, err)
 	}
 
-	logger.Info("stack template is valid.\n\n")
-	logger.Info("Validation OK!")
-	return nil
+	result.Valid = true
+	return printResult(result, func() {
+		if report != "" {
+			logger.Infof("Validation Report: %s\n", report)
+		}
+		logger.Info("stack template is valid.\n\n")
+		logger.Info("Validation OK!")
+	})
+}
+
+// validationFailed prints the failed validation result and returns `err`
+func validationFailed(result *root.ValidationResult, err error) error {
+	if perr := printResult(result, func() {
+		if result.Report != "" {
+			logger.Infof("Validation Report: %s\n", result.Report)
+		}
+	}); perr != nil {
+		return perr
+	}
+	return err
 }
@@ -0,0 +1,113 @@
+package root
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-incubator/kube-aws/ami"
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/hostos"
+)
+
+// AMI is an AMI looked up for the nodes of the cluster
+type AMI struct {
+	ami.Query `yaml:",inline"`
+	// Pinned is the ID of the AMI pinned in the lockfile
+	Pinned string `json:"pinned,omitempty" yaml:"pinned,omitempty"`
+	// Latest is the ID of the latest AMI, which is empty when it isn't looked up
+	Latest string `json:"latest,omitempty" yaml:"latest,omitempty"`
+}
+
+// amiSources returns the sources of the latest AMIs keyed by the name of the host OS
+func amiSources() map[string]ami.Source {
+	sources := map[string]ami.Source{}
+	for _, name := range hostos.Names() {
+		os, _ := hostos.Get(name)
+		sources[name] = os.AMISource()
+	}
+	return sources
+}
+
+func lookupLatestAMI(sources map[string]ami.Source, q ami.Query) (string, error) {
+	source, ok := sources[q.OS]
+	if !ok {
+		return "", fmt.Errorf("failed to look up the latest AMI for %s: no AMI source for host OS %s", q, q.OS)
+	}
+	id, err := source.Lookup(q)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the latest AMI for %s: %v", q, err)
+	}
+	return id, nil
+}
+
+// ListAMIs returns the AMIs looked up for the nodes of the cluster with the ones pinned in the lockfile.
+// The latest AMIs are looked up unless `offline`
+func ListAMIs(configPath string, offline bool) ([]AMI, error) {
+	return listAMIs(configPath, offline, amiSources())
+}
+
+func listAMIs(configPath string, offline bool, sources map[string]ami.Source) ([]AMI, error) {
+	queries, err := config.AMIQueriesFromFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := ami.LoadLockfile(config.LockfilePath(configPath))
+	if err != nil {
+		return nil, err
+	}
+
+	amis := []AMI{}
+	for _, q := range queries {
+		a := AMI{Query: q}
+		a.Pinned, _ = lock.Find(q)
+		if !offline {
+			if a.Latest, err = lookupLatestAMI(sources, q); err != nil {
+				return nil, err
+			}
+		}
+		amis = append(amis, a)
+	}
+	return amis, nil
+}
+
+// PinAMIs pins the latest AMIs looked up for the nodes of the cluster to the lockfile, and returns them along with the ones pinned before.
+// Unless `update`, the AMIs pinned already are kept and their latest AMIs aren't looked up
+func PinAMIs(configPath string, update bool) ([]AMI, error) {
+	return pinAMIs(configPath, update, amiSources())
+}
+
+func pinAMIs(configPath string, update bool, sources map[string]ami.Source) ([]AMI, error) {
+	queries, err := config.AMIQueriesFromFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	lockPath := config.LockfilePath(configPath)
+	lock, err := ami.LoadLockfile(lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	amis := []AMI{}
+	changed := false
+	for _, q := range queries {
+		a := AMI{Query: q}
+		pinned, ok := lock.Find(q)
+		a.Pinned = pinned
+		if !ok || update {
+			if a.Latest, err = lookupLatestAMI(sources, q); err != nil {
+				return nil, err
+			}
+			if a.Latest != a.Pinned {
+				lock.Pin(q, a.Latest)
+				changed = true
+			}
+		}
+		amis = append(amis, a)
+	}
+
+	if changed {
+		if err := lock.Save(lockPath); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %v", lockPath, err)
+		}
+	}
+	return amis, nil
+}
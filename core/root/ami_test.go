@@ -0,0 +1,188 @@
+package root
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-aws/ami"
+	"github.com/kubernetes-incubator/kube-aws/flatcar/amiregistry"
+	"github.com/kubernetes-incubator/kube-aws/hostos"
+	"github.com/kubernetes-incubator/kube-aws/test/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const amiClusterYaml = `clusterName: mycluster
+keyName: mykey
+region: us-west-2
+availabilityZone: us-west-2a
+s3URI: s3://mybucket/mydir
+kmsKeyArn: "arn:aws:kms:us-west-2:xxxxxxxxx:key/xxxxxxxxxxxxxxxxxxx"
+apiEndpoints:
+- name: public
+  dnsName: mycluster.example.com
+  loadBalancer:
+    hostedZone:
+      id: hostedzone-xxxx
+worker:
+  nodePools:
+  - name: flatcar
+  - name: al2
+    hostOS:
+      name: amazon-linux-2
+  - name: beta
+    releaseChannel: beta
+`
+
+type fakeParameterStore map[string]string
+
+func (s fakeParameterStore) GetParameter(region, name string) (string, error) {
+	v, ok := s[region+":"+name]
+	if !ok {
+		return "", fmt.Errorf("ParameterNotFound")
+	}
+	return v, nil
+}
+
+// withAMISources serves `version` as the latest AMIs of every host OS in us-west-2, from an httptest Flatcar feed and a fake SSM parameter store
+func withAMISources(version string, fn func(sources map[string]ami.Source)) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The path is /<channel>/amd64
+		fmt.Fprintf(w, `{"amis":[{"name":"us-west-2","hvm":"ami-flatcar%s-%s"}]}`, strings.Replace(r.URL.Path, "/", "-", -1), version)
+	}))
+	defer server.Close()
+
+	al2, _ := hostos.Get(hostos.AmazonLinux2)
+	name := al2.AMISource().(ami.SSMParameters).Name
+
+	fn(map[string]ami.Source{
+		hostos.Flatcar: ami.FlatcarFeed{Feed: amiregistry.NewFeed(server.URL+"/%s/amd64", server.Client())},
+		hostos.AmazonLinux2: ami.SSMParameters{
+			Name:  name,
+			Store: fakeParameterStore{"us-west-2:" + name: "ami-al2-amd64-" + version},
+		},
+	})
+}
+
+func writeAMIClusterYaml(t *testing.T, dir, yaml string) string {
+	configPath := filepath.Join(dir, "cluster.yaml")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(yaml), 0644))
+	return configPath
+}
+
+var (
+	flatcarStable = ami.Query{OS: hostos.Flatcar, Region: "us-west-2", Channel: "stable"}
+	al2           = ami.Query{OS: hostos.AmazonLinux2, Region: "us-west-2"}
+	flatcarBeta   = ami.Query{OS: hostos.Flatcar, Region: "us-west-2", Channel: "beta"}
+	flatcarAlpha  = ami.Query{OS: hostos.Flatcar, Region: "us-west-2", Channel: "alpha"}
+)
+
+func TestPinAMIs(t *testing.T) {
+	helper.WithTempDir(func(dir string) {
+		configPath := writeAMIClusterYaml(t, dir, amiClusterYaml)
+		lockPath := filepath.Join(dir, ami.LockfileName)
+
+		withAMISources("v1", func(sources map[string]ami.Source) {
+			amis, err := pinAMIs(configPath, false, sources)
+			require.NoError(t, err)
+			assert.Equal(t, []AMI{
+				{Query: flatcarStable, Latest: "ami-flatcar-stable-amd64-v1"},
+				{Query: al2, Latest: "ami-al2-amd64-v1"},
+				{Query: flatcarBeta, Latest: "ami-flatcar-beta-amd64-v1"},
+			}, amis)
+		})
+
+		lock, err := ami.LoadLockfile(lockPath)
+		require.NoError(t, err)
+		assert.Equal(t, []ami.Pin{
+			{Query: al2, ID: "ami-al2-amd64-v1"},
+			{Query: flatcarBeta, ID: "ami-flatcar-beta-amd64-v1"},
+			{Query: flatcarStable, ID: "ami-flatcar-stable-amd64-v1"},
+		}, lock.AMIs)
+
+		t.Run("WithoutUpdate", func(t *testing.T) {
+			writeAMIClusterYaml(t, dir, amiClusterYaml+"  - name: alpha\n    releaseChannel: alpha\n")
+
+			withAMISources("v2", func(sources map[string]ami.Source) {
+				amis, err := pinAMIs(configPath, false, sources)
+				require.NoError(t, err)
+				assert.Equal(t, []AMI{
+					{Query: flatcarStable, Pinned: "ami-flatcar-stable-amd64-v1"},
+					{Query: al2, Pinned: "ami-al2-amd64-v1"},
+					{Query: flatcarBeta, Pinned: "ami-flatcar-beta-amd64-v1"},
+					{Query: flatcarAlpha, Latest: "ami-flatcar-alpha-amd64-v2"},
+				}, amis)
+			})
+
+			lock, err := ami.LoadLockfile(lockPath)
+			require.NoError(t, err)
+			assert.Equal(t, []ami.Pin{
+				{Query: al2, ID: "ami-al2-amd64-v1"},
+				{Query: flatcarAlpha, ID: "ami-flatcar-alpha-amd64-v2"},
+				{Query: flatcarBeta, ID: "ami-flatcar-beta-amd64-v1"},
+				{Query: flatcarStable, ID: "ami-flatcar-stable-amd64-v1"},
+			}, lock.AMIs)
+		})
+
+		t.Run("Update", func(t *testing.T) {
+			withAMISources("v3", func(sources map[string]ami.Source) {
+				amis, err := pinAMIs(configPath, true, sources)
+				require.NoError(t, err)
+				assert.Equal(t, []AMI{
+					{Query: flatcarStable, Pinned: "ami-flatcar-stable-amd64-v1", Latest: "ami-flatcar-stable-amd64-v3"},
+					{Query: al2, Pinned: "ami-al2-amd64-v1", Latest: "ami-al2-amd64-v3"},
+					{Query: flatcarBeta, Pinned: "ami-flatcar-beta-amd64-v1", Latest: "ami-flatcar-beta-amd64-v3"},
+					{Query: flatcarAlpha, Pinned: "ami-flatcar-alpha-amd64-v2", Latest: "ami-flatcar-alpha-amd64-v3"},
+				}, amis)
+			})
+
+			lock, err := ami.LoadLockfile(lockPath)
+			require.NoError(t, err)
+			assert.Equal(t, []ami.Pin{
+				{Query: al2, ID: "ami-al2-amd64-v3"},
+				{Query: flatcarAlpha, ID: "ami-flatcar-alpha-amd64-v3"},
+				{Query: flatcarBeta, ID: "ami-flatcar-beta-amd64-v3"},
+				{Query: flatcarStable, ID: "ami-flatcar-stable-amd64-v3"},
+			}, lock.AMIs)
+		})
+	})
+}
+
+func TestListAMIs(t *testing.T) {
+	helper.WithTempDir(func(dir string) {
+		configPath := writeAMIClusterYaml(t, dir, amiClusterYaml)
+		lock := &ami.Lockfile{}
+		lock.Pin(flatcarStable, "ami-pinned")
+		lockPath := filepath.Join(dir, ami.LockfileName)
+		require.NoError(t, lock.Save(lockPath))
+		saved, err := ioutil.ReadFile(lockPath)
+		require.NoError(t, err)
+
+		withAMISources("v1", func(sources map[string]ami.Source) {
+			amis, err := listAMIs(configPath, true, sources)
+			require.NoError(t, err)
+			assert.Equal(t, []AMI{
+				{Query: flatcarStable, Pinned: "ami-pinned"},
+				{Query: al2},
+				{Query: flatcarBeta},
+			}, amis)
+
+			amis, err = listAMIs(configPath, false, sources)
+			require.NoError(t, err)
+			assert.Equal(t, []AMI{
+				{Query: flatcarStable, Pinned: "ami-pinned", Latest: "ami-flatcar-stable-amd64-v1"},
+				{Query: al2, Latest: "ami-al2-amd64-v1"},
+				{Query: flatcarBeta, Latest: "ami-flatcar-beta-amd64-v1"},
+			}, amis)
+		})
+
+		listed, err := ioutil.ReadFile(lockPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(saved), string(listed), "listing AMIs must not change the lockfile")
+	})
+}
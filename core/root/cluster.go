@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,16 +16,19 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/kubernetes-incubator/kube-aws/awsconn"
+	"github.com/kubernetes-incubator/kube-aws/cfndiff"
 	"github.com/kubernetes-incubator/kube-aws/cfnstack"
 	"github.com/kubernetes-incubator/kube-aws/core/root/config"
 	"github.com/kubernetes-incubator/kube-aws/core/root/defaults"
 	"github.com/kubernetes-incubator/kube-aws/credential"
 	"github.com/kubernetes-incubator/kube-aws/filereader/jsontemplate"
+	"github.com/kubernetes-incubator/kube-aws/filereader/texttemplate"
 	"github.com/kubernetes-incubator/kube-aws/logger"
 	"github.com/kubernetes-incubator/kube-aws/naming"
 	"github.com/kubernetes-incubator/kube-aws/pkg/api"
 	"github.com/kubernetes-incubator/kube-aws/pkg/model"
 	"github.com/kubernetes-incubator/kube-aws/plugin/clusterextension"
+	"github.com/kubernetes-incubator/kube-aws/revision"
 	"github.com/tidwall/sjson"
 )
 
@@ -47,7 +51,7 @@ func (cl Cluster) Export() error {
 		if err := os.MkdirAll(dir, 0700); err != nil {
 			return fmt.Errorf("failed to create directory \"%s\": %v", dir, err)
 		}
-		if err := ioutil.WriteFile(path, []byte(asset.Content), 0600); err != nil {
+		if err := ioutil.WriteFile(path, []byte(cl.redactSecrets(path, asset.Content)), 0600); err != nil {
 			return fmt.Errorf("Error writing %s : %v", path, err)
 		}
 		if strings.HasSuffix(path, "stack.json") && cl.Cfg.KMSKeyARN == "" {
@@ -101,9 +105,19 @@ func (cl Cluster) EstimateCost() ([]string, error) {
 
 }
 
+// DiffResult is the changes to a stack detected by `kube-aws diff`
 type DiffResult struct {
-	Target string
-	diff   string
+	Target  string           `json:"target" yaml:"target"`
+	Changes []cfndiff.Change `json:"changes" yaml:"changes"`
+	diff    string
+}
+
+func newDiffResult(target string, changes []cfndiff.Change, context int) (*DiffResult, error) {
+	diff, err := sprintChanges(changes, context)
+	if err != nil {
+		return nil, err
+	}
+	return &DiffResult{Target: target, Changes: changes, diff: diff}, nil
 }
 
 func (r *DiffResult) String() string {
@@ -127,22 +141,45 @@ type Cluster struct {
 	Cfg      *config.Config
 	loaded   bool
 	awsDebug bool
+	// configPath is the path to cluster.yaml archived on every successful apply
+	configPath string
+	// revisionS3 archives and reads revisions in place of S3 accessed with the session, when set e.g. in tests
+	revisionS3 revision.S3Service
+	// renderHooksDone is true once the preRender and postRender hooks have run
+	renderHooksDone bool
 }
 
 func LoadClusterFromFile(configPath string, opts options, awsDebug bool) (*Cluster, error) {
-	cfg, err := config.ConfigFromFile(configPath)
+	var cfg *config.Config
+	var err error
+	texttemplate.SetOffline(opts.Offline)
+	if opts.Offline {
+		cfg, err = config.OfflineConfigFromFile(configPath)
+	} else {
+		cfg, err = config.ConfigFromFileWithSecretStore(configPath, secretStore(awsDebug, opts.AWSProfile))
+	}
 	if err != nil {
 		return nil, err
 	}
-	return CompileClusterFromConfig(cfg, opts, awsDebug)
+	cl, err := CompileClusterFromConfig(cfg, opts, awsDebug)
+	if err != nil {
+		return nil, err
+	}
+	cl.configPath = configPath
+	return cl, nil
 }
 
 func CompileClusterFromFile(configPath string, opts options, awsDebug bool) (*Cluster, error) {
-	cfg, err := config.ConfigFromFile(configPath)
+	cfg, err := config.ConfigFromFileWithSecretStore(configPath, secretStore(awsDebug, opts.AWSProfile))
 	if err != nil {
 		return nil, err
 	}
-	return CompileClusterFromConfig(cfg, opts, awsDebug)
+	cl, err := CompileClusterFromConfig(cfg, opts, awsDebug)
+	if err != nil {
+		return nil, err
+	}
+	cl.configPath = configPath
+	return cl, nil
 }
 
 func CompileClusterFromConfig(cfg *config.Config, opts options, awsDebug bool) (*Cluster, error) {
@@ -161,6 +198,7 @@ func (cl *Cluster) context() *model.Context {
 			Session:                cl.session,
 			ProvidedCFInterrogator: cfn,
 			StackTemplateGetter:    cfn,
+			Offline:                cl.opts.Offline,
 		}
 	}
 	return cl.Context
@@ -195,6 +233,10 @@ func (cl *Cluster) ensureNestedStacksLoaded() error {
 		return fmt.Errorf("failed initializing credentials: %v", err)
 	}
 
+	if err := cl.context().EncryptSecrets(cfg, opts.AssetsDir); err != nil {
+		return fmt.Errorf("failed to encrypt secrets: %v", err)
+	}
+
 	netOpts := stackTemplateOpts
 	netOpts.StackTemplateTmplFile = opts.NetworkStackTemplateTmplFile
 
@@ -385,8 +427,26 @@ func (cl *Cluster) Diff(opts OperationTargets, context int) ([]*DiffResult, erro
 	}
 
 	diffResults := []*DiffResult{}
+	appendResult := func(target string, changes []cfndiff.Change) error {
+		if len(changes) == 0 {
+			return nil
+		}
+		r, err := newDiffResult(target, changes, context)
+		if err != nil {
+			return err
+		}
+		diffResults = append(diffResults, r)
+		return nil
+	}
 
-	for id, setting := range mappings {
+	ids := []string{}
+	for id := range mappings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		setting := mappings[id]
 		currentStack, err := getStackTemplate(cfnSvc, setting.stackName)
 		if err != nil {
 			return nil, fmt.Errorf("failed to obtain %s stack template: %v", id, err)
@@ -396,14 +456,15 @@ func (cl *Cluster) Diff(opts OperationTargets, context int) ([]*DiffResult, erro
 			return nil, fmt.Errorf("failed to render %s stack template: %v", id, err)
 		}
 
-		stackDiffOutput, err := diffJson(currentStack, desiredStack, context)
+		stackChanges, err := cfndiff.Templates(id, currentStack, desiredStack)
 		if err != nil {
 			return nil, err
 		}
-		stackDiffSummary := &DiffResult{fmt.Sprintf("%s-stack", id), stackDiffOutput}
-		diffResults = append(diffResults, stackDiffSummary)
+		if err := appendResult(fmt.Sprintf("%s-stack", id), stackChanges); err != nil {
+			return nil, err
+		}
 
-		if len(stackDiffOutput) > 0 && setting.userdata != nil {
+		if len(stackChanges) > 0 && setting.userdata != nil {
 			currentInsScriptUserdata, err := getInstanceScriptUserdata(currentStack, setting.launchConfName)
 			if err != nil {
 				return nil, fmt.Errorf("failed to obtain %s instance userdata template: %v", id, err)
@@ -413,12 +474,10 @@ func (cl *Cluster) Diff(opts OperationTargets, context int) ([]*DiffResult, erro
 				return nil, fmt.Errorf("failed to render %s instance userdata template: %v", id, err)
 			}
 
-			insScriptUserdataDiffOutput, err := diffText(currentInsScriptUserdata, desiredInsScriptUserdata, context)
-			if err != nil {
+			insScriptChanges := cfndiff.Text(id, "userdata-instance-script", currentInsScriptUserdata, desiredInsScriptUserdata)
+			if err := appendResult(fmt.Sprintf("%s-userdata-instance-script", id), insScriptChanges); err != nil {
 				return nil, err
 			}
-			insScriptUserdataDiffSummary := &DiffResult{fmt.Sprintf("%s-userdata-instance-script", id), insScriptUserdataDiffOutput}
-			diffResults = append(diffResults, insScriptUserdataDiffSummary)
 
 			currentInsUserdata, err := getInstanceUserdataJson(currentStack, setting.launchConfName)
 			if err != nil {
@@ -429,12 +488,13 @@ func (cl *Cluster) Diff(opts OperationTargets, context int) ([]*DiffResult, erro
 				return nil, fmt.Errorf("failed to render %s instance userdata template: %v", id, err)
 			}
 
-			insUserdataDiffOutput, err := diffJson(currentInsUserdata, desiredInsUserdata, context)
+			insChanges, err := cfndiff.JSON(id, "userdata-instance", currentInsUserdata, desiredInsUserdata)
 			if err != nil {
 				return nil, err
 			}
-			insUserdataDiffSummary := &DiffResult{fmt.Sprintf("%s-userdata-instance", id), insUserdataDiffOutput}
-			diffResults = append(diffResults, insUserdataDiffSummary)
+			if err := appendResult(fmt.Sprintf("%s-userdata-instance", id), insChanges); err != nil {
+				return nil, err
+			}
 
 			{
 				currentS3Userdata, err := getS3Userdata(s3Svc, currentInsUserdata)
@@ -446,12 +506,22 @@ func (cl *Cluster) Diff(opts OperationTargets, context int) ([]*DiffResult, erro
 					return nil, fmt.Errorf("failed to render %s s3 userdata template: %v", id, err)
 				}
 
-				s3UserdataDiffOutput, err := diffText(currentS3Userdata, desiredS3Userdata, context)
+				var s3Changes []cfndiff.Change
+				switch {
+				case setting.userdata.Format == api.USERDATA_FORMAT_CLOUD_CONFIG && strings.HasPrefix(currentS3Userdata, "#cloud-config"):
+					s3Changes, err = cfndiff.CloudConfigs(id, "userdata-s3", currentS3Userdata, desiredS3Userdata)
+				case setting.userdata.Format == api.USERDATA_FORMAT_IGNITION && strings.HasPrefix(currentS3Userdata, "{"):
+					s3Changes, err = cfndiff.JSON(id, "userdata-s3", currentS3Userdata, desiredS3Userdata)
+				default:
+					// Either the userdata is a shell script, or the node pool is being migrated to another userdata format
+					s3Changes = cfndiff.Text(id, "userdata-s3", currentS3Userdata, desiredS3Userdata)
+				}
 				if err != nil {
 					return nil, err
 				}
-				s3UserdataDiffSummary := &DiffResult{fmt.Sprintf("%s-userdata-s3", id), s3UserdataDiffOutput}
-				diffResults = append(diffResults, s3UserdataDiffSummary)
+				if err := appendResult(fmt.Sprintf("%s-userdata-s3", id), s3Changes); err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
@@ -471,8 +541,15 @@ func (cl *Cluster) create(cfSvc *cloudformation.CloudFormation) error {
 	}
 
 	assets, err := cl.EnsureAllAssetsGenerated()
+	if err != nil {
+		return err
+	}
 
-	err = cl.uploadAssets(assets)
+	return cl.createFromAssets(cfSvc, assets)
+}
+
+func (cl *Cluster) createFromAssets(cfSvc *cloudformation.CloudFormation, assets cfnstack.Assets) error {
+	err := cl.uploadAssets(assets)
 	if err != nil {
 		return err
 	}
@@ -482,20 +559,15 @@ func (cl *Cluster) create(cfSvc *cloudformation.CloudFormation) error {
 		return err
 	}
 
-	logger.Infof("Creating cluster %s with Kubernetes %s and etcd %s ...", cl.Cfg.ClusterName, cl.Cfg.K8sVer, cl.Cfg.Etcd.Version())
+	logger.With(logger.Fields{"cluster": cl.Cfg.ClusterName, "phase": "create"}).Infof("Creating cluster %s with Kubernetes %s and etcd %s ...", cl.Cfg.ClusterName, cl.Cfg.K8sVer, cl.Cfg.Etcd.Version())
 
-	q := make(chan struct{}, 1)
+	q := cl.streamLogsAndEvents(cfSvc, "create")
 	defer func() { q <- struct{}{} }()
 
-	if cl.controlPlaneStack.Config.CloudWatchLogging.Enabled && cl.controlPlaneStack.Config.CloudWatchLogging.LocalStreaming.Enabled {
-		go streamJournaldLogs(cl, q)
-	}
-
-	if cl.controlPlaneStack.Config.CloudFormationStreaming {
-		go streamStackEvents(cl, cfSvc, q)
+	if err := cl.stackProvisioner().CreateStackAtURLAndWait(cfSvc, stackTemplateURL); err != nil {
+		return err
 	}
-
-	return cl.stackProvisioner().CreateStackAtURLAndWait(cfSvc, stackTemplateURL)
+	return cl.protectStacks(cfSvc)
 }
 
 func (cl *Cluster) Info() (*Info, error) {
@@ -659,25 +731,15 @@ func (cl *Cluster) renderTemplateAsString() (string, error) {
 }
 
 func (cl *Cluster) stackProvisioner() *cfnstack.Provisioner {
-	stackPolicyBody := `{
-  "Statement" : [
-    {
-       "Effect" : "Allow",
-       "Principal" : "*",
-       "Action" : "Update:*",
-       "Resource" : "*"
-     }
-  ]
-}`
 	return cfnstack.NewProvisioner(
 		cl.stackName(),
 		cl.tags(),
 		cl.s3URI(),
 		cl.controlPlaneStack.Region,
-		stackPolicyBody,
+		cl.rootStackPolicyBody(),
 		cl.session,
 		cl.controlPlaneStack.Config.CloudFormation.RoleARN,
-	)
+	).WithWaitOptions(cfnstack.WaitOptions{Timeout: cl.opts.Timeout, ConfirmCancel: cl.opts.ConfirmCancel})
 }
 
 func (cl Cluster) stackName() string {
@@ -693,26 +755,71 @@ func (cl Cluster) tags() map[string]string {
 	return cptags
 }
 
-func (cl *Cluster) Apply(targets OperationTargets) error {
+func (cl *Cluster) Apply(targets OperationTargets) (err error) {
+	if err := cl.ensureNestedStacksLoaded(); err != nil {
+		return err
+	}
+
+	ctx := cl.hookContext("apply", cl.operationTargetsFromUserInput([]OperationTargets{targets}))
+	defer func() {
+		if err != nil {
+			cl.runFailureHooks(ctx, err)
+		}
+	}()
+
 	cfSvc := cloudformation.New(cl.session)
 
+	exists, err := cl.rootStackExists()
+	if err != nil {
+		return err
+	}
+
+	assets, err := cl.renderAssetsWithHooks(ctx, func() (cfnstack.Assets, error) {
+		if exists {
+			return cl.generateAssets(cl.operationTargetsFromUserInput([]OperationTargets{targets}))
+		}
+		return cl.EnsureAllAssetsGenerated()
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := cl.runPreApplyHooks(ctx, targets, exists); err != nil {
+		return err
+	}
+
+	if err := cl.applyAssets(cfSvc, assets, exists); err != nil {
+		return err
+	}
+
+	cl.archiveRevision(revisionFilesFromAssets(assets), targets)
+	cl.runPostApplyHooks(ctx, cfSvc)
+	return nil
+}
+
+func (cl *Cluster) rootStackExists() (bool, error) {
 	exists, err := cfnstack.StackExists(cl.context().ProvidedCFInterrogator, cl.controlPlaneStack.ClusterName)
 	if err != nil {
 		logger.Errorf("please check your AWS credentials/permissions")
-		return fmt.Errorf("can't lookup AWS CloudFormation stacks: %s", err)
+		return false, fmt.Errorf("can't lookup AWS CloudFormation stacks: %s", err)
 	}
+	return exists, nil
+}
 
-	if exists {
-		report, err := cl.update(cfSvc, targets)
-		if err != nil {
-			return fmt.Errorf("error updating cluster: %v", err)
-		}
-		if report != "" {
-			logger.Infof("Update stack: %s\n", report)
-		}
-		return nil
+// applyAssets uploads the assets and then updates the cluster with them, or creates it when it doesn't exist yet
+func (cl *Cluster) applyAssets(cfSvc *cloudformation.CloudFormation, assets cfnstack.Assets, exists bool) error {
+	if !exists {
+		return cl.createFromAssets(cfSvc, assets)
 	}
-	return cl.create(cfSvc)
+
+	report, err := cl.updateFromAssets(cfSvc, assets)
+	if err != nil {
+		return fmt.Errorf("error updating cluster: %v", err)
+	}
+	if report != "" {
+		logger.Infof("Update stack: %s\n", report)
+	}
+	return nil
 }
 
 // remove with legacy up command
@@ -728,7 +835,11 @@ func (cl *Cluster) update(cfSvc *cloudformation.CloudFormation, targets Operatio
 		return "", err
 	}
 
-	err = cl.uploadAssets(assets)
+	return cl.updateFromAssets(cfSvc, assets)
+}
+
+func (cl *Cluster) updateFromAssets(cfSvc *cloudformation.CloudFormation, assets cfnstack.Assets) (string, error) {
+	err := cl.uploadAssets(assets)
 	if err != nil {
 		return "", err
 	}
@@ -738,20 +849,16 @@ func (cl *Cluster) update(cfSvc *cloudformation.CloudFormation, targets Operatio
 		return "", err
 	}
 
-	logger.Infof("Updating cluster %s with Kubernetes %s and etcd %s ...", cl.Cfg.ClusterName, cl.Cfg.K8sVer, cl.Cfg.Etcd.Version())
+	logger.With(logger.Fields{"cluster": cl.Cfg.ClusterName, "phase": "update"}).Infof("Updating cluster %s with Kubernetes %s and etcd %s ...", cl.Cfg.ClusterName, cl.Cfg.K8sVer, cl.Cfg.Etcd.Version())
 
-	q := make(chan struct{}, 1)
+	q := cl.streamLogsAndEvents(cfSvc, "update")
 	defer func() { q <- struct{}{} }()
 
-	if cl.controlPlaneStack.Config.CloudWatchLogging.Enabled && cl.controlPlaneStack.Config.CloudWatchLogging.LocalStreaming.Enabled {
-		go streamJournaldLogs(cl, q)
-	}
-
-	if cl.controlPlaneStack.Config.CloudFormationStreaming {
-		go streamStackEvents(cl, cfSvc, q)
+	report, err := cl.stackProvisioner().UpdateStackAtURLAndWait(cfSvc, templateUrl)
+	if err != nil {
+		return "", err
 	}
-
-	return cl.stackProvisioner().UpdateStackAtURLAndWait(cfSvc, templateUrl)
+	return report, cl.protectStacks(cfSvc)
 }
 
 func (cl *Cluster) ValidateTemplates() error {
@@ -777,7 +884,7 @@ func (cl *Cluster) ValidateTemplates() error {
 }
 
 // ValidateStack validates all the CloudFormation stack templates already uploaded to S3
-func (cl *Cluster) ValidateStack(opts ...OperationTargets) (string, error) {
+func (cl *Cluster) ValidateStack(opts ...OperationTargets) (_ string, err error) {
 	if err := cl.ensureNestedStacksLoaded(); err != nil {
 		return "", err
 	}
@@ -786,7 +893,16 @@ func (cl *Cluster) ValidateStack(opts ...OperationTargets) (string, error) {
 
 	targets := cl.operationTargetsFromUserInput(opts)
 
-	assets, err := cl.generateAssets(cl.operationTargetsFromUserInput([]OperationTargets{targets}))
+	hookCtx := cl.hookContext("validate", targets)
+	defer func() {
+		if err != nil {
+			cl.runFailureHooks(hookCtx, err)
+		}
+	}()
+
+	assets, err := cl.renderAssetsWithHooks(hookCtx, func() (cfnstack.Assets, error) {
+		return cl.generateAssets(cl.operationTargetsFromUserInput([]OperationTargets{targets}))
+	})
 	if err != nil {
 		return "", err
 	}
@@ -840,8 +956,25 @@ func (cl *Cluster) ValidateStack(opts ...OperationTargets) (string, error) {
 	return strings.Join(reports, "\n"), nil
 }
 
-func streamJournaldLogs(c *Cluster, q chan struct{}) error {
-	logger.Infof("Streaming filtered Journald logs for log group '%s'...\nNOTE: Due to high initial entropy, '.service' failures may occur during the early stages of booting.\n", c.controlPlaneStack.ClusterName)
+// streamLogsAndEvents starts streaming journald logs and stack events when enabled in cluster.yaml, logging them with `phase` e.g. `create`.
+// Send to the returned channel to stop streaming.
+func (cl *Cluster) streamLogsAndEvents(cfSvc *cloudformation.CloudFormation, phase string) chan struct{} {
+	q := make(chan struct{}, 1)
+
+	if cl.controlPlaneStack.Config.CloudWatchLogging.Enabled && cl.controlPlaneStack.Config.CloudWatchLogging.LocalStreaming.Enabled {
+		go streamJournaldLogs(cl, phase, q)
+	}
+
+	if cl.controlPlaneStack.Config.CloudFormationStreaming {
+		go streamStackEvents(cl, cfSvc, phase, q)
+	}
+
+	return q
+}
+
+func streamJournaldLogs(c *Cluster, phase string, q chan struct{}) error {
+	log := logger.With(logger.Fields{"cluster": c.controlPlaneStack.ClusterName, "phase": phase})
+	log.Infof("Streaming filtered Journald logs for log group '%s'...\nNOTE: Due to high initial entropy, '.service' failures may occur during the early stages of booting.\n", c.controlPlaneStack.ClusterName)
 	cwlSvc := cloudwatchlogs.New(c.session)
 	s := time.Now().Unix() * 1e3
 	t := s
@@ -869,7 +1002,7 @@ func streamJournaldLogs(c *Cluster, q chan struct{}) error {
 						json.Unmarshal([]byte(*event.Message), &res)
 						s := int(((*event.Timestamp) - t) / 1e3)
 						d := fmt.Sprintf("+%.2d:%.2d:%.2d", s/3600, (s/60)%60, s%60)
-						logger.Infof("%s\t%s: \"%s\"\n", d, res.Hostname, res.Message)
+						log.With(logger.Fields{"host": res.Hostname, "instanceId": res.InstanceId, "unit": res.SystemdUnit, "priority": res.Priority, "elapsed": d}).Infof("%s\t%s: \"%s\"\n", d, res.Hostname, res.Message)
 					}
 				}
 			}
@@ -883,7 +1016,7 @@ func streamJournaldLogs(c *Cluster, q chan struct{}) error {
 }
 
 // streamStackEvents streams all the events from the root, the control-plane, and worker node pool stacks using StreamEventsNested
-func streamStackEvents(c *Cluster, cfSvc *cloudformation.CloudFormation, q chan struct{}) error {
-	logger.Infof("Streaming CloudFormation events for the cluster '%s'...\n", c.controlPlaneStack.ClusterName)
-	return c.stackProvisioner().StreamEventsNested(q, cfSvc, c.controlPlaneStack.ClusterName, c.controlPlaneStack.ClusterName, time.Now())
+func streamStackEvents(c *Cluster, cfSvc *cloudformation.CloudFormation, phase string, q chan struct{}) error {
+	logger.With(logger.Fields{"cluster": c.controlPlaneStack.ClusterName, "phase": phase}).Infof("Streaming CloudFormation events for the cluster '%s'...\n", c.controlPlaneStack.ClusterName)
+	return c.stackProvisioner().StreamEventsNested(q, cfSvc, c.controlPlaneStack.ClusterName, c.controlPlaneStack.ClusterName, phase, time.Now())
 }
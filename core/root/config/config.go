@@ -3,26 +3,46 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/go-yaml/yaml"
+	"github.com/kubernetes-incubator/kube-aws/ami"
+	"github.com/kubernetes-incubator/kube-aws/awsconn"
 	"github.com/kubernetes-incubator/kube-aws/pkg/api"
 	"github.com/kubernetes-incubator/kube-aws/pkg/model"
 	"github.com/kubernetes-incubator/kube-aws/plugin"
 	"github.com/kubernetes-incubator/kube-aws/plugin/clusterextension"
+	"github.com/kubernetes-incubator/kube-aws/schema"
+	"github.com/kubernetes-incubator/kube-aws/secret"
 	"github.com/pkg/errors"
 )
 
 type InitialConfig struct {
-	AmiId            string
-	AvailabilityZone string
-	ClusterName      string
-	ExternalDNSName  string
-	HostedZoneID     string
-	KMSKeyARN        string
-	KeyName          string
-	NoRecordSet      bool
-	Region           api.Region
-	S3URI            string
+	AmiId             string
+	AvailabilityZone  string
+	AvailabilityZones []string
+	ClusterName       string
+	ControllerCount   int
+	EtcdCount         int
+	ExternalDNSName   string
+	HostedZoneID      string
+	// InstanceCIDR is populated by Prepare for the single subnet when subnets aren't generated
+	InstanceCIDR      string
+	InternetGatewayID string
+	KMSKeyARN         string
+	KeyName           string
+	NoRecordSet       bool
+	NodePools         []InitialNodePool
+	Region            api.Region
+	S3URI             string
+	// Subnets are populated by Prepare from the availability zones and the topology
+	Subnets  []InitialSubnet
+	Topology string
+	VPCCIDR  string
+	VPCID    string
 }
 
 type UnmarshalledConfig struct {
@@ -37,6 +57,9 @@ type Config struct {
 	api.UnknownKeys `yaml:",inline"`
 
 	Extras *clusterextension.ClusterExtension
+
+	// Secrets are the values in cluster.yaml resolved from the references to secrets
+	Secrets []secret.Resolved
 }
 
 type unknownKeysSupport interface {
@@ -64,7 +87,15 @@ func unmarshalConfig(data []byte) (*UnmarshalledConfig, error) {
 	return c, nil
 }
 
+// OfflineAMI is the placeholder used in place of the AMI IDs otherwise looked up from the release channels when config is loaded offline
+const OfflineAMI = "ami-offline"
+
+// ConfigFromBytes loads the cluster.yaml in `data`, which isn't read from a file and therefore has no lockfile pinning AMIs
 func ConfigFromBytes(data []byte, plugins []*api.Plugin) (*Config, error) {
+	return configFromBytes(data, plugins, false, &ami.Lockfile{})
+}
+
+func configFromBytes(data []byte, plugins []*api.Plugin, offline bool, lock *ami.Lockfile) (*Config, error) {
 	c, err := unmarshalConfig(data)
 	if err != nil {
 		return nil, err
@@ -75,6 +106,23 @@ func ConfigFromBytes(data []byte, plugins []*api.Plugin) (*Config, error) {
 		return nil, err
 	}
 
+	for _, l := range amiLookups(c) {
+		if id, ok := lock.Find(l.query); ok {
+			l.pin(id)
+		}
+	}
+
+	if offline {
+		if c.AmiId == "" {
+			c.AmiId = OfflineAMI
+		}
+		for i := range c.NodePools {
+			if c.NodePools[i].AmiId == "" {
+				c.NodePools[i].AmiId = OfflineAMI
+			}
+		}
+	}
+
 	extras := clusterextension.NewExtrasFromPlugins(plugins, c.PluginConfigs)
 
 	opts := api.ClusterOptions{
@@ -145,6 +193,34 @@ func ConfigFromBytes(data []byte, plugins []*api.Plugin) (*Config, error) {
 	return cfg, nil
 }
 
+// amiLookup is the AMI looked up for the main cluster or a node pool, as amiId isn't specified
+type amiLookup struct {
+	query ami.Query
+	pin   func(id string)
+}
+
+// amiLookups returns the AMIs looked up for the main cluster and the node pools.
+// Queries of node pools are made from the settings defaulted from the main cluster as NodePoolCompile does
+func amiLookups(c *UnmarshalledConfig) []amiLookup {
+	lookups := []amiLookup{}
+	if c.AmiId == "" {
+		lookups = append(lookups, amiLookup{
+			query: c.AMIQuery(),
+			pin:   func(id string) { c.AmiId = id },
+		})
+	}
+	for i := range c.NodePools {
+		np := &c.NodePools[i]
+		if np.AmiId == "" {
+			lookups = append(lookups, amiLookup{
+				query: np.DeploymentSettings.WithDefaultsFrom(c.DeploymentSettings).AMIQuery(),
+				pin:   func(id string) { np.AmiId = id },
+			})
+		}
+	}
+	return lookups
+}
+
 func failFastWhenUnknownKeysFound(vs []unknownKeyValidation) error {
 	for _, v := range vs {
 		if err := v.unknownKeysSupport.FailWhenUnknownKeysFound(v.keyPath); err != nil {
@@ -154,25 +230,339 @@ func failFastWhenUnknownKeysFound(vs []unknownKeyValidation) error {
 	return nil
 }
 
+// SecretStoreFunc returns the store to look up the secrets referenced from cluster.yaml in the region of the cluster
+type SecretStoreFunc func(region api.Region) (secret.Store, error)
+
+// ConfigFromFile loads cluster.yaml, looking up the secrets referenced from it with the default AWS credentials
 func ConfigFromFile(configPath string) (*Config, error) {
-	data, err := ioutil.ReadFile(configPath)
+	return ConfigFromFileWithSecretStore(configPath, func(region api.Region) (secret.Store, error) {
+		session, err := awsconn.NewSessionFromRegion(region, false, "")
+		if err != nil {
+			return nil, err
+		}
+		return secret.NewAWSStore(session), nil
+	})
+}
+
+// ConfigFromFileWithSecretStore loads cluster.yaml like ConfigFromFile but looks up the secrets from the store returned by `secrets`
+func ConfigFromFileWithSecretStore(configPath string, secrets SecretStoreFunc) (*Config, error) {
+	return configFromFile(configPath, false, secrets)
+}
+
+// OfflineConfigFromFile loads cluster.yaml like ConfigFromFile but uses OfflineAMI instead of looking up AMI IDs over the network,
+// and secret.PlaceholderValue instead of looking up secrets
+func OfflineConfigFromFile(configPath string) (*Config, error) {
+	return configFromFile(configPath, true, nil)
+}
+
+func configFromFile(configPath string, offline bool, secrets SecretStoreFunc) (*Config, error) {
+	data, err := ReadConfigFile(configPath)
 	if err != nil {
 		return nil, err
 	}
 
+	data, resolved, err := resolveSecrets(data, offline, secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets in %s: %v", configPath, err)
+	}
+
 	plugins, err := plugin.LoadAll()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load plugins: %v", err)
 	}
 
-	c, err := ConfigFromBytes(data, plugins)
+	lock, err := ami.LoadLockfile(LockfilePath(configPath))
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := configFromBytes(data, plugins, offline, lock)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed loading %s: %v", configPath, err)
 	}
 
+	if err := c.setSecrets(resolved); err != nil {
+		return nil, fmt.Errorf("failed loading %s: %v", configPath, err)
+	}
+
 	return c, nil
 }
 
+// LockfilePath returns the path of the lockfile pinning the AMIs of the cluster, which is next to cluster.yaml
+func LockfilePath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), ami.LockfileName)
+}
+
+// AMIQueriesFromFile returns the AMIs looked up for the nodes of the cluster.yaml at `configPath`, without duplicates.
+// Secrets are replaced with placeholders as they don't affect AMIs
+func AMIQueriesFromFile(configPath string) ([]ami.Query, error) {
+	data, err := ReadConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _, err = secret.ResolveWithPlaceholders(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets in %s: %v", configPath, err)
+	}
+
+	c, err := unmarshalConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Cluster.Load(); err != nil {
+		return nil, fmt.Errorf("failed loading %s: %v", configPath, err)
+	}
+
+	queries := []ami.Query{}
+	found := map[ami.Query]bool{}
+	for _, l := range amiLookups(c) {
+		if !found[l.query] {
+			found[l.query] = true
+			queries = append(queries, l.query)
+		}
+	}
+	return queries, nil
+}
+
+func resolveSecrets(data []byte, offline bool, secrets SecretStoreFunc) ([]byte, []secret.Resolved, error) {
+	if offline {
+		return secret.ResolveWithPlaceholders(data)
+	}
+	if !secret.HasReferences(data) {
+		return data, []secret.Resolved{}, nil
+	}
+
+	c := struct {
+		Region string `yaml:"region"`
+	}{}
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	store, err := secrets(api.RegionForName(c.Region))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize secret store: %v", err)
+	}
+	return secret.Resolve(data, store)
+}
+
+var (
+	controllerCustomFileContent = regexp.MustCompile(`^controller\.customFiles\[(\d+)\]\.content$`)
+	nodePoolCustomFileContent   = regexp.MustCompile(`^worker\.nodePools\[(\d+)\]\.customFiles\[(\d+)\]\.content$`)
+	oidcSetting                 = regexp.MustCompile(`^experimental\.oidc\.(issuerUrl|clientId|usernameClaim|groupsClaim)$`)
+	pluginValue                 = regexp.MustCompile(`^kubeAwsPlugins\.([^.\[]+)\.(.+)$`)
+	// pluginValueSegment matches a key or an index in the path to a plugin value e.g. `auth` and `[0]` in `auth.tokens[0]`
+	pluginValueSegment = regexp.MustCompile(`([^.\[\]]+)|\[(\d+)\]`)
+	unsafePathChars    = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+)
+
+const webhookConfig = "experimental.authentication.webhook.configBase64"
+
+// oidcSecretNames are the names of the placeholders substituted in the kube-apiserver manifest for the OIDC settings resolved from secrets
+var oidcSecretNames = map[string]string{
+	"issuerUrl":     "OIDC_ISSUER_URL",
+	"clientId":      "OIDC_CLIENT_ID",
+	"usernameClaim": "OIDC_USERNAME_CLAIM",
+	"groupsClaim":   "OIDC_GROUPS_CLAIM",
+}
+
+// setSecrets marks the controller and worker custom files and the authentication webhook config resolved from secrets to be encrypted.
+// OIDC settings and plugin values resolved from secrets are moved to controller custom files to be encrypted as well.
+// Secrets referenced from anywhere else are rejected, as they would be rendered unencrypted in userdata and stack templates
+func (c *Config) setSecrets(resolved []secret.Resolved) error {
+	c.Secrets = resolved
+	for _, r := range resolved {
+		if m := controllerCustomFileContent.FindStringSubmatch(r.Path); m != nil {
+			i, _ := strconv.Atoi(m[1])
+			c.Controller.CustomFiles[i].Secret = true
+			continue
+		}
+		if m := nodePoolCustomFileContent.FindStringSubmatch(r.Path); m != nil {
+			i, _ := strconv.Atoi(m[1])
+			j, _ := strconv.Atoi(m[2])
+			c.Config.NodePools[i].CustomFiles[j].Secret = true
+			continue
+		}
+		if r.Path == webhookConfig {
+			c.Experimental.Authentication.Webhook.Secret = true
+			continue
+		}
+		if m := oidcSetting.FindStringSubmatch(r.Path); m != nil {
+			c.setOidcSecret(m[1], r.Value)
+			continue
+		}
+		if m := pluginValue.FindStringSubmatch(r.Path); m != nil {
+			if err := c.setPluginSecret(m[1], m[2], r.Value); err != nil {
+				return fmt.Errorf("%s: %v", r.Path, err)
+			}
+			continue
+		}
+		if strings.HasPrefix(r.Path, "etcd.customFiles[") {
+			return fmt.Errorf("%s: secrets can't be referenced from etcd custom files as etcd nodes don't decrypt them", r.Path)
+		}
+		return fmt.Errorf("%s: secrets can be referenced only from the content of controller and worker custom files, %s, experimental.oidc and kubeAwsPlugins, which are encrypted with KMS, as the value would be rendered unencrypted in userdata and stack templates", r.Path, webhookConfig)
+	}
+	return nil
+}
+
+// setOidcSecret moves the OIDC setting `key` to a controller custom file, and replaces the setting with the placeholder
+// which is substituted in the kube-apiserver manifest with the content of the file once decrypted
+func (c *Config) setOidcSecret(key string, value string) {
+	name := oidcSecretNames[key]
+	path := "/etc/kubernetes/secrets/oidc/" + key
+	c.Controller.CustomFiles = append(c.Controller.CustomFiles, api.CustomFile{Path: path, Permissions: 0600, Content: value, Secret: true})
+
+	oidc := &c.Experimental.Oidc
+	oidc.SecretValues = append(oidc.SecretValues, api.SecretValue{Name: name, Path: path})
+	placeholder := "$" + name
+	switch key {
+	case "issuerUrl":
+		oidc.IssuerUrl = placeholder
+	case "clientId":
+		oidc.ClientId = placeholder
+	case "usernameClaim":
+		oidc.UsernameClaim = placeholder
+	case "groupsClaim":
+		oidc.GroupsClaim = placeholder
+	}
+}
+
+// setPluginSecret moves the value at `key` of the plugin to a controller custom file, and replaces the value with the path to the file.
+// Node pools inherit the replaced value, as the plugin configs of the cluster are merged into theirs when their stacks are loaded
+func (c *Config) setPluginSecret(plugin string, key string, value string) error {
+	pc, ok := c.PluginConfigs[plugin]
+	if !ok {
+		return fmt.Errorf("plugin %s is not configured", plugin)
+	}
+	segments := pluginValueSegment.FindAllStringSubmatch(key, -1)
+	// Indexes are written without brackets and other characters are replaced, as the script decrypting the file doesn't quote the path
+	names := []string{}
+	for _, s := range segments {
+		names = append(names, unsafePathChars.ReplaceAllString(s[1]+s[2], "_"))
+	}
+	path := "/etc/kubernetes/secrets/plugins/" + unsafePathChars.ReplaceAllString(plugin, "_") + "/" + strings.Join(names, ".")
+	if !replacePluginValue(map[string]interface{}(pc.Values), segments, path) {
+		return fmt.Errorf("no string value found in plugin %s", plugin)
+	}
+	c.Controller.CustomFiles = append(c.Controller.CustomFiles, api.CustomFile{Path: path, Permissions: 0600, Content: value, Secret: true})
+	return nil
+}
+
+// replacePluginValue replaces the string at the path made of `segments` in `node` with `value`, and returns false when there's no string at the path
+func replacePluginValue(node interface{}, segments [][]string, value string) bool {
+	if len(segments) == 0 {
+		return false
+	}
+	s := segments[0]
+	var child interface{}
+	var set func(v interface{})
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, set = n[s[1]], func(v interface{}) { n[s[1]] = v }
+	case map[interface{}]interface{}:
+		child, set = n[s[1]], func(v interface{}) { n[s[1]] = v }
+	case []interface{}:
+		i, err := strconv.Atoi(s[2])
+		if err != nil || i >= len(n) {
+			return false
+		}
+		child, set = n[i], func(v interface{}) { n[i] = v }
+	default:
+		return false
+	}
+	if len(segments) > 1 {
+		return replacePluginValue(child, segments[1:], value)
+	}
+	if _, ok := child.(string); !ok {
+		return false
+	}
+	set(value)
+	return true
+}
+
+// ValidateSchemaFromFile validates the cluster.yaml at `configPath` and the plugin.yaml of every plugin against their JSON Schemas,
+// and returns the violations found prefixed with the file names
+func ValidateSchemaFromFile(configPath string) ([]string, error) {
+	files := map[string]*schema.Schema{configPath: api.ClusterSchema()}
+	names := []string{configPath}
+
+	// Overlays are validated one by one so that violations are reported against the files containing them
+	for _, o := range Overlays {
+		files[o] = api.ClusterSchema()
+		names = append(names, o)
+	}
+
+	fileInfos, _ := ioutil.ReadDir("plugins/")
+	for _, f := range fileInfos {
+		if f.IsDir() {
+			path := filepath.Join("plugins", f.Name(), "plugin.yaml")
+			files[path] = api.PluginSchema()
+			names = append(names, path)
+		}
+	}
+
+	violations := []string{}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		vs, err := files[name].ValidateYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		for _, v := range vs {
+			violations = append(violations, fmt.Sprintf("%s: %s", name, v))
+		}
+	}
+	return violations, nil
+}
+
+// MigrateFromFile rewrites the deprecated keys in the cluster.yaml at `configPath` and makes sure that the result is still loadable.
+// It returns the rewritten cluster.yaml and the changes made without writing them back.
+// Both are loaded offline, as only the keys matter here, so that the check works without network access e.g. in CI.
+func MigrateFromFile(configPath string) ([]byte, []string, error) {
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plugins, err := plugin.LoadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load plugins: %v", err)
+	}
+
+	lock, err := ami.LoadLockfile(LockfilePath(configPath))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := compileOffline(data, plugins, lock); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed loading %s: %v", configPath, err)
+	}
+
+	migrated, changes, err := api.MigrateClusterYaml(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := compileOffline(migrated, plugins, lock); err != nil {
+		return nil, nil, fmt.Errorf("[BUG] migrated %s is invalid: %v", configPath, err)
+	}
+
+	return migrated, changes, nil
+}
+
+// compileOffline makes sure that the cluster.yaml in `data` is loadable, without looking up AMIs or secrets
+func compileOffline(data []byte, plugins []*api.Plugin, lock *ami.Lockfile) error {
+	data, _, err := secret.ResolveWithPlaceholders(data)
+	if err != nil {
+		return fmt.Errorf("failed to resolve secrets: %v", err)
+	}
+	_, err = configFromBytes(data, plugins, true, lock)
+	return err
+}
+
 func (c *Config) RootStackName() string {
 	return c.ClusterName
 }
@@ -0,0 +1,243 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-aws/ami"
+	"github.com/kubernetes-incubator/kube-aws/hostos"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/pkg/model"
+	"github.com/kubernetes-incubator/kube-aws/secret"
+	"github.com/kubernetes-incubator/kube-aws/test/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetSecrets(t *testing.T) {
+	newConfig := func() *Config {
+		c := api.NewDefaultCluster()
+		c.Controller.CustomFiles = []api.CustomFile{{Path: "/etc/app/config"}, {Path: "/etc/app/token"}}
+		c.Worker.NodePools = []api.WorkerNodePool{{NodePoolName: "pool1", CustomFiles: []api.CustomFile{{Path: "/etc/app/token"}}}}
+		return &Config{Config: &model.Config{Cluster: c}}
+	}
+
+	t.Run("Encrypted", func(t *testing.T) {
+		c := newConfig()
+		resolved := []secret.Resolved{
+			{Path: "controller.customFiles[1].content", Value: "s3cr3t"},
+			{Path: "worker.nodePools[0].customFiles[0].content", Value: "s3cr3t"},
+			{Path: "experimental.authentication.webhook.configBase64", Value: "czNjcjN0"},
+		}
+		require.NoError(t, c.setSecrets(resolved))
+
+		assert.Equal(t, resolved, c.Secrets)
+		assert.False(t, c.Controller.CustomFiles[0].Secret)
+		assert.True(t, c.Controller.CustomFiles[1].Secret)
+		assert.True(t, c.Config.NodePools[0].CustomFiles[0].Secret)
+		assert.True(t, c.Experimental.Authentication.Webhook.Secret)
+	})
+
+	testCases := []struct {
+		path string
+		err  string
+	}{
+		{
+			path: "etcd.customFiles[0].content",
+			err:  "etcd.customFiles[0].content: secrets can't be referenced from etcd custom files as etcd nodes don't decrypt them",
+		},
+		{
+			path: "worker.nodePools[0].kubeAwsPlugins.myplugin.password",
+			err:  "worker.nodePools[0].kubeAwsPlugins.myplugin.password: secrets can be referenced only from the content of controller and worker custom files, experimental.authentication.webhook.configBase64, experimental.oidc and kubeAwsPlugins, which are encrypted with KMS, as the value would be rendered unencrypted in userdata and stack templates",
+		},
+		{
+			path: "controller.customFiles[0].path",
+			err:  "controller.customFiles[0].path: secrets can be referenced only from the content of controller and worker custom files, experimental.authentication.webhook.configBase64, experimental.oidc and kubeAwsPlugins, which are encrypted with KMS, as the value would be rendered unencrypted in userdata and stack templates",
+		},
+		{
+			path: "kubeAwsPlugins.unknown.password",
+			err:  "kubeAwsPlugins.unknown.password: plugin unknown is not configured",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			c := newConfig()
+			assert.EqualError(t, c.setSecrets([]secret.Resolved{{Path: tc.path, Value: "s3cr3t"}}), tc.err)
+		})
+	}
+}
+
+const amiConfigYaml = `clusterName: mycluster
+keyName: mykey
+region: us-west-2
+availabilityZone: us-west-2a
+s3URI: s3://mybucket/mydir
+kmsKeyArn: "arn:aws:kms:us-west-2:xxxxxxxxx:key/xxxxxxxxxxxxxxxxxxx"
+apiEndpoints:
+- name: public
+  dnsName: mycluster.example.com
+  loadBalancer:
+    hostedZone:
+      id: hostedzone-xxxx
+worker:
+  nodePools:
+  - name: flatcar
+  - name: al2
+    hostOS:
+      name: amazon-linux-2
+  - name: beta
+    releaseChannel: beta
+  - name: custom
+    amiId: ami-custom
+`
+
+var (
+	flatcarStable = ami.Query{OS: hostos.Flatcar, Region: "us-west-2", Channel: "stable"}
+	flatcarBeta   = ami.Query{OS: hostos.Flatcar, Region: "us-west-2", Channel: "beta"}
+	al2           = ami.Query{OS: hostos.AmazonLinux2, Region: "us-west-2"}
+	al2EastUS     = ami.Query{OS: hostos.AmazonLinux2, Region: "us-east-1"}
+)
+
+func TestAMILookups(t *testing.T) {
+	c, err := unmarshalConfig([]byte(amiConfigYaml))
+	require.NoError(t, err)
+	require.NoError(t, c.Cluster.Load())
+
+	lookups := amiLookups(c)
+	queries := []ami.Query{}
+	for _, l := range lookups {
+		queries = append(queries, l.query)
+	}
+	assert.Equal(t, []ami.Query{flatcarStable, flatcarStable, al2, flatcarBeta}, queries)
+
+	for i, l := range lookups {
+		l.pin(fmt.Sprintf("ami-pinned%d", i))
+	}
+	assert.Equal(t, "ami-pinned0", c.AmiId)
+	amiIds := []string{}
+	for _, np := range c.NodePools {
+		amiIds = append(amiIds, np.AmiId)
+	}
+	assert.Equal(t, []string{"ami-pinned1", "ami-pinned2", "ami-pinned3", "ami-custom"}, amiIds)
+}
+
+func TestConfigFromBytesWithLockfile(t *testing.T) {
+	lock := &ami.Lockfile{}
+	lock.Pin(flatcarStable, "ami-flatcar")
+	lock.Pin(al2, "ami-al2")
+	lock.Pin(flatcarBeta, "ami-beta")
+	lock.Pin(al2EastUS, "ami-unused")
+
+	c, err := configFromBytes([]byte(amiConfigYaml), nil, false, lock)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ami-flatcar", c.AmiId)
+	amiIds := map[string]string{}
+	for _, np := range c.NodePools {
+		amiIds[np.NodePoolName] = np.AmiId
+	}
+	assert.Equal(t, map[string]string{"flatcar": "ami-flatcar", "al2": "ami-al2", "beta": "ami-beta", "custom": "ami-custom"}, amiIds)
+}
+
+type noNetwork struct{}
+
+func (noNetwork) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("no network access to " + req.URL.String())
+}
+
+func TestMigrateFromFileWithoutNetwork(t *testing.T) {
+	transport := http.DefaultTransport
+	http.DefaultTransport = noNetwork{}
+	defer func() { http.DefaultTransport = transport }()
+
+	helper.WithTempDir(func(dir string) {
+		// Neither the AMIs nor the secret are looked up to check the migration
+		original := amiConfigYaml + `controller:
+  customFiles:
+  - path: /etc/token
+    permissions: 0600
+    content: '{{ssm "/mycluster/token"}}'
+vpcId: vpc-123
+internetGatewayId: igw-1
+`
+		configPath := filepath.Join(dir, "cluster.yaml")
+		require.NoError(t, ioutil.WriteFile(configPath, []byte(original), 0644))
+
+		migrated, changes, err := MigrateFromFile(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"moved vpcId to vpc.id", "moved internetGatewayId to internetGateway.id"}, changes)
+		assert.Contains(t, string(migrated), `content: '{{ssm "/mycluster/token"}}'`, "secret references must be kept as is")
+		assert.Contains(t, string(migrated), "vpc:\n  id: vpc-123\n")
+	})
+}
+
+func TestConfigFromFileWithSecretStore(t *testing.T) {
+	transport := http.DefaultTransport
+	http.DefaultTransport = noNetwork{}
+	defer func() { http.DefaultTransport = transport }()
+
+	store := &secret.MemoryStore{
+		Parameters: map[string]string{"/mycluster/oidc-client-id": "myclientid"},
+		Secrets:    map[string]string{"mycluster/plugin": `{"password":"s3cr3t"}`},
+	}
+	secrets := func(region api.Region) (secret.Store, error) {
+		return store, nil
+	}
+
+	helper.WithTempDir(func(dir string) {
+		configPath := filepath.Join(dir, "cluster.yaml")
+		data := `clusterName: mycluster
+keyName: mykey
+region: us-west-2
+availabilityZone: us-west-2a
+s3URI: s3://mybucket/mydir
+kmsKeyArn: "arn:aws:kms:us-west-2:xxxxxxxxx:key/xxxxxxxxxxxxxxxxxxx"
+amiId: ami-1234
+apiEndpoints:
+- name: public
+  dnsName: mycluster.example.com
+  loadBalancer:
+    hostedZone:
+      id: hostedzone-xxxx
+worker:
+  nodePools:
+  - name: pool1
+    amiId: ami-1234
+experimental:
+  oidc:
+    enabled: true
+    issuerUrl: https://accounts.example.com
+    clientId: '{{ssm "/mycluster/oidc-client-id"}}'
+kubeAwsPlugins:
+  myplugin:
+    enabled: true
+    auth:
+      users:
+      - name: admin
+        password: '{{secretsmanager "mycluster/plugin" "password"}}'
+`
+		require.NoError(t, ioutil.WriteFile(configPath, []byte(data), 0644))
+
+		c, err := ConfigFromFileWithSecretStore(configPath, secrets)
+		require.NoError(t, err)
+
+		oidc := c.Experimental.Oidc
+		assert.Equal(t, "https://accounts.example.com", oidc.IssuerUrl)
+		assert.Equal(t, "$OIDC_CLIENT_ID", oidc.ClientId)
+		assert.Equal(t, []api.SecretValue{{Name: "OIDC_CLIENT_ID", Path: "/etc/kubernetes/secrets/oidc/clientId"}}, oidc.SecretValues)
+
+		auth := c.PluginConfigs["myplugin"].Values["auth"].(map[interface{}]interface{})
+		user := auth["users"].([]interface{})[0].(map[interface{}]interface{})
+		assert.Equal(t, "/etc/kubernetes/secrets/plugins/myplugin/auth.users.0.password", user["password"])
+
+		assert.Equal(t, []api.CustomFile{
+			{Path: "/etc/kubernetes/secrets/oidc/clientId", Permissions: 0600, Content: "myclientid", Secret: true},
+			{Path: "/etc/kubernetes/secrets/plugins/myplugin/auth.users.0.password", Permissions: 0600, Content: "s3cr3t", Secret: true},
+		}, c.Controller.CustomFiles)
+	})
+}
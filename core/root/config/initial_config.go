@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	// TopologyPublic places all the nodes in public subnets
+	TopologyPublic = "public"
+	// TopologyPrivate places all the nodes in private subnets behind NAT gateways, and only load balancers in public subnets
+	TopologyPrivate = "private"
+
+	DefaultVPCCIDR = "10.0.0.0/16"
+)
+
+// InitialNodePool is a node pool written to the initial cluster.yaml
+type InitialNodePool struct {
+	Name string
+	// Count is the number of worker nodes. Zero means the count isn't specified and defaults to the one of kube-aws
+	Count int
+}
+
+// InitialSubnet is a managed subnet written to the initial cluster.yaml
+type InitialSubnet struct {
+	Name             string
+	AvailabilityZone string
+	InstanceCIDR     string
+	Private          bool
+}
+
+// ParseInitialNodePool parses a node pool specified like `name` or `name:count`
+func ParseInitialNodePool(s string) (InitialNodePool, error) {
+	parts := strings.SplitN(s, ":", 2)
+	np := InitialNodePool{Name: parts[0]}
+	if np.Name == "" {
+		return np, fmt.Errorf("invalid node pool %q: name must not be empty", s)
+	}
+	if len(parts) == 2 {
+		count, err := strconv.Atoi(parts[1])
+		if err != nil || count < 1 {
+			return np, fmt.Errorf("invalid node pool %q: count must be a positive integer", s)
+		}
+		np.Count = count
+	}
+	return np, nil
+}
+
+// Prepare validates the initial config and wires subnets to the availability zones.
+// Subnets are generated only when they are required i.e. there are 2 or more availability zones or the topology is private.
+// Otherwise the single availability zone is written to the top-level `availabilityZone` as before.
+func (c *InitialConfig) Prepare() error {
+	if len(c.AvailabilityZones) == 0 && c.AvailabilityZone != "" {
+		c.AvailabilityZones = []string{c.AvailabilityZone}
+	}
+	if len(c.AvailabilityZones) == 0 {
+		return fmt.Errorf("at least one availability zone must be specified")
+	}
+	seen := map[string]bool{}
+	for _, az := range c.AvailabilityZones {
+		if !strings.HasPrefix(az, c.Region.Name) {
+			return fmt.Errorf("availability zone %s is not in region %s", az, c.Region.Name)
+		}
+		if seen[az] {
+			return fmt.Errorf("availability zone %s is specified more than once", az)
+		}
+		seen[az] = true
+	}
+
+	if c.Topology == "" {
+		c.Topology = TopologyPublic
+	}
+	if c.Topology != TopologyPublic && c.Topology != TopologyPrivate {
+		return fmt.Errorf("topology must be either %s or %s but was %s", TopologyPublic, TopologyPrivate, c.Topology)
+	}
+
+	if c.ControllerCount < 1 {
+		return fmt.Errorf("controller count must be 1 or greater but was %d", c.ControllerCount)
+	}
+	if c.EtcdCount < 1 || c.EtcdCount%2 == 0 {
+		return fmt.Errorf("etcd count must be an odd number so that etcd tolerates failures but was %d", c.EtcdCount)
+	}
+
+	if len(c.NodePools) == 0 {
+		c.NodePools = []InitialNodePool{{Name: "nodepool1"}}
+	}
+	names := map[string]bool{}
+	for _, np := range c.NodePools {
+		if names[np.Name] {
+			return fmt.Errorf("node pool %s is specified more than once", np.Name)
+		}
+		names[np.Name] = true
+	}
+
+	if c.VPCID != "" && c.InternetGatewayID == "" {
+		return fmt.Errorf("the id of the internet gateway attached to VPC %s is required for the managed public subnets", c.VPCID)
+	}
+	if c.VPCID == "" && c.InternetGatewayID != "" {
+		return fmt.Errorf("an internet gateway can be reused only with an existing VPC")
+	}
+	if c.VPCCIDR == "" {
+		c.VPCCIDR = DefaultVPCCIDR
+	}
+
+	// Public subnets take the first /24 blocks of the VPC and private subnets the ones from the middle, so that more can be added later
+	_, vpcNet, err := net.ParseCIDR(c.VPCCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid VPC CIDR: %v", err)
+	}
+	ones, bits := vpcNet.Mask.Size()
+	if bits != 32 || ones > 22 {
+		return fmt.Errorf("VPC CIDR %s must be an IPv4 CIDR of /22 or larger to be split into /24 subnets", c.VPCCIDR)
+	}
+	blocks := 1 << uint(24-ones)
+	if 2*len(c.AvailabilityZones) > blocks {
+		return fmt.Errorf("VPC CIDR %s is too small for subnets in %d availability zones", c.VPCCIDR, len(c.AvailabilityZones))
+	}
+
+	c.Subnets = nil
+	if len(c.AvailabilityZones) == 1 && c.Topology == TopologyPublic {
+		c.AvailabilityZone = c.AvailabilityZones[0]
+		c.InstanceCIDR = subnetCIDR(vpcNet, 0)
+		return nil
+	}
+	c.AvailabilityZone = ""
+	c.InstanceCIDR = ""
+
+	for i, az := range c.AvailabilityZones {
+		c.Subnets = append(c.Subnets, InitialSubnet{
+			Name:             fmt.Sprintf("ManagedPublicSubnet%d", i+1),
+			AvailabilityZone: az,
+			InstanceCIDR:     subnetCIDR(vpcNet, i),
+		})
+	}
+	if c.Topology == TopologyPrivate {
+		for i, az := range c.AvailabilityZones {
+			c.Subnets = append(c.Subnets, InitialSubnet{
+				Name:             fmt.Sprintf("ManagedPrivateSubnet%d", i+1),
+				AvailabilityZone: az,
+				InstanceCIDR:     subnetCIDR(vpcNet, blocks/2+i),
+				Private:          true,
+			})
+		}
+	}
+	return nil
+}
+
+// PublicSubnets are the subnets for the API load balancer
+func (c InitialConfig) PublicSubnets() []InitialSubnet {
+	subnets := []InitialSubnet{}
+	for _, s := range c.Subnets {
+		if !s.Private {
+			subnets = append(subnets, s)
+		}
+	}
+	return subnets
+}
+
+// NodeSubnets are the subnets for controller, etcd and worker nodes
+func (c InitialConfig) NodeSubnets() []InitialSubnet {
+	if c.Topology != TopologyPrivate {
+		return c.PublicSubnets()
+	}
+	subnets := []InitialSubnet{}
+	for _, s := range c.Subnets {
+		if s.Private {
+			subnets = append(subnets, s)
+		}
+	}
+	return subnets
+}
+
+// subnetCIDR returns the `index`-th /24 block in the VPC CIDR
+func subnetCIDR(vpcNet *net.IPNet, index int) string {
+	ip := vpcNet.IP.To4()
+	n := uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
+	n += uint32(index) << 8
+	return fmt.Sprintf("%d.%d.%d.%d/24", byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"text/template"
+
+	"github.com/kubernetes-incubator/kube-aws/builtin"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInitialNodePool(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected InitialNodePool
+		err      string
+	}{
+		{input: "pool1", expected: InitialNodePool{Name: "pool1"}},
+		{input: "pool1:3", expected: InitialNodePool{Name: "pool1", Count: 3}},
+		{input: "pool1:0", err: `invalid node pool "pool1:0": count must be a positive integer`},
+		{input: "pool1:-1", err: `invalid node pool "pool1:-1": count must be a positive integer`},
+		{input: "pool1:three", err: `invalid node pool "pool1:three": count must be a positive integer`},
+		{input: ":3", err: `invalid node pool ":3": name must not be empty`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			np, err := ParseInitialNodePool(tc.input)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, np)
+		})
+	}
+}
+
+func newInitialConfig(f func(c *InitialConfig)) InitialConfig {
+	c := InitialConfig{
+		AmiId:             "ami-0123456789abcdef0",
+		AvailabilityZones: []string{"us-west-1a"},
+		ClusterName:       "mycluster",
+		ControllerCount:   1,
+		EtcdCount:         1,
+		ExternalDNSName:   "mycluster.example.com",
+		HostedZoneID:      "Z1234567890",
+		KMSKeyARN:         "arn:aws:kms:us-west-1:123456789012:key/mykey",
+		KeyName:           "mykey",
+		Region:            api.RegionForName("us-west-1"),
+		S3URI:             "s3://mybucket/mydir",
+	}
+	f(&c)
+	return c
+}
+
+func TestPrepare(t *testing.T) {
+	testCases := []struct {
+		context                  string
+		config                   InitialConfig
+		expectedAvailabilityZone string
+		expectedInstanceCIDR     string
+		expectedSubnets          []InitialSubnet
+		expectedNodePools        []InitialNodePool
+		err                      string
+	}{
+		{
+			context:                  "SingleAvailabilityZone",
+			config:                   newInitialConfig(func(c *InitialConfig) {}),
+			expectedAvailabilityZone: "us-west-1a",
+			expectedInstanceCIDR:     "10.0.0.0/24",
+			expectedNodePools:        []InitialNodePool{{Name: "nodepool1"}},
+		},
+		{
+			context: "MultipleAvailabilityZones",
+			config: newInitialConfig(func(c *InitialConfig) {
+				c.AvailabilityZones = []string{"us-west-1a", "us-west-1b"}
+				c.NodePools = []InitialNodePool{{Name: "pool1", Count: 2}}
+			}),
+			expectedSubnets: []InitialSubnet{
+				{Name: "ManagedPublicSubnet1", AvailabilityZone: "us-west-1a", InstanceCIDR: "10.0.0.0/24"},
+				{Name: "ManagedPublicSubnet2", AvailabilityZone: "us-west-1b", InstanceCIDR: "10.0.1.0/24"},
+			},
+			expectedNodePools: []InitialNodePool{{Name: "pool1", Count: 2}},
+		},
+		{
+			context: "PrivateTopology",
+			config: newInitialConfig(func(c *InitialConfig) {
+				c.Topology = TopologyPrivate
+				c.VPCCIDR = "10.1.0.0/22"
+			}),
+			expectedSubnets: []InitialSubnet{
+				{Name: "ManagedPublicSubnet1", AvailabilityZone: "us-west-1a", InstanceCIDR: "10.1.0.0/24"},
+				{Name: "ManagedPrivateSubnet1", AvailabilityZone: "us-west-1a", InstanceCIDR: "10.1.2.0/24", Private: true},
+			},
+			expectedNodePools: []InitialNodePool{{Name: "nodepool1"}},
+		},
+		{
+			context: "AvailabilityZoneInAnotherRegion",
+			config: newInitialConfig(func(c *InitialConfig) {
+				c.AvailabilityZones = []string{"us-east-1a"}
+			}),
+			err: "availability zone us-east-1a is not in region us-west-1",
+		},
+		{
+			context: "DuplicateAvailabilityZones",
+			config: newInitialConfig(func(c *InitialConfig) {
+				c.AvailabilityZones = []string{"us-west-1a", "us-west-1a"}
+			}),
+			err: "availability zone us-west-1a is specified more than once",
+		},
+		{
+			context: "UnknownTopology",
+			config: newInitialConfig(func(c *InitialConfig) {
+				c.Topology = "hybrid"
+			}),
+			err: "topology must be either public or private but was hybrid",
+		},
+		{
+			context: "EvenEtcdCount",
+			config: newInitialConfig(func(c *InitialConfig) {
+				c.EtcdCount = 2
+			}),
+			err: "etcd count must be an odd number so that etcd tolerates failures but was 2",
+		},
+		{
+			context: "DuplicateNodePools",
+			config: newInitialConfig(func(c *InitialConfig) {
+				c.NodePools = []InitialNodePool{{Name: "pool1"}, {Name: "pool1", Count: 2}}
+			}),
+			err: "node pool pool1 is specified more than once",
+		},
+		{
+			context: "ExistingVPCWithoutInternetGateway",
+			config: newInitialConfig(func(c *InitialConfig) {
+				c.VPCID = "vpc-12345678"
+			}),
+			err: "the id of the internet gateway attached to VPC vpc-12345678 is required for the managed public subnets",
+		},
+		{
+			context: "TooSmallVPC",
+			config: newInitialConfig(func(c *InitialConfig) {
+				c.VPCCIDR = "10.0.0.0/23"
+			}),
+			err: "VPC CIDR 10.0.0.0/23 must be an IPv4 CIDR of /22 or larger to be split into /24 subnets",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			c := tc.config
+			err := c.Prepare()
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAvailabilityZone, c.AvailabilityZone)
+			assert.Equal(t, tc.expectedInstanceCIDR, c.InstanceCIDR)
+			assert.Equal(t, tc.expectedSubnets, c.Subnets)
+			assert.Equal(t, tc.expectedNodePools, c.NodePools)
+		})
+	}
+}
+
+func TestSubnetCIDR(t *testing.T) {
+	_, vpcNet, err := net.ParseCIDR("10.0.0.0/16")
+	require.NoError(t, err)
+
+	assert.Equal(t, "10.0.0.0/24", subnetCIDR(vpcNet, 0))
+	assert.Equal(t, "10.0.1.0/24", subnetCIDR(vpcNet, 1))
+	assert.Equal(t, "10.0.128.0/24", subnetCIDR(vpcNet, 128))
+	assert.Equal(t, "10.0.255.0/24", subnetCIDR(vpcNet, 255))
+}
+
+func TestInitialClusterYaml(t *testing.T) {
+	render := func(c InitialConfig) *UnmarshalledConfig {
+		require.NoError(t, c.Prepare())
+		tmpl, err := template.New("cluster.yaml").Parse(string(builtin.Bytes("cluster.yaml.tmpl")))
+		require.NoError(t, err)
+		buf := new(bytes.Buffer)
+		require.NoError(t, tmpl.Execute(buf, c))
+
+		unmarshalled, err := unmarshalConfig(buf.Bytes())
+		require.NoError(t, err)
+		require.NoError(t, unmarshalled.Load())
+		return unmarshalled
+	}
+
+	t.Run("SingleAvailabilityZone", func(t *testing.T) {
+		c := render(newInitialConfig(func(c *InitialConfig) {}))
+		assert.Equal(t, "us-west-1a", c.AvailabilityZone)
+		assert.Equal(t, "ami-0123456789abcdef0", c.AmiId)
+		require.Len(t, c.NodePools, 1)
+		assert.Equal(t, "nodepool1", c.NodePools[0].NodePoolName)
+		assert.Equal(t, 1, c.NodePools[0].Count)
+		assert.Empty(t, c.NodePools[0].Subnets)
+	})
+
+	t.Run("PrivateTopologyWithNodePools", func(t *testing.T) {
+		c := render(newInitialConfig(func(c *InitialConfig) {
+			c.AvailabilityZones = []string{"us-west-1a", "us-west-1b"}
+			c.Topology = TopologyPrivate
+			c.ControllerCount = 2
+			c.EtcdCount = 3
+			c.NodePools = []InitialNodePool{{Name: "pool1", Count: 3}, {Name: "pool2"}}
+		}))
+		assert.Equal(t, "", c.AvailabilityZone)
+		assert.Len(t, c.Subnets, 4)
+		assert.Equal(t, 2, c.Controller.Count)
+		assert.Equal(t, 3, c.Etcd.Count)
+		require.Len(t, c.NodePools, 2)
+		assert.Equal(t, 3, c.NodePools[0].Count)
+		assert.Equal(t, 1, c.NodePools[1].Count)
+		for _, np := range c.NodePools {
+			require.Len(t, np.Subnets, 2)
+			assert.Equal(t, "ManagedPrivateSubnet1", np.Subnets[0].Name)
+			assert.Equal(t, "ManagedPrivateSubnet2", np.Subnets[1].Name)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-yaml/yaml"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+)
+
+// Overlays are the paths to the files deep-merged into cluster.yaml in order whenever it is loaded
+var Overlays []string
+
+// EnvOverlayPath returns the path to the overlay for the environment named `env`, which sits next to cluster.yaml
+// e.g. `cluster.prod.yaml` for `prod`
+func EnvOverlayPath(configPath string, env string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + "." + env + ext
+}
+
+// ReadConfigFile reads the cluster.yaml at `configPath` and merges Overlays into it
+func ReadConfigFile(configPath string) ([]byte, error) {
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range Overlays {
+		overlay, err := ioutil.ReadFile(o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read overlay: %v", err)
+		}
+		data, err = api.MergeClusterYaml(data, overlay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge %s into %s: %v", o, configPath, err)
+		}
+	}
+
+	return data, nil
+}
+
+// FileDefiningKey returns the last of cluster.yaml and Overlays which sets the top-level key, so that updating the key
+// in the file changes the merged cluster.yaml. It returns `configPath` when none of them does.
+func FileDefiningKey(configPath string, key string) (string, error) {
+	for i := len(Overlays) - 1; i >= 0; i-- {
+		data, err := ioutil.ReadFile(Overlays[i])
+		if err != nil {
+			return "", fmt.Errorf("failed to read overlay: %v", err)
+		}
+		m := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %v", Overlays[i], err)
+		}
+		if _, ok := m[key]; ok {
+			return Overlays[i], nil
+		}
+	}
+	return configPath, nil
+}
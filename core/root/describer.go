@@ -1,22 +1,73 @@
 package root
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/kubernetes-incubator/kube-aws/awsconn"
+	"github.com/kubernetes-incubator/kube-aws/cfnstack"
 	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/naming"
 	"github.com/kubernetes-incubator/kube-aws/pkg/model"
 )
 
+// Info is the status of the cluster printed by `kube-aws status`
 type Info struct {
-	ControlPlane *model.Info
+	ControlPlane *model.Info `json:"controlPlane" yaml:"controlPlane"`
+	// Stacks contains the statuses of the root, network, etcd and control-plane stacks in this order
+	Stacks    []*cfnstack.StackStatus    `json:"stacks" yaml:"stacks"`
+	NodePools []*model.NodePoolStackInfo `json:"nodePools" yaml:"nodePools"`
 }
 
 func (i *Info) String() string {
-	return i.ControlPlane.String()
+	buf := new(bytes.Buffer)
+	buf.WriteString(i.ControlPlane.String())
+
+	statuses := append([]*cfnstack.StackStatus{}, i.Stacks...)
+	for _, np := range i.NodePools {
+		if np.Stack != nil {
+			statuses = append(statuses, np.Stack)
+		}
+	}
+	if len(statuses) == 0 {
+		return buf.String()
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(buf, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\nSTACK\tSTATUS\tLAST UPDATED\tSTACK NAME\n")
+	for _, s := range statuses {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, s.Status, s.LastUpdated.Format(time.RFC3339), s.StackName)
+	}
+
+	fmt.Fprintf(w, "\nSTACK\tGROUP\tDESIRED\tIN SERVICE\tPENDING\n")
+	for _, s := range statuses {
+		for _, g := range s.Groups {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\n", s.Name, g.LogicalID, g.Desired, g.InService, g.Pending)
+		}
+	}
+	w.Flush()
+
+	inProgress := []string{}
+	for _, s := range statuses {
+		if s.InProgress {
+			inProgress = append(inProgress, fmt.Sprintf("%s (%s)", s.Name, s.Status))
+		}
+	}
+	if len(inProgress) > 0 {
+		fmt.Fprintf(buf, "\nOperations in progress: %s\n", strings.Join(inProgress, ", "))
+	}
+
+	return buf.String()
 }
 
 type ClusterDescriber interface {
@@ -31,7 +82,7 @@ type clusterDescriberImpl struct {
 }
 
 func ClusterDescriberFromFile(configPath string, opts options) (ClusterDescriber, error) {
-	config, err := config.ConfigFromFile(configPath)
+	config, err := config.ConfigFromFileWithSecretStore(configPath, secretStore(false, opts.AWSProfile))
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +111,7 @@ func (c clusterDescriberImpl) Info() (*Info, error) {
 	{
 		resp, err := cfSvc.DescribeStackResource(
 			&cloudformation.DescribeStackResourceInput{
-				LogicalResourceId: aws.String("Controlplane"),
+				LogicalResourceId: aws.String(naming.FromStackToCfnResource(c.cpConfig.ControlPlaneStackName())),
 				StackName:         aws.String(c.stackName),
 			},
 		)
@@ -97,5 +148,48 @@ func (c clusterDescriberImpl) Info() (*Info, error) {
 		info.ControlPlane = cpInfo
 	}
 
+	describer := cfnstack.StatusDescriber{
+		CloudFormation: cfSvc,
+		AutoScaling:    autoscaling.New(c.session),
+		EC2:            ec2.New(c.session),
+	}
+
+	rootStatus, nested, err := describer.Describe("root", c.stackName)
+	if err != nil {
+		return nil, err
+	}
+	info.Stacks = []*cfnstack.StackStatus{rootStatus}
+
+	for _, name := range []string{c.cpConfig.NetworkStackName(), c.cpConfig.EtcdStackName(), c.cpConfig.ControlPlaneStackName()} {
+		stackName, ok := nested[naming.FromStackToCfnResource(name)]
+		if !ok {
+			continue
+		}
+		status, _, err := describer.Describe(name, stackName)
+		if err != nil {
+			return nil, err
+		}
+		info.Stacks = append(info.Stacks, status)
+	}
+
+	info.NodePools = []*model.NodePoolStackInfo{}
+	for _, np := range c.cpConfig.NodePools {
+		ref := model.NewNodePoolStackRef(np, c.session)
+		stackName, ok := nested[np.NodePoolLogicalName()]
+		if !ok {
+			npInfo, err := ref.Info()
+			if err != nil {
+				return nil, err
+			}
+			info.NodePools = append(info.NodePools, npInfo)
+			continue
+		}
+		npInfo, err := ref.InfoWithStatus(describer, stackName)
+		if err != nil {
+			return nil, err
+		}
+		info.NodePools = append(info.NodePools, npInfo)
+	}
+
 	return &info, nil
 }
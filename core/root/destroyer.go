@@ -1,17 +1,30 @@
 package root
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/kubernetes-incubator/kube-aws/awsconn"
 	"github.com/kubernetes-incubator/kube-aws/cfnstack"
 	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/naming"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
 )
 
 type DestroyOptions struct {
 	Profile  string
 	AwsDebug bool
 	Force    bool
+	Targets  []string
 }
 
 type ClusterDestroyer interface {
@@ -20,10 +33,13 @@ type ClusterDestroyer interface {
 
 type clusterDestroyerImpl struct {
 	underlying *cfnstack.Destroyer
+	cfg        *config.Config
+	session    *session.Session
+	targets    OperationTargets
 }
 
 func ClusterDestroyerFromFile(configPath string, opts DestroyOptions) (ClusterDestroyer, error) {
-	cfg, err := config.ConfigFromFile(configPath)
+	cfg, err := config.ConfigFromFileWithSecretStore(configPath, secretStore(opts.AwsDebug, opts.Profile))
 	if err != nil {
 		return nil, err
 	}
@@ -36,9 +52,339 @@ func ClusterDestroyerFromFile(configPath string, opts DestroyOptions) (ClusterDe
 	cfnDestroyer := cfnstack.NewDestroyer(cfg.RootStackName(), session, cfg.CloudFormation.RoleARN)
 	return clusterDestroyerImpl{
 		underlying: cfnDestroyer,
+		cfg:        cfg,
+		session:    session,
+		targets:    OperationTargetsFromStringSlice(opts.Targets),
 	}, nil
 }
 
 func (d clusterDestroyerImpl) Destroy() error {
+	if len(d.targets) == 0 || d.targets.IsAll() {
+		return d.destroyAll()
+	}
+	return d.destroyTargets()
+}
+
+// destroyAll deletes the root stack along with all the nested stacks, unless the termination protection of the root stack is enabled
+func (d clusterDestroyerImpl) destroyAll() error {
+	cfSvc := cloudformation.New(d.session)
+	stackName := d.cfg.RootStackName()
+
+	exists, err := checkTerminationProtection(cfSvc, stackName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		// Nothing to protect or retain. Deleting a missing stack succeeds, so that destroy can be run again
+		return d.underlying.Destroy()
+	}
+
+	d.warnRetainedResources(cfSvc, nil)
 	return d.underlying.Destroy()
 }
+
+type StackDescriber interface {
+	DescribeStacks(input *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error)
+}
+
+// checkTerminationProtection returns an error when the stack named `stackName` has termination protection enabled,
+// and whether the stack exists
+func checkTerminationProtection(cf StackDescriber, stackName string) (bool, error) {
+	resp, err := cf.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "ValidationError: Stack with id "+stackName+" does not exist") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to describe stack %s: %v", stackName, err)
+	}
+	for _, s := range resp.Stacks {
+		if aws.BoolValue(s.EnableTerminationProtection) {
+			return true, fmt.Errorf("stack %s has termination protection enabled. Set `cloudformation.terminationProtection` to false in cluster.yaml and run `kube-aws apply` before destroying the cluster", stackName)
+		}
+	}
+	return true, nil
+}
+
+// warnRetainedResources warns about the resources retained after the nested stacks identified by `logicalIDs` are deleted,
+// or after the root stack and all the nested stacks are deleted when `logicalIDs` is nil
+func (d clusterDestroyerImpl) warnRetainedResources(cfSvc *cloudformation.CloudFormation, logicalIDs []string) {
+	retained, err := d.retainedResources(cfSvc, logicalIDs)
+	if err != nil {
+		logger.Warnf("failed to look up the resources retained after the deletion: %v\n", err)
+		return
+	}
+	if len(retained) == 0 {
+		return
+	}
+	logger.Warnf("The following resources will be retained after the deletion. Delete them manually once they are no longer needed:\n")
+	for _, r := range retained {
+		logger.Warnf("  %s\n", r)
+	}
+}
+
+func (d clusterDestroyerImpl) retainedResources(cfSvc *cloudformation.CloudFormation, logicalIDs []string) ([]cfnstack.RetainedResource, error) {
+	rootStackName := d.cfg.RootStackName()
+	stackNames := []string{}
+
+	if logicalIDs == nil {
+		template, err := getStackTemplate(cfSvc, rootStackName)
+		if err != nil {
+			return nil, err
+		}
+		resources := struct {
+			Resources map[string]struct {
+				Type string
+			}
+		}{}
+		if err := json.Unmarshal([]byte(template), &resources); err != nil {
+			return nil, fmt.Errorf("failed to parse the template of stack %s: %v", rootStackName, err)
+		}
+		stackNames = append(stackNames, rootStackName)
+		logicalIDs = []string{}
+		for id, r := range resources.Resources {
+			if r.Type == "AWS::CloudFormation::Stack" {
+				logicalIDs = append(logicalIDs, id)
+			}
+		}
+		sort.Strings(logicalIDs)
+	}
+
+	for _, id := range logicalIDs {
+		stackName, err := getNestedStackName(cfSvc, rootStackName, id)
+		if err != nil {
+			return nil, err
+		}
+		stackNames = append(stackNames, stackName)
+	}
+
+	all := []cfnstack.RetainedResource{}
+	for _, stackName := range stackNames {
+		template, err := getStackTemplate(cfSvc, stackName)
+		if err != nil {
+			return nil, err
+		}
+		retained, err := cfnstack.RetainedResources(template)
+		if err != nil {
+			return nil, fmt.Errorf("stack %s: %v", stackName, err)
+		}
+		for _, r := range retained {
+			resp, err := cfSvc.DescribeStackResource(&cloudformation.DescribeStackResourceInput{StackName: aws.String(stackName), LogicalResourceId: aws.String(r.LogicalID)})
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe resource %s in stack %s: %v", r.LogicalID, stackName, err)
+			}
+			r.StackName = aws.StringValue(resp.StackResourceDetail.StackName)
+			r.PhysicalID = aws.StringValue(resp.StackResourceDetail.PhysicalResourceId)
+			all = append(all, r)
+		}
+	}
+	return all, nil
+}
+
+// destroyTargets deletes the nested stacks for the targets by removing them from the root stack.
+// It refuses to do so while any of the remaining stacks depend on the targets, either by referring to them in the root stack template
+// or by importing values exported from them.
+func (d clusterDestroyerImpl) destroyTargets() error {
+	cfSvc := cloudformation.New(d.session)
+	stackName := d.cfg.RootStackName()
+
+	known := map[string]bool{
+		d.cfg.NetworkStackName():      true,
+		d.cfg.EtcdStackName():         true,
+		d.cfg.ControlPlaneStackName(): true,
+	}
+	for _, np := range d.cfg.NodePools {
+		known[np.NodePoolName] = true
+	}
+
+	template, err := getStackTemplate(cfSvc, stackName)
+	if err != nil {
+		return err
+	}
+	resources := struct {
+		Resources map[string]struct {
+			Type string
+		}
+	}{}
+	if err := json.Unmarshal([]byte(template), &resources); err != nil {
+		return fmt.Errorf("failed to parse the template of stack %s: %v", stackName, err)
+	}
+
+	logicalIDs := []string{}
+	for _, t := range d.targets {
+		if !known[t] {
+			return fmt.Errorf("unknown target %s: specify `all` or any combination of `%s`, `%s`, `%s` and node pool names", t, d.cfg.NetworkStackName(), d.cfg.EtcdStackName(), d.cfg.ControlPlaneStackName())
+		}
+		id := naming.FromStackToCfnResource(t)
+		if _, ok := resources.Resources[id]; !ok {
+			logger.Warnf("skipping %s: it doesn't exist in stack %s\n", t, stackName)
+			continue
+		}
+		logicalIDs = append(logicalIDs, id)
+	}
+	if len(logicalIDs) == 0 {
+		logger.Info("Nothing to destroy")
+		return nil
+	}
+
+	remaining := 0
+	for id, r := range resources.Resources {
+		if r.Type == "AWS::CloudFormation::Stack" && !containsString(logicalIDs, id) {
+			remaining++
+		}
+	}
+	if remaining == 0 {
+		logger.Infof("All the sub-stacks are targeted. Destroying the whole cluster %s ...\n", stackName)
+		return d.destroyAll()
+	}
+
+	deps, err := cfnstack.FindDependents(template, logicalIDs)
+	if err != nil {
+		return err
+	}
+	if len(deps.Resources) > 0 {
+		msgs := []string{}
+		for dependent, ids := range deps.Resources {
+			msgs = append(msgs, fmt.Sprintf("%s depends on %s", dependent, strings.Join(ids, ", ")))
+		}
+		sort.Strings(msgs)
+		return fmt.Errorf("refusing to destroy %s: %s", d.targets.String(), strings.Join(msgs, "; "))
+	}
+
+	outputs := []string{}
+	for name := range deps.Outputs {
+		outputs = append(outputs, name)
+	}
+	sort.Strings(outputs)
+
+	if err := d.ensureExportsNotImported(cfSvc, logicalIDs, outputs); err != nil {
+		return err
+	}
+
+	d.warnRetainedResources(cfSvc, logicalIDs)
+
+	updated, err := cfnstack.RemoveResources(template, logicalIDs, outputs)
+	if err != nil {
+		return err
+	}
+
+	s3URI := api.NewS3Folders(d.cfg.DeploymentSettings.S3URI, d.cfg.ClusterName).ClusterExportedStacks().URI()
+	assetsBuilder, err := cfnstack.NewAssetsBuilder(stackName, s3URI, d.cfg.Region)
+	if err != nil {
+		return err
+	}
+	asset, err := assetsBuilder.Add(REMOTE_STACK_TEMPLATE_FILENAME, updated)
+	if err != nil {
+		return err
+	}
+	templateURL, err := asset.URL()
+	if err != nil {
+		return err
+	}
+
+	provisioner := cfnstack.NewProvisioner(stackName, nil, s3URI, d.cfg.Region, "", d.session, d.cfg.CloudFormation.RoleARN)
+	if err := provisioner.UploadAssets(s3.New(d.session), assetsBuilder.Build()); err != nil {
+		return fmt.Errorf("failed to upload assets: %v", err)
+	}
+
+	logger.With(logger.Fields{"cluster": stackName, "phase": "destroy"}).Infof("Destroying %s in cluster %s ...\n", d.targets.String(), stackName)
+
+	q := make(chan struct{}, 1)
+	defer func() { q <- struct{}{} }()
+	go provisioner.StreamEventsNested(q, cfSvc, stackName, stackName, "destroy", time.Now())
+
+	if _, err := provisioner.UpdateStackAtURLAndWait(cfSvc, templateURL); err != nil {
+		return err
+	}
+
+	logger.Infof("Destroyed %s. Remove them from cluster.yaml too, or they will be recreated by the next `kube-aws apply`\n", d.targets.String())
+	return nil
+}
+
+// ensureExportsNotImported fails when any stack other than the ones being deleted imports values exported from the target nested stacks
+// or the root stack outputs being removed
+func (d clusterDestroyerImpl) ensureExportsNotImported(cfSvc *cloudformation.CloudFormation, logicalIDs []string, rootOutputs []string) error {
+	stackName := d.cfg.RootStackName()
+
+	deleting := map[string]bool{}
+	exports := []string{}
+
+	for _, id := range logicalIDs {
+		physicalID, err := getNestedStackName(cfSvc, stackName, id)
+		if err != nil {
+			return err
+		}
+		resp, err := cfSvc.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(physicalID)})
+		if err != nil {
+			return fmt.Errorf("failed to describe stack %s: %v", physicalID, err)
+		}
+		for _, s := range resp.Stacks {
+			deleting[aws.StringValue(s.StackName)] = true
+			for _, o := range s.Outputs {
+				if name := aws.StringValue(o.ExportName); name != "" {
+					exports = append(exports, name)
+				}
+			}
+		}
+	}
+
+	if len(rootOutputs) > 0 {
+		resp, err := cfSvc.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
+		if err != nil {
+			return fmt.Errorf("failed to describe stack %s: %v", stackName, err)
+		}
+		for _, s := range resp.Stacks {
+			for _, o := range s.Outputs {
+				if name := aws.StringValue(o.ExportName); name != "" && containsString(rootOutputs, aws.StringValue(o.OutputKey)) {
+					exports = append(exports, name)
+				}
+			}
+		}
+	}
+
+	msgs := []string{}
+	for _, export := range exports {
+		importers, err := listImports(cfSvc, export)
+		if err != nil {
+			return err
+		}
+		for _, importer := range importers {
+			if !deleting[importer] {
+				msgs = append(msgs, fmt.Sprintf("stack %s imports %s", importer, export))
+			}
+		}
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("refusing to destroy %s: %s", d.targets.String(), strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// listImports returns the names of the stacks importing the export
+func listImports(cfSvc *cloudformation.CloudFormation, exportName string) ([]string, error) {
+	importers := []string{}
+	input := &cloudformation.ListImportsInput{ExportName: aws.String(exportName)}
+	for {
+		out, err := cfSvc.ListImports(input)
+		if err != nil {
+			if awsErr, ok := err.(awserr.Error); ok && strings.Contains(awsErr.Message(), "is not imported by any stack") {
+				return importers, nil
+			}
+			return nil, fmt.Errorf("failed to list imports of %s: %v", exportName, err)
+		}
+		importers = append(importers, aws.StringValueSlice(out.Imports)...)
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return importers, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, i := range list {
+		if i == s {
+			return true
+		}
+	}
+	return false
+}
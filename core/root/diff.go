@@ -5,29 +5,62 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/aryann/difflib"
+	"github.com/kubernetes-incubator/kube-aws/cfndiff"
 	"github.com/mgutz/ansi"
 	"math"
 	"strings"
 )
 
-func diffJson(current, desired string, context int) (string, error) {
-	var currentBytes bytes.Buffer
-	err := json.Indent(&currentBytes, []byte(current), "", "  ")
-	if err != nil {
-		return "", err
-	}
+// sprintChanges renders every change under a header of the changed resource and property path.
+// Multi-line strings such as decoded userdata files are rendered as line diffs.
+func sprintChanges(changes []cfndiff.Change, context int) (string, error) {
+	outputs := []string{}
+	for _, c := range changes {
+		name := c.Resource
+		if c.Path != "" {
+			name = fmt.Sprintf("%s %s", c.Resource, c.Path)
+		}
+		switch c.Action() {
+		case "add":
+			outputs = append(outputs, fmt.Sprintf("%s\n", ansi.Color("+ "+name, "green")))
+		case "remove":
+			outputs = append(outputs, fmt.Sprintf("%s\n", ansi.Color("- "+name, "red")))
+		default:
+			outputs = append(outputs, fmt.Sprintf("%s\n", ansi.Color("~ "+name, "yellow")))
+		}
 
-	var desiredBytes bytes.Buffer
-	err = json.Indent(&desiredBytes, []byte(desired), "", "  ")
-	if err != nil {
-		return "", err
+		current, err := valueLines(c.Old)
+		if err != nil {
+			return "", err
+		}
+		desired, err := valueLines(c.New)
+		if err != nil {
+			return "", err
+		}
+		outputs = append(outputs, diffLines(current, desired, context))
 	}
+	return strings.Join(outputs, ""), nil
+}
 
-	return diffText(currentBytes.String(), desiredBytes.String(), context)
+func valueLines(v interface{}) ([]string, error) {
+	if v == nil {
+		return []string{}, nil
+	}
+	if s, ok := v.(string); ok {
+		return strings.Split(strings.TrimSuffix(s, "\n"), "\n"), nil
+	}
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"), nil
 }
 
-func diffText(current, desired string, context int) (string, error) {
-	stackDiffs := difflib.Diff(strings.Split(current, "\n"), strings.Split(desired, "\n"))
+func diffLines(current, desired []string, context int) string {
+	stackDiffs := difflib.Diff(current, desired)
 	stackDiffOutputs := []string{}
 	if context >= 0 {
 		distances := calculateDistances(stackDiffs)
@@ -35,7 +68,7 @@ func diffText(current, desired string, context int) (string, error) {
 		for i, r := range stackDiffs {
 			if distances[i] > context {
 				if !omitting {
-					stackDiffOutputs = append(stackDiffOutputs, "...")
+					stackDiffOutputs = append(stackDiffOutputs, "...\n")
 					omitting = true
 				}
 			} else {
@@ -48,7 +81,7 @@ func diffText(current, desired string, context int) (string, error) {
 			stackDiffOutputs = append(stackDiffOutputs, sprintDiffRecord(r))
 		}
 	}
-	return strings.Join(stackDiffOutputs, ""), nil
+	return strings.Join(stackDiffOutputs, "")
 }
 
 // Calculate distance of every diff-line to the closest change
@@ -0,0 +1,115 @@
+package root
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/kubernetes-incubator/kube-aws/cfnstack"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/naming"
+)
+
+const driftDetectionPollInterval = 5 * time.Second
+
+// DriftReport is the result of CloudFormation drift detection on the stacks of the cluster
+type DriftReport struct {
+	Stacks []*cfnstack.StackDrift `json:"stacks" yaml:"stacks"`
+}
+
+// Drifted returns true when any resource in the cluster has been modified or deleted outside kube-aws
+func (r *DriftReport) Drifted() bool {
+	return len(r.DriftedStacks()) > 0
+}
+
+// DriftedStacks returns the names of the stacks containing drifted resources
+func (r *DriftReport) DriftedStacks() []string {
+	names := []string{}
+	for _, s := range r.Stacks {
+		if s.Drifted() {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+func (r *DriftReport) String() string {
+	buf := new(bytes.Buffer)
+
+	for _, s := range r.Stacks {
+		fmt.Fprintf(buf, "Stack %s (%s): %s\n", s.Name, s.StackName, s.Status)
+		if s.Reason != "" {
+			fmt.Fprintf(buf, "  Some resources were not checked: %s\n", s.Reason)
+		}
+		for _, res := range s.Resources {
+			fmt.Fprintf(buf, "  %s %s %s (%s)\n", res.Status, res.Type, res.LogicalID, res.PhysicalID)
+			for _, d := range res.Differences {
+				fmt.Fprintf(buf, "    %s: expected %s, actual %s (%s)\n", d.Path, d.Expected, d.Actual, d.Type)
+			}
+		}
+		fmt.Fprintln(buf)
+	}
+
+	fmt.Fprintf(buf, "Drift detected in %d of %d stacks.\n", len(r.DriftedStacks()), len(r.Stacks))
+
+	return buf.String()
+}
+
+// DetectDrift runs CloudFormation drift detection on the root stack and the nested stacks included in the targets
+func (cl *Cluster) DetectDrift(targets OperationTargets) (*DriftReport, error) {
+	if err := cl.ensureNestedStacksLoaded(); err != nil {
+		return nil, err
+	}
+
+	cfSvc := cloudformation.New(cl.session)
+	stacks := []cfnstack.NamedStack{}
+
+	targets = cl.operationTargetsFromUserInput([]OperationTargets{targets})
+	includeAll := targets.IncludeAll(cl)
+	for _, np := range cl.nodePoolStacks {
+		includeAll = includeAll && targets.IncludeWorker(np.StackName)
+	}
+	if includeAll {
+		stacks = append(stacks, cfnstack.NamedStack{Name: "root", StackName: cl.stackName()})
+	}
+
+	nested := []string{}
+	if targets.IncludeNetwork(cl.networkStack.Config.NetworkStackName()) {
+		nested = append(nested, cl.networkStack.StackName)
+	}
+	if targets.IncludeEtcd(cl.etcdStack.Config.EtcdStackName()) {
+		nested = append(nested, cl.etcdStack.StackName)
+	}
+	if targets.IncludeControlPlane(cl.controlPlaneStack.Config.ControlPlaneStackName()) {
+		nested = append(nested, cl.controlPlaneStack.StackName)
+	}
+	for _, np := range cl.nodePoolStacks {
+		if targets.IncludeWorker(np.StackName) {
+			nested = append(nested, np.StackName)
+		}
+	}
+
+	for _, name := range nested {
+		logicalID := naming.FromStackToCfnResource(name)
+		exists, err := cfnstack.NestedStackExists(cl.context().ProvidedCFInterrogator, cl.stackName(), logicalID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			logger.Debugf("skipping drift detection on %s: it doesn't exist in stack %s", name, cl.stackName())
+			continue
+		}
+		stackName, err := getNestedStackName(cfSvc, cl.stackName(), logicalID)
+		if err != nil {
+			return nil, err
+		}
+		stacks = append(stacks, cfnstack.NamedStack{Name: name, StackName: stackName})
+	}
+
+	drifts, err := cfnstack.DetectDrift(cfSvc, stacks, driftDetectionPollInterval)
+	if err != nil {
+		return nil, err
+	}
+	return &DriftReport{Stacks: drifts}, nil
+}
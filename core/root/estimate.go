@@ -0,0 +1,112 @@
+package root
+
+import (
+	"strconv"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/pricing"
+)
+
+// BillableResourcesFromFile lists the billable resources in the cluster from cluster.yaml.
+// Unlike EstimateCost, it neither renders stack templates nor calls AWS, so AMI IDs and secrets are left unresolved.
+func BillableResourcesFromFile(configPath string) (*pricing.Cluster, error) {
+	cfg, err := config.OfflineConfigFromFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	c := BillableResources(cfg)
+	return &c, nil
+}
+
+// BillableResources lists the resources in the cluster which are accounted for in cost estimates
+func BillableResources(cfg *config.Config) pricing.Cluster {
+	c := pricing.Cluster{
+		Region: cfg.Region.Name,
+		Groups: []pricing.Group{},
+	}
+
+	controller := cfg.Controller
+	c.Groups = append(c.Groups, pricing.Group{
+		Name:         "controller",
+		Kind:         pricing.KindController,
+		InstanceType: controller.InstanceType,
+		MinCount:     controller.MinControllerCount(),
+		MaxCount:     controller.MaxControllerCount(),
+		Volumes:      []pricing.Volume{rootVolume(controller.RootVolume)},
+	})
+
+	etcd := cfg.Etcd
+	etcdVolumes := []pricing.Volume{rootVolume(etcd.RootVolume)}
+	if !etcd.DataVolume.Ephemeral {
+		etcdVolumes = append(etcdVolumes, pricing.Volume{Type: etcd.DataVolume.Type, Size: etcd.DataVolume.Size, IOPS: etcd.DataVolume.IOPS})
+	}
+	c.Groups = append(c.Groups, pricing.Group{
+		Name:         "etcd",
+		Kind:         pricing.KindEtcd,
+		InstanceType: etcd.InstanceType,
+		MinCount:     etcd.Count,
+		MaxCount:     etcd.Count,
+		Volumes:      etcdVolumes,
+	})
+
+	for _, np := range cfg.NodePools {
+		c.Groups = append(c.Groups, nodePoolGroup(np.WorkerNodePool))
+	}
+
+	for _, ngw := range cfg.NATGateways() {
+		if ngw.ManageNATGateway() {
+			c.NATGateways++
+		}
+	}
+
+	for _, e := range cfg.APIEndpoints {
+		if !e.LoadBalancer.ManageELB() {
+			continue
+		}
+		if e.LoadBalancer.NetworkLoadBalancer() {
+			c.NetworkLoadBalancers++
+		} else {
+			c.ClassicLoadBalancers++
+		}
+	}
+
+	return c
+}
+
+func nodePoolGroup(np api.WorkerNodePool) pricing.Group {
+	g := pricing.Group{
+		Name:         np.NodePoolName,
+		Kind:         pricing.KindNodePool,
+		InstanceType: np.InstanceType,
+		MinCount:     np.MinCount(),
+		MaxCount:     np.MaxCount(),
+		Volumes:      []pricing.Volume{rootVolume(np.RootVolume)},
+	}
+
+	if !np.SpotFleet.Enabled() {
+		return g
+	}
+
+	// Spot fleets are sized in units of capacity, and spot prices are specified per unit hour
+	g.MinCount = np.SpotFleet.TargetCapacity
+	g.MaxCount = np.SpotFleet.TargetCapacity
+	for _, spec := range np.SpotFleet.LaunchSpecifications {
+		spotPrice := spec.SpotPrice
+		if spotPrice == "" {
+			spotPrice = np.SpotFleet.SpotPrice
+		}
+		unitPrice, _ := strconv.ParseFloat(spotPrice, 64)
+		g.SpotFleet = append(g.SpotFleet, pricing.SpotInstance{
+			InstanceType:     spec.InstanceType,
+			WeightedCapacity: spec.WeightedCapacity,
+			SpotPrice:        unitPrice * float64(spec.WeightedCapacity),
+			Volumes:          []pricing.Volume{rootVolume(spec.RootVolume)},
+		})
+	}
+	return g
+}
+
+func rootVolume(v api.RootVolume) pricing.Volume {
+	return pricing.Volume{Type: v.Type, Size: v.Size, IOPS: v.IOPS}
+}
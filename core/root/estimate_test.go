@@ -0,0 +1,51 @@
+package root
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-aws/pricing"
+	"github.com/kubernetes-incubator/kube-aws/test/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type noNetwork struct{}
+
+func (noNetwork) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("no network access to " + req.URL.String())
+}
+
+// withoutNetwork fails every HTTP request sent through the default transport, e.g. AMI feed lookups and AWS API calls, while `fn` runs
+func withoutNetwork(fn func()) {
+	transport := http.DefaultTransport
+	http.DefaultTransport = noNetwork{}
+	defer func() { http.DefaultTransport = transport }()
+	fn()
+}
+
+func TestBillableResourcesFromFileWithoutNetwork(t *testing.T) {
+	helper.WithTempDir(func(dir string) {
+		// Neither the AMIs of the node pools nor the secret are resolved to estimate the cost
+		configPath := writeAMIClusterYaml(t, dir, amiClusterYaml+`controller:
+  customFiles:
+  - path: /etc/token
+    permissions: 0600
+    content: '{{ssm "/mycluster/token"}}'
+`)
+
+		withoutNetwork(func() {
+			c, err := BillableResourcesFromFile(configPath)
+			require.NoError(t, err)
+			assert.Equal(t, "us-west-2", c.Region)
+
+			var names []string
+			for _, g := range c.Groups {
+				names = append(names, g.Name)
+			}
+			assert.Equal(t, []string{"controller", "etcd", "flatcar", "al2", "beta"}, names)
+			assert.Equal(t, pricing.KindController, c.Groups[0].Kind)
+		})
+	})
+}
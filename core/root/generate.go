@@ -6,22 +6,49 @@ import (
 	"text/template"
 
 	"github.com/gobuffalo/packr"
+	"github.com/kubernetes-incubator/kube-aws/ami"
 	"github.com/kubernetes-incubator/kube-aws/builtin"
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
 	"github.com/kubernetes-incubator/kube-aws/filegen"
+	"github.com/kubernetes-incubator/kube-aws/hook"
 	"github.com/kubernetes-incubator/kube-aws/pkg/api"
 	"github.com/kubernetes-incubator/kube-aws/pkg/model"
 	"os"
 	"strings"
 )
 
-func RenderStack(configPath string) error {
+func RenderStack(configPath string) (err error) {
 
 	c, err := model.ClusterFromFile(configPath)
 	if err != nil {
 		return err
 	}
-	config, err := model.Compile(c, api.ClusterOptions{})
-	kubeconfig, err := generateKubeconfig(config)
+
+	// The AMI pinned to the lockfile is used instead of the latest one, so that renders are reproducible
+	lock, err := ami.LoadLockfile(config.LockfilePath(configPath))
+	if err != nil {
+		return err
+	}
+	if id, ok := lock.Find(c.AMIQuery()); ok && c.AmiId == "" {
+		c.AmiId = id
+	}
+
+	dir := hookDir(configPath)
+	ctx := hook.Context{Operation: "render", ClusterName: c.ClusterName, Targets: AllOperationTargetsAsStringSlice()}
+	defer func() {
+		if err != nil {
+			runFailureHooks(c.Hooks.OnFailure, ctx, dir, err)
+		}
+	}()
+
+	if err := hook.Run(hook.PreRender, c.Hooks.PreRender, ctx, dir); err != nil {
+		return err
+	}
+	cfg, err := model.Compile(c, api.ClusterOptions{})
+	if err != nil {
+		return err
+	}
+	kubeconfig, err := generateKubeconfig(cfg)
 	if err != nil {
 		return err
 	}
@@ -55,7 +82,25 @@ func RenderStack(configPath string) error {
 		return err
 	}
 
-	return nil
+	if len(c.Hooks.PostRender) == 0 {
+		return nil
+	}
+
+	// The stack templates just rendered are rendered offline for the postRender hooks, as credentials may not have been rendered yet
+	opts := NewOptions(false, false)
+	opts.Offline = true
+	cl, err := LoadClusterFromFile(configPath, opts, false)
+	if err != nil {
+		return err
+	}
+	if err := cl.ensureNestedStacksLoaded(); err != nil {
+		return err
+	}
+	assets, err := cl.generateAssets(cl.allOperationTargets())
+	if err != nil {
+		return err
+	}
+	return cl.runPostRenderHooks(ctx, assets)
 }
 
 func generateKubeconfig(clusterConfig *model.Config) ([]byte, error) {
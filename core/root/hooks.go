@@ -0,0 +1,190 @@
+package root
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/kubernetes-incubator/kube-aws/cfnstack"
+	"github.com/kubernetes-incubator/kube-aws/hook"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/pkg/model"
+)
+
+// hookContext returns the context passed to the hooks of `operation` on the sub-stacks `targets`
+func (cl *Cluster) hookContext(operation string, targets OperationTargets) hook.Context {
+	return hook.Context{Operation: operation, ClusterName: cl.Cfg.ClusterName, Targets: []string(targets)}
+}
+
+// runHooks runs the hooks in the directory containing cluster.yaml
+func (cl *Cluster) runHooks(point string, hooks []api.Hook, ctx hook.Context) error {
+	return hook.Run(point, hooks, ctx, hookDir(cl.configPath))
+}
+
+func (cl *Cluster) runFailureHooks(ctx hook.Context, err error) {
+	runFailureHooks(cl.Cfg.Hooks.OnFailure, ctx, hookDir(cl.configPath), err)
+}
+
+// runFailureHooks runs the onFailure hooks for `err`. Failures of the hooks themselves are logged but not returned,
+// so that they don't hide `err`.
+func runFailureHooks(hooks []api.Hook, ctx hook.Context, dir string, err error) {
+	ctx.Error = err.Error()
+	if herr := hook.Run(hook.OnFailure, hooks, ctx, dir); herr != nil {
+		logger.Warnf("%v\n", herr)
+	}
+}
+
+func hookDir(configPath string) string {
+	if configPath == "" {
+		return "."
+	}
+	return filepath.Dir(configPath)
+}
+
+// renderAssetsWithHooks renders assets with `render` between the preRender and postRender hooks.
+// The hooks run once per cluster, so that e.g. `apply` validating the stacks before applying them doesn't run them twice.
+func (cl *Cluster) renderAssetsWithHooks(ctx hook.Context, render func() (cfnstack.Assets, error)) (cfnstack.Assets, error) {
+	if cl.renderHooksDone {
+		return render()
+	}
+	cl.renderHooksDone = true
+
+	if err := cl.runHooks(hook.PreRender, cl.Cfg.Hooks.PreRender, ctx); err != nil {
+		return nil, err
+	}
+
+	assets, err := render()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cl.runPostRenderHooks(ctx, assets); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// runPostRenderHooks writes the stack templates in `assets` to a temporary directory with secrets redacted, and runs the postRender hooks
+// to inspect them
+func (cl *Cluster) runPostRenderHooks(ctx hook.Context, assets cfnstack.Assets) error {
+	if len(cl.Cfg.Hooks.PostRender) == 0 {
+		return nil
+	}
+
+	dir, err := ioutil.TempDir("", "kube-aws-templates")
+	if err != nil {
+		return fmt.Errorf("failed to create directory for rendered templates: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx.Templates = map[string]string{}
+	for id, a := range assets.AsMap() {
+		if id.Filename != model.STACK_TEMPLATE_FILENAME {
+			continue
+		}
+		name := id.StackName
+		if name == cl.stackName() {
+			name = "root"
+		}
+		path := filepath.Join(dir, name+".json")
+		if err := ioutil.WriteFile(path, []byte(cl.redactSecrets(path, a.Content)), 0600); err != nil {
+			return fmt.Errorf("failed to write %s stack template: %v", name, err)
+		}
+		ctx.Templates[name] = path
+	}
+
+	return cl.runHooks(hook.PostRender, cl.Cfg.Hooks.PostRender, ctx)
+}
+
+// runPreApplyHooks runs the preApply hooks with the summary of the changes to the existing stacks
+func (cl *Cluster) runPreApplyHooks(ctx hook.Context, targets OperationTargets, exists bool) error {
+	if len(cl.Cfg.Hooks.PreApply) == 0 {
+		return nil
+	}
+	if exists {
+		diff, err := cl.diffSummary(targets)
+		if err != nil {
+			logger.Warnf("failed to summarize changes for %s hooks: %v\n", hook.PreApply, err)
+		}
+		ctx.Diff = diff
+	}
+	return cl.runHooks(hook.PreApply, cl.Cfg.Hooks.PreApply, ctx)
+}
+
+// runPostApplyHooks runs the postApply hooks with the outputs of the stacks. Failures are logged but not returned
+// as the stacks have already been applied.
+func (cl *Cluster) runPostApplyHooks(ctx hook.Context, cfSvc *cloudformation.CloudFormation) {
+	if len(cl.Cfg.Hooks.PostApply) == 0 {
+		return
+	}
+	outputs, err := cl.stackOutputs(cfSvc)
+	if err != nil {
+		logger.Warnf("failed to get stack outputs for %s hooks: %v\n", hook.PostApply, err)
+	}
+	ctx.Outputs = outputs
+	if err := cl.runHooks(hook.PostApply, cl.Cfg.Hooks.PostApply, ctx); err != nil {
+		logger.Warnf("%v\n", err)
+	}
+}
+
+// diffSummary returns the number of changes and the changed resources per stack
+func (cl *Cluster) diffSummary(targets OperationTargets) ([]hook.StackDiff, error) {
+	diffs, err := cl.Diff(targets, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := []hook.StackDiff{}
+	for _, d := range diffs {
+		resources := []string{}
+		seen := map[string]bool{}
+		for _, c := range d.Changes {
+			if !seen[c.Resource] {
+				seen[c.Resource] = true
+				resources = append(resources, c.Resource)
+			}
+		}
+		sort.Strings(resources)
+		summary = append(summary, hook.StackDiff{Target: d.Target, Changes: len(d.Changes), Resources: resources})
+	}
+	return summary, nil
+}
+
+// stackOutputs returns the outputs of the root stack and the nested stacks keyed by stack name
+func (cl *Cluster) stackOutputs(cfSvc *cloudformation.CloudFormation) (map[string]map[string]string, error) {
+	outputs := map[string]map[string]string{}
+
+	describe := func(name, stackName string) error {
+		resp, err := cfSvc.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
+		if err != nil {
+			return fmt.Errorf("failed to describe stack %s: %v", stackName, err)
+		}
+		values := map[string]string{}
+		for _, s := range resp.Stacks {
+			for _, o := range s.Outputs {
+				values[aws.StringValue(o.OutputKey)] = aws.StringValue(o.OutputValue)
+			}
+		}
+		outputs[name] = values
+		return nil
+	}
+
+	if err := describe("root", cl.stackName()); err != nil {
+		return nil, err
+	}
+	for _, s := range append([]*model.Stack{cl.networkStack, cl.etcdStack, cl.controlPlaneStack}, cl.nodePoolStacks...) {
+		stackName, err := getNestedStackName(cfSvc, cl.stackName(), s.NestedStackName())
+		if err != nil {
+			return nil, err
+		}
+		if err := describe(s.StackName, stackName); err != nil {
+			return nil, err
+		}
+	}
+	return outputs, nil
+}
@@ -0,0 +1,67 @@
+package root
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/cfnstack"
+	"github.com/kubernetes-incubator/kube-aws/pkg/model"
+)
+
+// ValidateStackOffline renders all the stack templates and userdata locally, and checks that every reference in the stack templates
+// resolves against the sibling stacks. Unlike ValidateStack, nothing is uploaded to S3 nor validated by CloudFormation,
+// and the checks against existing AWS resources are skipped.
+func (cl *Cluster) ValidateStackOffline() (_ string, err error) {
+	if !cl.opts.Offline {
+		return "", fmt.Errorf("[bug] the cluster must be loaded offline to be validated offline")
+	}
+
+	if err := cl.ensureNestedStacksLoaded(); err != nil {
+		return "", err
+	}
+
+	ctx := cl.hookContext("validate", cl.allOperationTargets())
+	defer func() {
+		if err != nil {
+			cl.runFailureHooks(ctx, err)
+		}
+	}()
+
+	assets, err := cl.renderAssetsWithHooks(ctx, func() (cfnstack.Assets, error) {
+		return cl.generateAssets(cl.allOperationTargets())
+	})
+	if err != nil {
+		return "", err
+	}
+
+	root, err := assets.FindAssetByStackAndFileName(cl.stackName(), REMOTE_STACK_TEMPLATE_FILENAME)
+	if err != nil {
+		return "", fmt.Errorf("failed to find root stack template: %v", err)
+	}
+
+	nested := map[string]string{}
+	for id, a := range assets.AsMap() {
+		if id.StackName == cl.stackName() || id.Filename != model.STACK_TEMPLATE_FILENAME {
+			continue
+		}
+		url, err := a.URL()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate %s stack template url: %v", id.StackName, err)
+		}
+		nested[url] = a.Content
+	}
+
+	r := cfnstack.CheckReferences(cl.stackName(), root.Content, nested)
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "Rendered the root stack and %d nested stacks.\n", len(nested))
+	if len(r.Unchecked) > 0 {
+		fmt.Fprintf(buf, "The following imports can't be checked offline:\n  %s\n", strings.Join(r.Unchecked, "\n  "))
+	}
+
+	if !r.OK() {
+		return buf.String(), fmt.Errorf("unresolved references found in stack templates:\n  %s", strings.Join(r.Errors, "\n  "))
+	}
+	return buf.String(), nil
+}
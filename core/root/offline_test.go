@@ -0,0 +1,61 @@
+package root
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-aws/filereader/texttemplate"
+	"github.com/kubernetes-incubator/kube-aws/test/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const offlineClusterYaml = `clusterName: mycluster
+keyName: mykey
+region: us-west-2
+availabilityZone: us-west-2a
+s3URI: s3://mybucket/mydir
+kmsKeyArn: "arn:aws:kms:us-west-2:xxxxxxxxx:key/xxxxxxxxxxxxxxxxxxx"
+amiId: ami-1234
+apiEndpoints:
+- name: public
+  dnsName: mycluster.example.com
+  loadBalancer:
+    hostedZone:
+      id: hostedzone-xxxx
+worker:
+  nodePools:
+  - name: pool1
+etcd:
+  version: v3.3.17
+kubeAwsPlugins:
+  dashboard:
+    enabled: true
+`
+
+func TestValidateStackOfflineWithoutCredentials(t *testing.T) {
+	helper.WithTempDir(func(dir string) {
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(dir))
+		defer os.Chdir(wd)
+
+		require.NoError(t, ioutil.WriteFile("cluster.yaml", []byte(offlineClusterYaml), 0644))
+		require.NoError(t, RenderStack("cluster.yaml"))
+		_, err = os.Stat("credentials")
+		require.True(t, os.IsNotExist(err), "credentials must not have been rendered")
+
+		// The dashboard plugin inserts credentials/dashboard.pem and more into its manifests
+		opts := NewOptions(false, false)
+		opts.Offline = true
+		defer texttemplate.SetOffline(false)
+		withoutNetwork(func() {
+			cl, err := LoadClusterFromFile("cluster.yaml", opts, false)
+			require.NoError(t, err)
+			report, err := cl.ValidateStackOffline()
+			require.NoError(t, err)
+			assert.Contains(t, report, "Rendered the root stack and 4 nested stacks.")
+		})
+	})
+}
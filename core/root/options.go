@@ -1,6 +1,10 @@
 package root
 
-import "github.com/kubernetes-incubator/kube-aws/core/root/defaults"
+import (
+	"time"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root/defaults"
+)
 
 type options struct {
 	AssetsDir                         string
@@ -15,6 +19,12 @@ type options struct {
 	AWSProfile                        string
 	SkipWait                          bool
 	PrettyPrint                       bool
+	// Offline loads and renders the cluster without accessing AWS
+	Offline bool
+	// Timeout is how long to wait for the cluster to get created or updated. Zero waits forever
+	Timeout time.Duration
+	// ConfirmCancel is asked whether to cancel the update of the cluster when waiting for it is interrupted or times out
+	ConfirmCancel func(reason string) bool
 }
 
 func NewOptions(prettyPrint bool, skipWait bool, awsProfile ...string) options {
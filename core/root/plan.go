@@ -0,0 +1,448 @@
+package root
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/kubernetes-incubator/kube-aws/cfnstack"
+	"github.com/kubernetes-incubator/kube-aws/fingerprint"
+	"github.com/kubernetes-incubator/kube-aws/hook"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/pkg/model"
+	"github.com/kubernetes-incubator/kube-aws/revision"
+)
+
+// Plan is the set of changes to be made to the cluster by a subsequent `apply --plan`.
+// It is backed by a change set created for the root stack. Changes to nested stacks are analyzed with
+// change sets created and deleted while planning, as CloudFormation executes nested stack updates from the root stack.
+type Plan struct {
+	ClusterName   string            `json:"clusterName"`
+	StackName     string            `json:"stackName"`
+	ChangeSetID   string            `json:"changeSetId"`
+	ChangeSetType string            `json:"changeSetType"`
+	Targets       []string          `json:"targets"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	Stacks        []*StackPlan      `json:"stacks"`
+	Templates     []PlannedTemplate `json:"templates"`
+}
+
+// StackPlan is the list of resource changes planned for either the root stack or one of the nested stacks
+type StackPlan struct {
+	Target    string                    `json:"target"`
+	StackName string                    `json:"stackName"`
+	Changes   []cfnstack.ResourceChange `json:"changes"`
+}
+
+// PlannedTemplate records a nested stack template referenced from the root change set.
+// CloudFormation reads nested stack templates from S3 when the change set is executed, so they are fingerprinted
+// to detect templates overwritten after the plan has been made.
+type PlannedTemplate struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	SHA256 string `json:"sha256"`
+}
+
+// HasChanges returns true when applying the plan would change any resource
+func (p *Plan) HasChanges() bool {
+	for _, s := range p.Stacks {
+		if len(s.Changes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Replacements returns "<target>/<logical id>" of every resource to be replaced by the plan
+func (p *Plan) Replacements() []string {
+	rs := []string{}
+	for _, s := range p.Stacks {
+		for _, c := range s.Changes {
+			if c.RequiresReplacement() {
+				rs = append(rs, fmt.Sprintf("%s/%s", s.Target, c.LogicalID))
+			}
+		}
+	}
+	return rs
+}
+
+// hookDiff returns the number of changes and the changed resources per stack recorded in the plan, given to preApply hooks
+func (p *Plan) hookDiff() []hook.StackDiff {
+	summary := []hook.StackDiff{}
+	for _, s := range p.Stacks {
+		resources := []string{}
+		for _, c := range s.Changes {
+			resources = append(resources, c.LogicalID)
+		}
+		sort.Strings(resources)
+		summary = append(summary, hook.StackDiff{Target: s.Target, Changes: len(s.Changes), Resources: resources})
+	}
+	return summary
+}
+
+func (p *Plan) String() string {
+	buf := new(bytes.Buffer)
+
+	add, modify, remove := 0, 0, 0
+	for _, s := range p.Stacks {
+		fmt.Fprintf(buf, "Stack %s (%s):\n", s.Target, s.StackName)
+		if len(s.Changes) == 0 {
+			fmt.Fprintf(buf, "  No changes\n\n")
+			continue
+		}
+
+		w := new(tabwriter.Writer)
+		w.Init(buf, 0, 8, 2, ' ', 0)
+		fmt.Fprintf(w, "  ACTION\tLOGICAL ID\tTYPE\tREPLACEMENT\tCAUSED BY\n")
+		for _, c := range s.Changes {
+			replacement := c.Replacement
+			if replacement == "" {
+				replacement = "-"
+			}
+			causedBy := strings.Join(c.CausingProperties, ",")
+			if causedBy == "" {
+				causedBy = "-"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", c.Action, c.LogicalID, c.ResourceType, replacement, causedBy)
+
+			switch c.Action {
+			case cloudformation.ChangeActionAdd:
+				add++
+			case cloudformation.ChangeActionModify:
+				modify++
+			case cloudformation.ChangeActionRemove:
+				remove++
+			}
+		}
+		w.Flush()
+		fmt.Fprintln(buf)
+	}
+
+	fmt.Fprintf(buf, "Plan: %d to add, %d to modify, %d to remove, %d to be replaced.\n", add, modify, remove, len(p.Replacements()))
+
+	return buf.String()
+}
+
+// WriteToFile saves the plan as JSON so that it can be executed later with `apply --plan`
+func (p *Plan) WriteToFile(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %v", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write plan to %s: %v", path, err)
+	}
+	return nil
+}
+
+// ReadPlanFromFile loads the plan previously saved by `plan --out`
+func ReadPlanFromFile(path string) (*Plan, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan from %s: %v", path, err)
+	}
+	p := &Plan{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %v", path, err)
+	}
+	if p.ChangeSetID == "" {
+		return nil, fmt.Errorf("invalid plan %s: missing change set id", path)
+	}
+	return p, nil
+}
+
+// Plan uploads the assets for the specified targets and creates a change set for the root stack without executing it.
+// Every nested stack included in the targets is analyzed with a temporary change set so that the plan lists
+// the resources to be added, modified, replaced or removed within it.
+func (cl *Cluster) Plan(opts OperationTargets) (_ *Plan, err error) {
+	if err := cl.ensureNestedStacksLoaded(); err != nil {
+		return nil, err
+	}
+
+	cfSvc := cloudformation.New(cl.session)
+
+	targets := cl.operationTargetsFromUserInput([]OperationTargets{opts})
+
+	ctx := cl.hookContext("plan", targets)
+	defer func() {
+		if err != nil {
+			cl.runFailureHooks(ctx, err)
+		}
+	}()
+
+	status, err := cl.rootStackStatus(cfSvc)
+	if err != nil {
+		return nil, err
+	}
+	changeSetType := cloudformation.ChangeSetTypeUpdate
+	if status == "" || status == cloudformation.StackStatusReviewInProgress {
+		changeSetType = cloudformation.ChangeSetTypeCreate
+	}
+
+	assets, err := cl.renderAssetsWithHooks(ctx, func() (cfnstack.Assets, error) {
+		return cl.generateAssets(targets)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cl.uploadAssets(assets); err != nil {
+		return nil, err
+	}
+
+	rootTemplateURL, err := cl.extractRootStackTemplateURL(assets)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		ClusterName:   cl.Cfg.ClusterName,
+		StackName:     cl.stackName(),
+		ChangeSetType: changeSetType,
+		Targets:       targets,
+		CreatedAt:     time.Now().UTC(),
+		Stacks:        []*StackPlan{},
+		Templates:     []PlannedTemplate{},
+	}
+
+	changeSetName := fmt.Sprintf("kube-aws-plan-%s", plan.CreatedAt.Format("20060102150405"))
+
+	logger.Infof("Creating change set %s for stack %s ...\n", changeSetName, cl.stackName())
+	changeSetID, err := cl.stackProvisioner().CreateChangeSetAtURL(cfSvc, rootTemplateURL, changeSetName, changeSetType, nil)
+	if err != nil {
+		return nil, err
+	}
+	rootChangeSet, err := cfnstack.WaitForChangeSet(cfSvc, changeSetID)
+	if err != nil {
+		return nil, err
+	}
+	plan.ChangeSetID = changeSetID
+	plan.Stacks = append(plan.Stacks, &StackPlan{Target: "root", StackName: cl.stackName(), Changes: rootChangeSet.Changes})
+
+	for _, stack := range cl.plannedNestedStacks(targets) {
+		a, err := assets.FindAssetByStackAndFileName(stack.StackName, REMOTE_STACK_TEMPLATE_FILENAME)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find assets for stack %s: %v", stack.StackName, err)
+		}
+		plan.Templates = append(plan.Templates, PlannedTemplate{Bucket: a.Bucket, Key: a.Key, SHA256: fingerprint.SHA256(a.Content)})
+
+		// Nested stacks being created by the root change set have nothing to compare with
+		if changeSetType == cloudformation.ChangeSetTypeCreate {
+			continue
+		}
+
+		url, err := a.URL()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate %s stack template url: %v", stack.StackName, err)
+		}
+
+		stackPlan, err := cl.planNestedStack(cfSvc, stack, url, changeSetName)
+		if err != nil {
+			return nil, err
+		}
+		if stackPlan != nil {
+			plan.Stacks = append(plan.Stacks, stackPlan)
+		}
+	}
+
+	return plan, nil
+}
+
+// plannedNestedStacks returns the nested stacks included in the targets in the order of network, etcd, control-plane and node pools
+func (cl *Cluster) plannedNestedStacks(targets OperationTargets) []*model.Stack {
+	stacks := []*model.Stack{}
+	if targets.IncludeNetwork(cl.networkStack.Config.NetworkStackName()) {
+		stacks = append(stacks, cl.networkStack)
+	}
+	if targets.IncludeEtcd(cl.etcdStack.Config.EtcdStackName()) {
+		stacks = append(stacks, cl.etcdStack)
+	}
+	if targets.IncludeControlPlane(cl.controlPlaneStack.Config.ControlPlaneStackName()) {
+		stacks = append(stacks, cl.controlPlaneStack)
+	}
+	for _, np := range cl.nodePoolStacks {
+		if targets.IncludeWorker(np.StackName) {
+			stacks = append(stacks, np)
+		}
+	}
+	return stacks
+}
+
+// planNestedStack analyzes the changes to an existing nested stack with a change set which is deleted right after being described.
+// It returns nil when the nested stack is yet to be created by the root stack.
+func (cl *Cluster) planNestedStack(cfSvc *cloudformation.CloudFormation, stack *model.Stack, templateURL string, changeSetName string) (*StackPlan, error) {
+	resp, err := cfSvc.DescribeStackResources(&cloudformation.DescribeStackResourcesInput{
+		StackName:         aws.String(cl.stackName()),
+		LogicalResourceId: aws.String(stack.NestedStackName()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe nested stack %s: %v", stack.NestedStackName(), err)
+	}
+	if len(resp.StackResources) == 0 || aws.StringValue(resp.StackResources[0].PhysicalResourceId) == "" {
+		return nil, nil
+	}
+	stackID := aws.StringValue(resp.StackResources[0].PhysicalResourceId)
+
+	current, err := cfSvc.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(stackID)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe stack %s: %v", stackID, err)
+	}
+	if len(current.Stacks) == 0 {
+		return nil, fmt.Errorf("stack %s not found", stackID)
+	}
+	nested := current.Stacks[0]
+
+	// Parameters are passed from the root stack and therefore can't change without the root stack itself being changed
+	params := []*cloudformation.Parameter{}
+	for _, p := range nested.Parameters {
+		params = append(params, &cloudformation.Parameter{ParameterKey: p.ParameterKey, UsePreviousValue: aws.Bool(true)})
+	}
+
+	// Tags are left as is by passing none
+	provisioner := cfnstack.NewProvisioner(
+		aws.StringValue(nested.StackName),
+		nil,
+		cl.s3URI(),
+		cl.controlPlaneStack.Region,
+		"",
+		cl.session,
+		cl.controlPlaneStack.Config.CloudFormation.RoleARN,
+	)
+
+	logger.Infof("Analyzing changes to nested stack %s ...\n", aws.StringValue(nested.StackName))
+	changeSetID, err := provisioner.CreateChangeSetAtURL(cfSvc, templateURL, changeSetName, cloudformation.ChangeSetTypeUpdate, params)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cfnstack.DeleteChangeSet(cfSvc, changeSetID); err != nil {
+			logger.Warnf("%v", err)
+		}
+	}()
+
+	cs, err := cfnstack.WaitForChangeSet(cfSvc, changeSetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StackPlan{Target: stack.StackName, StackName: aws.StringValue(nested.StackName), Changes: cs.Changes}, nil
+}
+
+// rootStackStatus returns the status of the root stack, or an empty string when it doesn't exist yet
+func (cl *Cluster) rootStackStatus(cfSvc *cloudformation.CloudFormation) (string, error) {
+	exists, err := cfnstack.StackExists(cfSvc, cl.stackName())
+	if err != nil {
+		return "", fmt.Errorf("can't lookup AWS CloudFormation stacks: %v", err)
+	}
+	if !exists {
+		return "", nil
+	}
+	resp, err := cfSvc.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(cl.stackName())})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe stack %s: %v", cl.stackName(), err)
+	}
+	if len(resp.Stacks) == 0 {
+		return "", nil
+	}
+	return aws.StringValue(resp.Stacks[0].StackStatus), nil
+}
+
+// ApplyPlan executes the root stack change set recorded in the plan and waits for the stack to be created or updated.
+// The preApply, postApply and onFailure hooks run as they do for `apply`, with the changes recorded in the plan as the diff
+func (cl *Cluster) ApplyPlan(plan *Plan) (err error) {
+	if plan.ClusterName != cl.Cfg.ClusterName {
+		return fmt.Errorf("the plan is for cluster %s but the current cluster is %s", plan.ClusterName, cl.Cfg.ClusterName)
+	}
+
+	if err := cl.ensureNestedStacksLoaded(); err != nil {
+		return err
+	}
+
+	ctx := cl.hookContext("apply", plan.Targets)
+	defer func() {
+		if err != nil {
+			cl.runFailureHooks(ctx, err)
+		}
+	}()
+
+	files, err := cl.verifyPlannedTemplates(plan)
+	if err != nil {
+		return err
+	}
+
+	if len(cl.Cfg.Hooks.PreApply) > 0 {
+		if plan.ChangeSetType == cloudformation.ChangeSetTypeUpdate {
+			ctx.Diff = plan.hookDiff()
+		}
+		if err := cl.runHooks(hook.PreApply, cl.Cfg.Hooks.PreApply, ctx); err != nil {
+			return err
+		}
+	}
+
+	cfSvc := cloudformation.New(cl.session)
+
+	logger.Infof("Applying plan %s to cluster %s ...\n", plan.ChangeSetID, plan.ClusterName)
+
+	if err := cl.executePlan(cfSvc, plan); err != nil {
+		return err
+	}
+
+	// The root stack template is embedded in the change set rather than stored at a fixed location
+	if rootTemplate, err := getStackTemplate(cfSvc, cl.stackName()); err != nil {
+		logger.Warnf("failed to archive revision: %v\n", err)
+	} else {
+		files[revision.StackFilePath(cl.stackName(), REMOTE_STACK_TEMPLATE_FILENAME)] = rootTemplate
+		cl.archiveRevision(files, plan.Targets)
+	}
+
+	cl.runPostApplyHooks(ctx, cfSvc)
+	return nil
+}
+
+func (cl *Cluster) executePlan(cfSvc *cloudformation.CloudFormation, plan *Plan) error {
+	phase := "update"
+	if plan.ChangeSetType == cloudformation.ChangeSetTypeCreate {
+		phase = "create"
+	}
+	q := cl.streamLogsAndEvents(cfSvc, phase)
+	defer func() { q <- struct{}{} }()
+
+	if err := cl.stackProvisioner().ExecuteChangeSetAndWait(cfSvc, plan.ChangeSetID, plan.ChangeSetType == cloudformation.ChangeSetTypeCreate); err != nil {
+		return err
+	}
+	return cl.protectStacks(cfSvc)
+}
+
+// verifyPlannedTemplates ensures that none of the nested stack templates has been overwritten since the plan was made.
+// It returns the templates keyed by their paths in a revision.
+func (cl *Cluster) verifyPlannedTemplates(plan *Plan) (map[string]string, error) {
+	s3Svc := s3.New(cl.session)
+	files := map[string]string{}
+	for _, t := range plan.Templates {
+		out, err := s3Svc.GetObject(&s3.GetObjectInput{Bucket: aws.String(t.Bucket), Key: aws.String(t.Key)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get s3://%s/%s: %v", t.Bucket, t.Key, err)
+		}
+		data, err := ioutil.ReadAll(out.Body)
+		out.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read s3://%s/%s: %v", t.Bucket, t.Key, err)
+		}
+		if fingerprint.SHA256(string(data)) != t.SHA256 {
+			return nil, fmt.Errorf("s3://%s/%s has been changed since the plan was made. Please run `kube-aws plan` again", t.Bucket, t.Key)
+		}
+		// Keys of stack templates end with STACK_NAME/stack.json
+		stack := path.Base(path.Dir(t.Key))
+		files[revision.StackFilePath(stack, path.Base(t.Key))] = string(data)
+	}
+	return files, nil
+}
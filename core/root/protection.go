@@ -0,0 +1,113 @@
+package root
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/pkg/model"
+)
+
+// StackProtector updates the termination protection and the stack policies of stacks
+type StackProtector interface {
+	StackResourceDescriber
+	DescribeStacks(input *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error)
+	UpdateTerminationProtection(input *cloudformation.UpdateTerminationProtectionInput) (*cloudformation.UpdateTerminationProtectionOutput, error)
+	GetStackPolicy(input *cloudformation.GetStackPolicyInput) (*cloudformation.GetStackPolicyOutput, error)
+	SetStackPolicy(input *cloudformation.SetStackPolicyInput) (*cloudformation.SetStackPolicyOutput, error)
+}
+
+// rootStackPolicyBody returns the stack policy of the root stack configured in cluster.yaml
+func (cl *Cluster) rootStackPolicyBody() string {
+	return cl.Cfg.CloudFormation.StackPolicies[api.RootStackPolicyKey].Body()
+}
+
+// protectStacks enables or disables the termination protection of the root stack, and sets the stack policies of the root stack
+// and all the nested stacks, as configured in cluster.yaml.
+// Only what differs from the current settings is updated, so that clusters configuring neither of them don't need the permissions
+// to update them. Stacks without any policy configured are given the policy allowing all updates when they have another policy,
+// so that removing a policy from cluster.yaml takes effect too.
+func (cl *Cluster) protectStacks(cfSvc StackProtector) error {
+	rootStackName := cl.stackName()
+	if err := cl.updateTerminationProtection(cfSvc, rootStackName); err != nil {
+		return err
+	}
+
+	stackNames := map[string]string{api.RootStackPolicyKey: rootStackName}
+	for _, s := range append([]*model.Stack{cl.networkStack, cl.etcdStack, cl.controlPlaneStack}, cl.nodePoolStacks...) {
+		stackName, err := getNestedStackName(cfSvc, rootStackName, s.NestedStackName())
+		if err != nil {
+			return err
+		}
+		stackNames[s.StackName] = stackName
+	}
+
+	keys := []string{}
+	for k := range stackNames {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		policy, ok := cl.Cfg.CloudFormation.StackPolicies[k]
+		if !ok {
+			current, err := cfSvc.GetStackPolicy(&cloudformation.GetStackPolicyInput{StackName: aws.String(stackNames[k])})
+			if err != nil {
+				logger.Debugf("Leaving the stack policy of stack %s as is as it can't be got: %v\n", stackNames[k], err)
+				continue
+			}
+			if body := aws.StringValue(current.StackPolicyBody); body == "" || sameStackPolicy(body, policy.Body()) {
+				continue
+			}
+		}
+		if _, err := cfSvc.SetStackPolicy(&cloudformation.SetStackPolicyInput{
+			StackName:       aws.String(stackNames[k]),
+			StackPolicyBody: aws.String(policy.Body()),
+		}); err != nil {
+			return fmt.Errorf("failed to set stack policy of stack %s: %v", stackNames[k], err)
+		}
+		if ok {
+			logger.Infof("Set the stack policy of %s with %d statement(s)\n", k, len(policy.Statements))
+		} else {
+			logger.Infof("Reset the stack policy of %s to allow all updates\n", k)
+		}
+	}
+	return nil
+}
+
+// updateTerminationProtection enables or disables the termination protection of the root stack when it differs from cluster.yaml
+func (cl *Cluster) updateTerminationProtection(cfSvc StackProtector, rootStackName string) error {
+	enabled := cl.Cfg.CloudFormation.TerminationProtection
+	resp, err := cfSvc.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(rootStackName)})
+	if err != nil {
+		return fmt.Errorf("failed to describe stack %s: %v", rootStackName, err)
+	}
+	if len(resp.Stacks) > 0 && aws.BoolValue(resp.Stacks[0].EnableTerminationProtection) == enabled {
+		return nil
+	}
+	if _, err := cfSvc.UpdateTerminationProtection(&cloudformation.UpdateTerminationProtectionInput{
+		StackName:                   aws.String(rootStackName),
+		EnableTerminationProtection: aws.Bool(enabled),
+	}); err != nil {
+		return fmt.Errorf("failed to update termination protection of stack %s: %v", rootStackName, err)
+	}
+	logger.Infof("Termination protection of stack %s is %v\n", rootStackName, enabled)
+	return nil
+}
+
+// sameStackPolicy returns true when the JSON stack policy bodies are equal regardless of their formatting
+func sameStackPolicy(a, b string) bool {
+	var x, y interface{}
+	if err := json.Unmarshal([]byte(a), &x); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(b), &y); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
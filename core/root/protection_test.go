@@ -0,0 +1,160 @@
+package root
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dummyStackProtector struct {
+	terminationProtection bool
+	// policies are the current stack policies keyed by stack name. Getting the policy of a stack missing here fails
+	policies map[string]string
+
+	updatedTerminationProtection []bool
+	setPolicies                  map[string]string
+}
+
+func (s *dummyStackProtector) DescribeStackResource(input *cloudformation.DescribeStackResourceInput) (*cloudformation.DescribeStackResourceOutput, error) {
+	return &cloudformation.DescribeStackResourceOutput{
+		StackResourceDetail: &cloudformation.StackResourceDetail{
+			PhysicalResourceId: aws.String("mycluster-" + aws.StringValue(input.LogicalResourceId)),
+		},
+	}, nil
+}
+
+func (s *dummyStackProtector) DescribeStacks(input *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+	return &cloudformation.DescribeStacksOutput{
+		Stacks: []*cloudformation.Stack{{StackName: input.StackName, EnableTerminationProtection: aws.Bool(s.terminationProtection)}},
+	}, nil
+}
+
+func (s *dummyStackProtector) UpdateTerminationProtection(input *cloudformation.UpdateTerminationProtectionInput) (*cloudformation.UpdateTerminationProtectionOutput, error) {
+	s.updatedTerminationProtection = append(s.updatedTerminationProtection, aws.BoolValue(input.EnableTerminationProtection))
+	return &cloudformation.UpdateTerminationProtectionOutput{}, nil
+}
+
+func (s *dummyStackProtector) GetStackPolicy(input *cloudformation.GetStackPolicyInput) (*cloudformation.GetStackPolicyOutput, error) {
+	body, ok := s.policies[aws.StringValue(input.StackName)]
+	if !ok {
+		return nil, errors.New("AccessDenied")
+	}
+	out := &cloudformation.GetStackPolicyOutput{}
+	if body != "" {
+		out.StackPolicyBody = aws.String(body)
+	}
+	return out, nil
+}
+
+func (s *dummyStackProtector) SetStackPolicy(input *cloudformation.SetStackPolicyInput) (*cloudformation.SetStackPolicyOutput, error) {
+	if s.setPolicies == nil {
+		s.setPolicies = map[string]string{}
+	}
+	s.setPolicies[aws.StringValue(input.StackName)] = aws.StringValue(input.StackPolicyBody)
+	return &cloudformation.SetStackPolicyOutput{}, nil
+}
+
+func TestProtectStacks(t *testing.T) {
+	denyEtcdDeletion := api.StackPolicy{Statements: []api.StackPolicyStatement{{Effect: "Deny", Actions: []string{"Update:Delete"}}}}
+	allowAll := api.StackPolicy{}.Body()
+
+	newCluster := func(cfn api.CloudFormation) Cluster {
+		c := &api.Cluster{}
+		c.ClusterName = "mycluster"
+		c.CloudFormation = cfn
+		cfg := &model.Config{Cluster: c}
+		return Cluster{
+			Cfg:               &config.Config{Config: cfg},
+			controlPlaneStack: &model.Stack{StackName: "control-plane", Config: cfg},
+			etcdStack:         &model.Stack{StackName: "etcd"},
+			networkStack:      &model.Stack{StackName: "network"},
+			nodePoolStacks:    []*model.Stack{{StackName: "pool1"}},
+		}
+	}
+
+	testCases := []struct {
+		context                              string
+		cloudFormation                       api.CloudFormation
+		terminationProtection                bool
+		policies                             map[string]string
+		expectedUpdatedTerminationProtection []bool
+		expectedSetPolicies                  map[string]string
+	}{
+		{
+			context:  "NothingConfigured",
+			policies: map[string]string{"mycluster": allowAll, "mycluster-Etcd": "", "mycluster-Network": ""},
+		},
+		{
+			context: "NothingConfiguredWithoutPermissionToGetPolicies",
+		},
+		{
+			context:                              "TerminationProtectionEnabled",
+			cloudFormation:                       api.CloudFormation{TerminationProtection: true},
+			expectedUpdatedTerminationProtection: []bool{true},
+		},
+		{
+			context:               "TerminationProtectionAlreadyEnabled",
+			cloudFormation:        api.CloudFormation{TerminationProtection: true},
+			terminationProtection: true,
+		},
+		{
+			context:                              "TerminationProtectionDisabled",
+			terminationProtection:                true,
+			expectedUpdatedTerminationProtection: []bool{false},
+		},
+		{
+			context:             "StackPolicyConfigured",
+			cloudFormation:      api.CloudFormation{StackPolicies: api.StackPolicies{"etcd": denyEtcdDeletion}},
+			expectedSetPolicies: map[string]string{"mycluster-Etcd": denyEtcdDeletion.Body()},
+		},
+		{
+			context:             "StackPolicyRemoved",
+			policies:            map[string]string{"mycluster": allowAll, "mycluster-Etcd": denyEtcdDeletion.Body()},
+			expectedSetPolicies: map[string]string{"mycluster-Etcd": allowAll},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			cfSvc := &dummyStackProtector{terminationProtection: tc.terminationProtection, policies: tc.policies}
+			cl := newCluster(tc.cloudFormation)
+			require.NoError(t, cl.protectStacks(cfSvc))
+			assert.Equal(t, tc.expectedUpdatedTerminationProtection, cfSvc.updatedTerminationProtection)
+			assert.Equal(t, tc.expectedSetPolicies, cfSvc.setPolicies)
+		})
+	}
+}
+
+func TestSameStackPolicy(t *testing.T) {
+	assert.True(t, sameStackPolicy(`{"Statement":[{"Effect":"Allow","Principal":"*","Action":"Update:*","Resource":"*"}]}`, api.StackPolicy{}.Body()))
+	assert.False(t, sameStackPolicy(`{"Statement":[]}`, api.StackPolicy{}.Body()))
+	assert.False(t, sameStackPolicy(`not json`, api.StackPolicy{}.Body()))
+}
+
+type missingStackDescriber struct{}
+
+func (missingStackDescriber) DescribeStacks(input *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+	return nil, awserr.New("ValidationError", "Stack with id "+aws.StringValue(input.StackName)+" does not exist", nil)
+}
+
+func TestCheckTerminationProtection(t *testing.T) {
+	exists, err := checkTerminationProtection(missingStackDescriber{}, "mycluster")
+	require.NoError(t, err, "destroying a cluster whose root stack doesn't exist must succeed")
+	assert.False(t, exists)
+
+	exists, err = checkTerminationProtection(&dummyStackProtector{}, "mycluster")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = checkTerminationProtection(&dummyStackProtector{terminationProtection: true}, "mycluster")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "termination protection enabled")
+}
@@ -0,0 +1,281 @@
+package root
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/kubernetes-incubator/kube-aws/cfnstack"
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/naming"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/pkg/model"
+	"github.com/kubernetes-incubator/kube-aws/revision"
+)
+
+func (cl *Cluster) revisionStore() (*revision.Store, error) {
+	s3URI := api.NewS3Folders(cl.Cfg.DeploymentSettings.S3URI, cl.Cfg.ClusterName).ClusterRevisions().URI()
+	if cl.revisionS3 != nil {
+		return revision.NewStore(cl.revisionS3, s3URI)
+	}
+	return revision.NewStore(s3.New(cl.session), s3URI)
+}
+
+// History returns all the revisions of the cluster archived by successful applies, oldest first
+func (cl *Cluster) History() ([]*revision.Revision, error) {
+	store, err := cl.revisionStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.List()
+}
+
+// RevisionFile returns the content of a file archived in the revision e.g. cluster.yaml
+func (cl *Cluster) RevisionFile(number int, path string) (string, error) {
+	store, err := cl.revisionStore()
+	if err != nil {
+		return "", err
+	}
+	r, err := store.Get(number)
+	if err != nil {
+		return "", err
+	}
+	return store.ReadFile(r, path)
+}
+
+// Rollback re-applies the stack templates and userdata archived in the revision numbered `number`,
+// and then archives them as a new revision
+func (cl *Cluster) Rollback(number int) error {
+	if err := cl.ensureNestedStacksLoaded(); err != nil {
+		return err
+	}
+
+	store, err := cl.revisionStore()
+	if err != nil {
+		return err
+	}
+	r, err := store.Get(number)
+	if err != nil {
+		return err
+	}
+	if r.ClusterName != cl.Cfg.ClusterName {
+		return fmt.Errorf("revision %d is for cluster %s but the current cluster is %s", number, r.ClusterName, cl.Cfg.ClusterName)
+	}
+	files, err := store.ReadFiles(r)
+	if err != nil {
+		return err
+	}
+	if redacted := redactedStackFiles(r); len(redacted) > 0 {
+		return fmt.Errorf("revision %d can't be rolled back to, as secrets have been redacted from %s. Run `kube-aws apply` with the cluster.yaml archived in the revision instead", number, strings.Join(redacted, ", "))
+	}
+
+	assets, err := cl.revisionAssets(files)
+	if err != nil {
+		return err
+	}
+	if _, err := cl.extractRootStackTemplateURL(assets); err != nil {
+		return fmt.Errorf("revision %d is incomplete: %v", number, err)
+	}
+
+	exists, err := cl.rootStackExists()
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Rolling back cluster %s to revision %d created at %s ...\n", cl.Cfg.ClusterName, number, r.CreatedAt.Format(time.RFC3339))
+
+	cfSvc := cloudformation.New(cl.session)
+	if err := cl.applyAssets(cfSvc, assets, exists); err != nil {
+		return err
+	}
+
+	cl.saveRevision(&revision.Revision{
+		ClusterName:       r.ClusterName,
+		KubeAWSVersion:    r.KubeAWSVersion,
+		KubernetesVersion: r.KubernetesVersion,
+		Targets:           AllOperationTargetsAsStringSlice(),
+		Plugins:           r.Plugins,
+		RollbackOf:        number,
+	}, files)
+	return nil
+}
+
+// redactedStackFiles returns the stack files redacted in the revision, which would re-apply the references to secrets in place of the values
+func redactedStackFiles(r *revision.Revision) []string {
+	redacted := []string{}
+	for _, path := range r.Redacted {
+		if strings.HasPrefix(path, revision.StacksDir+"/") {
+			redacted = append(redacted, path)
+		}
+	}
+	return redacted
+}
+
+// revisionAssets rebuilds the assets uploaded to S3 on an apply from the files archived in a revision
+func (cl *Cluster) revisionAssets(files map[string]string) (cfnstack.Assets, error) {
+	s3URI := api.NewS3Folders(cl.Cfg.DeploymentSettings.S3URI, cl.Cfg.ClusterName).ClusterExportedStacks().URI()
+
+	var assets cfnstack.Assets = cfnstack.EmptyAssets()
+	for stack, stackFiles := range revision.StackFiles(files) {
+		builder, err := cfnstack.NewAssetsBuilder(stack, s3URI, cl.Cfg.Region)
+		if err != nil {
+			return nil, err
+		}
+		for filename, content := range stackFiles {
+			if _, err := builder.Add(filename, content); err != nil {
+				return nil, err
+			}
+		}
+		assets = assets.Merge(builder.Build())
+	}
+	return assets, nil
+}
+
+func revisionFilesFromAssets(assets cfnstack.Assets) map[string]string {
+	files := map[string]string{}
+	for id, a := range assets.AsMap() {
+		files[revision.StackFilePath(id.StackName, id.Filename)] = a.Content
+	}
+	return files
+}
+
+// archiveRevision archives cluster.yaml, the plugins and the files produced by an apply as a new revision
+func (cl *Cluster) archiveRevision(files map[string]string, targets OperationTargets) {
+	if cl.configPath != "" {
+		// The merged cluster.yaml is archived as it is what has been applied
+		data, err := config.ReadConfigFile(cl.configPath)
+		if err != nil {
+			logger.Warnf("failed to archive revision: failed to read %s: %v\n", cl.configPath, err)
+			return
+		}
+		files[revision.ClusterConfigFile] = string(data)
+	}
+
+	redacted := []string{}
+	for path, content := range files {
+		files[path] = cl.redactSecrets(path, content)
+		if files[path] != content {
+			redacted = append(redacted, path)
+		}
+	}
+	sort.Strings(redacted)
+
+	if err := addPluginFiles(files); err != nil {
+		logger.Warnf("failed to archive revision: %v\n", err)
+		return
+	}
+
+	plugins := []revision.Plugin{}
+	for _, p := range cl.Cfg.Plugins {
+		enabled, _ := p.EnabledIn(cl.Cfg.PluginConfigs)
+		plugins = append(plugins, revision.Plugin{Name: p.Name, Version: p.Version, Enabled: enabled})
+	}
+
+	cl.saveRevision(&revision.Revision{
+		ClusterName:       cl.Cfg.ClusterName,
+		KubeAWSVersion:    model.VERSION,
+		KubernetesVersion: cl.Cfg.K8sVer,
+		Targets:           targets,
+		Plugins:           plugins,
+		Redacted:          redacted,
+	}, files)
+}
+
+// saveRevision saves a new revision. The assets of the nested stacks which weren't updated this time are carried over from the latest revision
+// so that every revision can be rolled back to on its own.
+// The cluster has already been updated at this point, so a failure is reported without failing the whole operation.
+func (cl *Cluster) saveRevision(r *revision.Revision, files map[string]string) {
+	store, err := cl.revisionStore()
+	if err != nil {
+		logger.Warnf("failed to archive revision: %v\n", err)
+		return
+	}
+
+	latest, err := store.Latest()
+	if err != nil {
+		logger.Warnf("failed to archive revision: %v\n", err)
+		return
+	}
+	if latest != nil {
+		previous, err := store.ReadFiles(latest)
+		if err != nil {
+			logger.Warnf("failed to archive revision: %v\n", err)
+			return
+		}
+		for _, path := range carryOverStackFiles(files, previous, cl.stackName()) {
+			if containsString(latest.Redacted, path) {
+				r.Redacted = append(r.Redacted, path)
+			}
+		}
+		sort.Strings(r.Redacted)
+	}
+
+	r.CreatedAt = time.Now().UTC()
+	n, err := store.Save(r, files)
+	if err != nil {
+		logger.Warnf("failed to archive revision: %v\n", err)
+		return
+	}
+	logger.Infof("Archived revision %d of cluster %s\n", n, r.ClusterName)
+}
+
+// carryOverStackFiles copies the files of the nested stacks missing in `files` from `previous`,
+// as long as the root stack template in `files` still contains the nested stacks, and returns the paths of the copied files
+func carryOverStackFiles(files, previous map[string]string, rootStackName string) []string {
+	carried := []string{}
+	root, ok := revision.StackFiles(files)[rootStackName]
+	if !ok {
+		return carried
+	}
+	template := struct {
+		Resources map[string]interface{}
+	}{}
+	if err := json.Unmarshal([]byte(root[REMOTE_STACK_TEMPLATE_FILENAME]), &template); err != nil {
+		return carried
+	}
+
+	current := revision.StackFiles(files)
+	for stack, stackFiles := range revision.StackFiles(previous) {
+		if _, ok := current[stack]; ok {
+			continue
+		}
+		if _, ok := template.Resources[naming.FromStackToCfnResource(stack)]; !ok {
+			continue
+		}
+		for filename, content := range stackFiles {
+			path := revision.StackFilePath(stack, filename)
+			files[path] = content
+			carried = append(carried, path)
+		}
+	}
+	return carried
+}
+
+// addPluginFiles adds all the files in the plugins directory, from which plugins are loaded
+func addPluginFiles(files map[string]string) error {
+	if _, err := os.Stat(revision.PluginsDir); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.Walk(revision.PluginsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		files[filepath.ToSlash(path)] = string(data)
+		return nil
+	})
+}
@@ -0,0 +1,109 @@
+package root
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/pkg/model"
+	"github.com/kubernetes-incubator/kube-aws/revision"
+	"github.com/kubernetes-incubator/kube-aws/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dummyRevisionS3 struct {
+	objects map[string][]byte
+}
+
+func (s *dummyRevisionS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	data, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	s.objects[aws.StringValue(input.Bucket)+"/"+aws.StringValue(input.Key)] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (s *dummyRevisionS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	data, ok := s.objects[aws.StringValue(input.Bucket)+"/"+aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil)
+	}
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(data))}, nil
+}
+
+// ListObjectsV2 returns all the common prefixes at once
+func (s *dummyRevisionS3) ListObjectsV2(input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	prefix := aws.StringValue(input.Bucket) + "/" + aws.StringValue(input.Prefix)
+	found := map[string]bool{}
+	out := &s3.ListObjectsV2Output{}
+	for k := range s.objects {
+		rest := strings.TrimPrefix(k, prefix)
+		if rest == k {
+			continue
+		}
+		if i := strings.Index(rest, "/"); i >= 0 && !found[rest[:i]] {
+			found[rest[:i]] = true
+			out.CommonPrefixes = append(out.CommonPrefixes, &s3.CommonPrefix{Prefix: aws.String(aws.StringValue(input.Prefix) + rest[:i+1])})
+		}
+	}
+	return out, nil
+}
+
+func TestRollbackToRedactedRevision(t *testing.T) {
+	c := &api.Cluster{}
+	c.ClusterName = "mycluster"
+	c.S3URI = "s3://mybucket/mydir"
+	cfg := &model.Config{Cluster: c}
+	svc := &dummyRevisionS3{objects: map[string][]byte{}}
+	cl := Cluster{
+		Cfg: &config.Config{
+			Config:  cfg,
+			Secrets: []secret.Resolved{{Path: "controller.customFiles[0].content", Value: "s3cr3tt0k3n", References: []string{`{{ssm "/mycluster/token"}}`}}},
+		},
+		controlPlaneStack: &model.Stack{StackName: "control-plane", Config: cfg},
+		loaded:            true,
+		revisionS3:        svc,
+	}
+	rootStack := revision.StackFilePath("mycluster", REMOTE_STACK_TEMPLATE_FILENAME)
+	controlPlaneStack := revision.StackFilePath("control-plane", model.STACK_TEMPLATE_FILENAME)
+	rootTemplate := `{"Resources":{"Controlplane":{"Type":"AWS::CloudFormation::Stack"}}}`
+
+	store, err := cl.revisionStore()
+	require.NoError(t, err)
+
+	// The secret ends up in plain text in the control-plane stack template
+	cl.archiveRevision(map[string]string{
+		rootStack:         rootTemplate,
+		controlPlaneStack: `{"Token":"s3cr3tt0k3n"}`,
+	}, OperationTargets{"control-plane"})
+
+	r, err := store.Get(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{controlPlaneStack}, r.Redacted)
+	content, err := store.ReadFile(r, controlPlaneStack)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Token":"{{ssm "/mycluster/token"}}"}`, content)
+
+	err = cl.Rollback(1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "revision 1 can't be rolled back to, as secrets have been redacted from stacks/control-plane/stack.json")
+
+	// The redacted stack template is carried over to the revision archived by an apply updating only the root stack
+	cl.archiveRevision(map[string]string{rootStack: rootTemplate}, OperationTargets{})
+
+	r, err = store.Get(2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{controlPlaneStack}, r.Redacted)
+
+	err = cl.Rollback(2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "revision 2 can't be rolled back to")
+}
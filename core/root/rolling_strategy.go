@@ -97,3 +97,140 @@ func (c Cluster) allNodePoolLogicalNamesinAZ(az string) []string {
 	logger.Debugf("The following nodepools are in AZ %s: %v", az, poolNames)
 	return poolNames
 }
+
+// nodePoolBatchedDependencies produces a list of NodePool logical names that the present nodepool depends upon
+// when using the 'Batched' NodePoolRollingStrategy.
+// The pools using the strategy are rolled `worker.nodePoolRollingMaxParallel` at a time in the order listed in cluster.yaml,
+// each batch waiting for all the pools in the previous batch.
+// Returns the quoted logical names of the pools in the previous batch separated by commas, e.g. "Pool1","Pool2",
+// or an empty string for the pools in the first batch
+func (c Cluster) nodePoolBatchedDependencies(pool nodePool) (string, error) {
+	poolConfig := pool.nodePool.NodePoolConfig
+
+	maxParallel, err := c.rollingMaxParallel("Batched")
+	if err != nil {
+		return "", fmt.Errorf("can't resolve nodepool batch dependencies for %s: %v", poolConfig.NodePoolName, err)
+	}
+
+	batches := batchNodePools(c.nodePoolStacksWithRollingStrategy("Batched"), maxParallel)
+	logger.Debugf("Batched rollout order: %v", batches)
+
+	return quotedDependencies(previousBatch(batches, poolConfig.NodePoolLogicalName())), nil
+}
+
+// nodePoolCanaryDependencies produces a list of NodePool logical names that the present nodepool depends upon
+// when using the 'Canary' NodePoolRollingStrategy.
+// The canary pool named by `worker.nodePoolRollingCanary` is rolled first, and the other pools using the strategy wait for it,
+// so that they aren't rolled at all when the canary fails to update or its nodes don't signal.
+// The other pools are rolled `worker.nodePoolRollingMaxParallel` at a time when it is set, otherwise all at once.
+// Returns the quoted logical name of the canary, e.g. "Canary", for the pools in the first batch after it,
+// the quoted logical names of the pools in the previous batch separated by commas for the other pools,
+// or an empty string for the canary itself
+func (c Cluster) nodePoolCanaryDependencies(pool nodePool) (string, error) {
+	poolConfig := pool.nodePool.NodePoolConfig
+
+	canary, err := c.canaryNodePool()
+	if err != nil {
+		return "", fmt.Errorf("can't resolve nodepool canary dependencies for %s: %v", poolConfig.NodePoolName, err)
+	}
+	if canary == poolConfig.NodePoolLogicalName() {
+		logger.Debugf("The nodepool %s is the canary, so it does not have any dependencies", poolConfig.NodePoolName)
+		return "", nil
+	}
+
+	maxParallel := c.Cfg.Worker.NodePoolRollingMaxParallel
+	if maxParallel < 0 {
+		return "", fmt.Errorf("can't resolve nodepool canary dependencies for %s: worker.nodePoolRollingMaxParallel must not be negative: %d", poolConfig.NodePoolName, maxParallel)
+	}
+
+	var rest []string
+	for _, name := range c.nodePoolStacksWithRollingStrategy("Canary") {
+		if name != canary {
+			rest = append(rest, name)
+		}
+	}
+	if maxParallel == 0 {
+		maxParallel = len(rest)
+	}
+	batches := append([][]string{{canary}}, batchNodePools(rest, maxParallel)...)
+	logger.Debugf("Canary rollout order: %v", batches)
+
+	return quotedDependencies(previousBatch(batches, poolConfig.NodePoolLogicalName())), nil
+}
+
+// rollingMaxParallel validates and returns `worker.nodePoolRollingMaxParallel` required by `strategy`
+func (c Cluster) rollingMaxParallel(strategy string) (int, error) {
+	maxParallel := c.Cfg.Worker.NodePoolRollingMaxParallel
+	if maxParallel < 1 {
+		return 0, fmt.Errorf("the '%s' rolling strategy requires worker.nodePoolRollingMaxParallel to be 1 or greater but it was %d", strategy, maxParallel)
+	}
+	return maxParallel, nil
+}
+
+// canaryNodePool validates and returns the logical name of the canary nodepool named by `worker.nodePoolRollingCanary`.
+// The canary must use the 'Canary' strategy itself, and wait for its nodes to signal so that a canary whose nodes fail to
+// come up fails its update and halts the other pools.
+func (c Cluster) canaryNodePool() (string, error) {
+	name := c.Cfg.Worker.NodePoolRollingCanary
+	if name == "" {
+		return "", fmt.Errorf("the 'Canary' rolling strategy requires worker.nodePoolRollingCanary to name the nodepool rolled first")
+	}
+	for _, pool := range c.nodePoolStacks {
+		poolConfig := pool.NodePoolConfig
+		if poolConfig.NodePoolName != name {
+			continue
+		}
+		if poolConfig.NodePoolRollingStrategy != "Canary" {
+			return "", fmt.Errorf("the canary nodepool %s must use the 'Canary' rolling strategy but uses '%s'", name, poolConfig.NodePoolRollingStrategy)
+		}
+		if !poolConfig.WaitSignal.Enabled() {
+			return "", fmt.Errorf("the canary nodepool %s must have waitSignal enabled so that the other nodepools wait for its nodes to signal", name)
+		}
+		return poolConfig.NodePoolLogicalName(), nil
+	}
+	return "", fmt.Errorf("the canary nodepool %s named by worker.nodePoolRollingCanary does not exist", name)
+}
+
+// nodePoolStacksWithRollingStrategy returns the logical names of the nodepools using `strategy` in the order listed in cluster.yaml
+func (c Cluster) nodePoolStacksWithRollingStrategy(strategy string) []string {
+	var poolNames []string
+	for _, pool := range c.nodePoolStacks {
+		poolConfig := pool.NodePoolConfig
+		if poolConfig.NodePoolRollingStrategy == strategy {
+			poolNames = append(poolNames, poolConfig.NodePoolLogicalName())
+		}
+	}
+	return poolNames
+}
+
+// batchNodePools splits the nodepools into batches of up to `size` pools
+func batchNodePools(poolNames []string, size int) [][]string {
+	var batches [][]string
+	for len(poolNames) > size {
+		batches = append(batches, poolNames[:size])
+		poolNames = poolNames[size:]
+	}
+	if len(poolNames) > 0 {
+		batches = append(batches, poolNames)
+	}
+	return batches
+}
+
+// previousBatch returns the nodepools in the batch before the one containing `poolName`
+func previousBatch(batches [][]string, poolName string) []string {
+	for i, batch := range batches {
+		for _, name := range batch {
+			if name == poolName && i > 0 {
+				return batches[i-1]
+			}
+		}
+	}
+	return nil
+}
+
+func quotedDependencies(poolNames []string) string {
+	if len(poolNames) == 0 {
+		return ""
+	}
+	return `"` + strings.Join(poolNames, `","`) + `"`
+}
@@ -0,0 +1,161 @@
+package root
+
+import (
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchNodePools(t *testing.T) {
+	pools := []string{"Pool1", "Pool2", "Pool3", "Pool4", "Pool5"}
+
+	assert.Equal(t, [][]string{{"Pool1", "Pool2"}, {"Pool3", "Pool4"}, {"Pool5"}}, batchNodePools(pools, 2))
+	assert.Equal(t, [][]string{{"Pool1", "Pool2", "Pool3", "Pool4", "Pool5"}}, batchNodePools(pools, 5))
+	assert.Equal(t, [][]string{{"Pool1", "Pool2", "Pool3", "Pool4", "Pool5"}}, batchNodePools(pools, 10))
+	assert.Equal(t, [][]string{{"Pool1"}, {"Pool2"}, {"Pool3"}, {"Pool4"}, {"Pool5"}}, batchNodePools(pools, 1))
+	assert.Empty(t, batchNodePools(nil, 2))
+}
+
+func TestPreviousBatch(t *testing.T) {
+	batches := [][]string{{"Pool1", "Pool2"}, {"Pool3", "Pool4"}, {"Pool5"}}
+
+	assert.Empty(t, previousBatch(batches, "Pool1"))
+	assert.Empty(t, previousBatch(batches, "Pool2"))
+	assert.Equal(t, []string{"Pool1", "Pool2"}, previousBatch(batches, "Pool3"))
+	assert.Equal(t, []string{"Pool1", "Pool2"}, previousBatch(batches, "Pool4"))
+	assert.Equal(t, []string{"Pool3", "Pool4"}, previousBatch(batches, "Pool5"))
+	assert.Empty(t, previousBatch(batches, "Other"))
+}
+
+type testNodePool struct {
+	name       string
+	strategy   string
+	waitSignal bool
+}
+
+func newRollingCluster(worker api.Worker, pools ...testNodePool) Cluster {
+	stacks := []*model.Stack{}
+	for _, p := range pools {
+		waitSignal := p.waitSignal
+		np := api.WorkerNodePool{NodePoolName: p.name, NodePoolRollingStrategy: p.strategy}
+		np.WaitSignal = api.WaitSignal{EnabledOverride: &waitSignal}
+		stacks = append(stacks, &model.Stack{NodePoolConfig: &model.NodePoolConfig{WorkerNodePool: np}})
+	}
+	return Cluster{
+		Cfg:            &config.Config{Config: &model.Config{Cluster: &api.Cluster{Worker: worker}}},
+		nodePoolStacks: stacks,
+	}
+}
+
+// dependsOn returns the DependsOn entries produced for every nodepool keyed by nodepool name
+func dependsOn(t *testing.T, c Cluster, deps func(nodePool) (string, error)) map[string]string {
+	result := map[string]string{}
+	for _, s := range c.nodePoolStacks {
+		d, err := deps(nodePool{nodePool: s})
+		require.NoError(t, err)
+		result[s.NodePoolConfig.NodePoolName] = d
+	}
+	return result
+}
+
+func TestNodePoolBatchedDependencies(t *testing.T) {
+	c := newRollingCluster(
+		api.Worker{NodePoolRollingMaxParallel: 2},
+		testNodePool{name: "pool1", strategy: "Batched"},
+		testNodePool{name: "pool2", strategy: "Batched"},
+		testNodePool{name: "pool3", strategy: "Sequential"},
+		testNodePool{name: "pool4", strategy: "Batched"},
+		testNodePool{name: "pool5", strategy: "Batched"},
+		testNodePool{name: "pool6", strategy: "Batched"},
+	)
+
+	assert.Equal(t, map[string]string{
+		"pool1": ``,
+		"pool2": ``,
+		"pool3": ``,
+		"pool4": `"Pool1","Pool2"`,
+		"pool5": `"Pool1","Pool2"`,
+		"pool6": `"Pool4","Pool5"`,
+	}, dependsOn(t, c, c.nodePoolBatchedDependencies))
+
+	c.Cfg.Worker.NodePoolRollingMaxParallel = 0
+	_, err := c.nodePoolBatchedDependencies(nodePool{nodePool: c.nodePoolStacks[0]})
+	assert.EqualError(t, err, "can't resolve nodepool batch dependencies for pool1: the 'Batched' rolling strategy requires worker.nodePoolRollingMaxParallel to be 1 or greater but it was 0")
+}
+
+func TestNodePoolCanaryDependencies(t *testing.T) {
+	pools := []testNodePool{
+		{name: "pool1", strategy: "Canary", waitSignal: true},
+		{name: "canary", strategy: "Canary", waitSignal: true},
+		{name: "pool2", strategy: "Canary", waitSignal: true},
+		{name: "pool3", strategy: "Batched", waitSignal: true},
+		{name: "pool4", strategy: "Canary", waitSignal: true},
+	}
+
+	t.Run("AllAtOnce", func(t *testing.T) {
+		c := newRollingCluster(api.Worker{NodePoolRollingCanary: "canary"}, pools...)
+		deps := dependsOn(t, c, c.nodePoolCanaryDependencies)
+		assert.Equal(t, "", deps["canary"])
+		assert.Equal(t, `"Canary"`, deps["pool1"])
+		assert.Equal(t, `"Canary"`, deps["pool2"])
+		assert.Equal(t, `"Canary"`, deps["pool4"])
+	})
+
+	t.Run("MaxParallel", func(t *testing.T) {
+		c := newRollingCluster(api.Worker{NodePoolRollingCanary: "canary", NodePoolRollingMaxParallel: 2}, pools...)
+		deps := dependsOn(t, c, c.nodePoolCanaryDependencies)
+		assert.Equal(t, "", deps["canary"])
+		assert.Equal(t, `"Canary"`, deps["pool1"])
+		assert.Equal(t, `"Canary"`, deps["pool2"])
+		assert.Equal(t, `"Pool1","Pool2"`, deps["pool4"])
+	})
+
+	testCases := []struct {
+		context string
+		worker  api.Worker
+		pools   []testNodePool
+		err     string
+	}{
+		{
+			context: "NoCanary",
+			pools:   pools,
+			err:     "can't resolve nodepool canary dependencies for pool1: the 'Canary' rolling strategy requires worker.nodePoolRollingCanary to name the nodepool rolled first",
+		},
+		{
+			context: "MissingCanary",
+			worker:  api.Worker{NodePoolRollingCanary: "missing"},
+			pools:   pools,
+			err:     "can't resolve nodepool canary dependencies for pool1: the canary nodepool missing named by worker.nodePoolRollingCanary does not exist",
+		},
+		{
+			context: "CanaryWithAnotherStrategy",
+			worker:  api.Worker{NodePoolRollingCanary: "pool3"},
+			pools:   pools,
+			err:     "can't resolve nodepool canary dependencies for pool1: the canary nodepool pool3 must use the 'Canary' rolling strategy but uses 'Batched'",
+		},
+		{
+			context: "CanaryWithoutWaitSignal",
+			worker:  api.Worker{NodePoolRollingCanary: "canary"},
+			pools:   []testNodePool{{name: "pool1", strategy: "Canary", waitSignal: true}, {name: "canary", strategy: "Canary"}},
+			err:     "can't resolve nodepool canary dependencies for pool1: the canary nodepool canary must have waitSignal enabled so that the other nodepools wait for its nodes to signal",
+		},
+		{
+			context: "NegativeMaxParallel",
+			worker:  api.Worker{NodePoolRollingCanary: "canary", NodePoolRollingMaxParallel: -1},
+			pools:   pools,
+			err:     "can't resolve nodepool canary dependencies for pool1: worker.nodePoolRollingMaxParallel must not be negative: -1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			c := newRollingCluster(tc.worker, tc.pools...)
+			_, err := c.nodePoolCanaryDependencies(nodePool{nodePool: c.nodePoolStacks[0]})
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
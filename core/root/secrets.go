@@ -0,0 +1,39 @@
+package root
+
+import (
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/awsconn"
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/secret"
+)
+
+// secretStore returns the function to create the store looking up the secrets referenced from cluster.yaml with the AWS profile
+func secretStore(awsDebug bool, awsProfile string) config.SecretStoreFunc {
+	return func(region api.Region) (secret.Store, error) {
+		session, err := awsconn.NewSessionFromRegion(region, awsDebug, awsProfile)
+		if err != nil {
+			return nil, err
+		}
+		return secret.NewAWSStore(session), nil
+	}
+}
+
+// minRedactedSecretLength is the length of the shortest value redacted by redactSecrets.
+// Shorter values such as `true` or `1` are likely to match unrelated content, which would be corrupted by redacting them
+const minRedactedSecretLength = 8
+
+// redactSecrets replaces the values resolved from secrets in the content of the file at `path` with the references to them,
+// so that secrets are never written to disk in plain text
+func (cl Cluster) redactSecrets(path string, content string) string {
+	for _, r := range cl.Cfg.Secrets {
+		if len(r.Value) < minRedactedSecretLength || !strings.Contains(content, r.Value) {
+			continue
+		}
+		logger.Warnf("Redacting %s resolved from secrets in %s\n", r.Path, path)
+		content = strings.Replace(content, r.Value, strings.Join(r.References, ""), -1)
+	}
+	return content
+}
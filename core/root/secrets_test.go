@@ -0,0 +1,49 @@
+package root
+
+import (
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-aws/core/root/config"
+	"github.com/kubernetes-incubator/kube-aws/secret"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	cl := Cluster{
+		Cfg: &config.Config{
+			Secrets: []secret.Resolved{
+				{Path: "controller.customFiles[0].content", Value: "s3cr3tt0k3n", References: []string{`{{ssm "/mycluster/token"}}`}},
+				{Path: "controller.customFiles[1].content", Value: "prefix-s3cr3tpassw0rd", References: []string{"prefix-", `{{secretsmanager "mycluster/app" "password"}}`}},
+				{Path: "controller.customFiles[2].content", Value: "true", References: []string{`{{ssm "/mycluster/enabled"}}`}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		context  string
+		content  string
+		expected string
+	}{
+		{
+			context:  "NoSecrets",
+			content:  `{"Enabled":"true"}`,
+			expected: `{"Enabled":"true"}`,
+		},
+		{
+			context:  "Secret",
+			content:  "token: s3cr3tt0k3n\ntoken2: s3cr3tt0k3n\n",
+			expected: "token: {{ssm \"/mycluster/token\"}}\ntoken2: {{ssm \"/mycluster/token\"}}\n",
+		},
+		{
+			context:  "PartOfValue",
+			content:  "password: prefix-s3cr3tpassw0rd\n",
+			expected: "password: prefix-{{secretsmanager \"mycluster/app\" \"password\"}}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			assert.Equal(t, tc.expected, cl.redactSecrets("exported/stacks/control-plane/stack.json", tc.content))
+		})
+	}
+}
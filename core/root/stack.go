@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/kubernetes-incubator/kube-aws/pkg/model"
 	"io/ioutil"
+	"regexp"
 	"strings"
 )
 
@@ -47,6 +48,15 @@ func getInstanceScriptUserdata(stackJson string, nestedStackLogicalName string)
 	fnBase64 := ud["Fn::Base64"].(map[string]interface{})
 	fnJoin := fnBase64["Fn::Join"].([]interface{})
 	joinedItems := fnJoin[1].([]interface{})
+	// With the `ignition` userdata format, the script is base64-encoded in the middle of the Ignition config
+	if len(joinedItems) == 3 {
+		if script, ok := joinedItems[1].(map[string]interface{}); ok {
+			joinedItems = script["Fn::Base64"].(map[string]interface{})["Fn::Join"].([]interface{})[1].([]interface{})
+		}
+	}
+	if len(joinedItems) < 4 {
+		return "", fmt.Errorf("unexpected userdata of %s", nestedStackLogicalName)
+	}
 	instanceScript := joinedItems[3].(string)
 	return instanceScript, nil
 }
@@ -74,10 +84,14 @@ func getInstanceUserdataJson(stackJson string, nestedStackLogicalName string) (s
 	return buf.String(), nil
 }
 
+var s3URIPattern = regexp.MustCompile(`s3://[^\s"\\]+`)
+
 func getS3Userdata(s3Svc *s3.S3, instanceUserdata string) (string, error) {
-	a := strings.Split(instanceUserdata, " cp ")
-	b := strings.Split(a[1], " ")[0]
-	s3uri := b
+	// Either the URI copied by the instance script, or the URI of the config merged by the Ignition config
+	s3uri := s3URIPattern.FindString(instanceUserdata)
+	if s3uri == "" {
+		return "", fmt.Errorf("no s3 userdata is referenced by the instance userdata")
+	}
 	tokens := strings.SplitN(strings.Split(s3uri, "s3://")[1], "/", 2)
 	bucket := tokens[0]
 	key := tokens[1]
@@ -212,3 +212,11 @@ func (p TemplateParams) Subnets() api.Subnets {
 func (p TemplateParams) NodePoolAvailabilityZoneDependencies(pool nodePool, subnets api.Subnets) (string, error) {
 	return p.cluster.nodePoolAvailabilityZoneDependencies(pool, subnets)
 }
+
+func (p TemplateParams) NodePoolBatchedDependencies(pool nodePool) (string, error) {
+	return p.cluster.nodePoolBatchedDependencies(pool)
+}
+
+func (p TemplateParams) NodePoolCanaryDependencies(pool nodePool) (string, error) {
+	return p.cluster.nodePoolCanaryDependencies(pool)
+}
@@ -0,0 +1,132 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/kubernetes-incubator/kube-aws/cfnstack"
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/naming"
+	"github.com/kubernetes-incubator/kube-aws/upgrade"
+)
+
+const upgradeHealthCheckInterval = 15 * time.Second
+
+// UpgradeOptions controls how the phases of an upgrade are run
+type UpgradeOptions struct {
+	// HealthTimeout is how long to wait for the stacks updated in a phase to become healthy before giving up
+	HealthTimeout time.Duration
+	// Pause stops the upgrade after each phase so that it can be verified before resuming
+	Pause bool
+}
+
+// PlanUpgrade validates the version skew between the current and the desired Kubernetes versions and
+// returns the state of the upgrade to be started
+func (cl *Cluster) PlanUpgrade(to string) (*upgrade.State, error) {
+	if err := upgrade.ValidateSkew(cl.Cfg.K8sVer, to, cl.Cfg.Etcd.Version()); err != nil {
+		return nil, err
+	}
+
+	exists, err := cl.rootStackExists()
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("cluster %s does not exist. Run `kube-aws apply` to create it", cl.Cfg.ClusterName)
+	}
+
+	if err := cl.ensureNestedStacksLoaded(); err != nil {
+		return nil, err
+	}
+	nodePools := []string{}
+	for _, np := range cl.nodePoolStacks {
+		nodePools = append(nodePools, np.StackName)
+	}
+	phases := upgrade.Phases(cl.etcdStack.StackName, cl.controlPlaneStack.StackName, nodePools)
+
+	return upgrade.NewState(cl.Cfg.ClusterName, cl.Cfg.K8sVer, to, phases, time.Now().UTC()), nil
+}
+
+// Upgrade runs the remaining phases of the upgrade one by one, and saves the progress to `statePath` after each phase.
+// It returns false when the upgrade has been paused before completing all the phases.
+func (cl *Cluster) Upgrade(state *upgrade.State, statePath string, opts UpgradeOptions) (bool, error) {
+	if state.ClusterName != cl.Cfg.ClusterName {
+		return false, fmt.Errorf("the upgrade in progress is for cluster %s but the current cluster is %s", state.ClusterName, cl.Cfg.ClusterName)
+	}
+	if cl.Cfg.K8sVer != state.To {
+		return false, fmt.Errorf("cluster.yaml has kubernetesVersion %s but the upgrade in progress is to %s", cl.Cfg.K8sVer, state.To)
+	}
+
+	for phase := state.Next(); phase != nil; phase = state.Next() {
+		logger.Headingf("Upgrading %s to Kubernetes %s\n", phase.Name, state.To)
+
+		targets := OperationTargetsFromStringSlice(phase.Targets)
+		if err := cl.Apply(targets); err != nil {
+			return false, fmt.Errorf("failed to update %s: %v", phase.Name, err)
+		}
+		if err := cl.waitUntilHealthy(targets, opts.HealthTimeout); err != nil {
+			return false, fmt.Errorf("%s did not become healthy after the update: %v", phase.Name, err)
+		}
+
+		state.Complete(phase.Name)
+		if err := state.WriteToFile(statePath); err != nil {
+			return false, err
+		}
+		logger.Infof("Upgraded %s to Kubernetes %s\n", phase.Name, state.To)
+
+		if opts.Pause && state.Next() != nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// waitUntilHealthy waits until the operation on the stacks of the targets has succeeded and all their instances are in service
+func (cl *Cluster) waitUntilHealthy(targets OperationTargets, timeout time.Duration) error {
+	describer := cfnstack.StatusDescriber{
+		CloudFormation: cloudformation.New(cl.session),
+		AutoScaling:    autoscaling.New(cl.session),
+		EC2:            ec2.New(cl.session),
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		reasons, err := cl.unhealthyReasons(describer, targets)
+		if err != nil {
+			return err
+		}
+		if len(reasons) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s: %s", timeout, strings.Join(reasons, ", "))
+		}
+		logger.Infof("Waiting for %s to become healthy: %s\n", targets.String(), strings.Join(reasons, ", "))
+		time.Sleep(upgradeHealthCheckInterval)
+	}
+}
+
+func (cl *Cluster) unhealthyReasons(describer cfnstack.StatusDescriber, targets OperationTargets) ([]string, error) {
+	_, nested, err := describer.Describe("root", cl.stackName())
+	if err != nil {
+		return nil, err
+	}
+
+	reasons := []string{}
+	for _, target := range targets {
+		stackName, ok := nested[naming.FromStackToCfnResource(target)]
+		if !ok {
+			return nil, fmt.Errorf("stack %s does not exist in stack %s", target, cl.stackName())
+		}
+		status, _, err := describer.Describe(target, stackName)
+		if err != nil {
+			return nil, err
+		}
+		reasons = append(reasons, status.Unhealthy()...)
+	}
+	return reasons, nil
+}
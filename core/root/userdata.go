@@ -0,0 +1,109 @@
+package root
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/pkg/model"
+)
+
+// UserDataRoles are the roles of the nodes whose userdata can be rendered by RenderUserData
+var UserDataRoles = []string{"controller", "etcd", "worker"}
+
+// RenderUserData renders the userdata of a node of the role, i.e. the instance part run on boot followed by the S3 part
+// the instance part fetches, which is the cloud-config including customFiles, customSystemdUnits and plugin contributions.
+// `pool` is the node pool of a worker and is required only when there is more than one node pool.
+// `index` is the index of an etcd node.
+func (cl *Cluster) RenderUserData(role, pool string, index int) (string, error) {
+	if err := cl.ensureNestedStacksLoaded(); err != nil {
+		return "", err
+	}
+
+	var stack *model.Stack
+	extra := []map[string]interface{}{}
+	switch role {
+	case "controller":
+		stack = cl.controlPlaneStack
+	case "etcd":
+		if index < 0 || index >= len(cl.Cfg.EtcdNodes) {
+			return "", fmt.Errorf("invalid etcd node index %d: the cluster has %d etcd node(s)", index, len(cl.Cfg.EtcdNodes))
+		}
+		stack = cl.etcdStack
+		extra = append(extra, map[string]interface{}{"etcdIndex": index})
+	case "worker":
+		np, err := cl.nodePoolForUserData(pool)
+		if err != nil {
+			return "", err
+		}
+		stack = np
+	default:
+		return "", fmt.Errorf("unknown role %s. Specify one of %s", role, strings.Join(UserDataRoles, ", "))
+	}
+
+	ud := stack.GetUserData(role)
+	if ud == nil {
+		return "", fmt.Errorf("stack %s has no %s userdata", stack.StackName, role)
+	}
+
+	buf := new(bytes.Buffer)
+	if p, ok := ud.Parts[api.USERDATA_INSTANCE_SCRIPT]; ok {
+		script, err := p.Template(extra...)
+		if err != nil {
+			return "", fmt.Errorf("failed to render the instance part of %s userdata: %v", role, err)
+		}
+		fmt.Fprintf(buf, "#\n# The instance part run on boot, from %s\n#\n%s\n", ud.Path, strings.TrimRight(script, "\n"))
+	}
+	if p, ok := ud.Parts[api.USERDATA_S3]; ok {
+		content, err := p.Template()
+		if err != nil {
+			return "", fmt.Errorf("failed to render the S3 part of %s userdata: %v", role, err)
+		}
+		decompressed, err := decompressS3Part(ud.Format, content)
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress the S3 part of %s userdata: %v", role, err)
+		}
+		url, err := p.Asset.S3URL()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate the S3 part of %s userdata: %v", role, err)
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "#\n# The S3 part uploaded to %s\n#\n%s", url, decompressed)
+	}
+	return buf.String(), nil
+}
+
+// decompressS3Part returns the cloud-config with the contents of the files decoded, the indented Ignition config or the shell script as is
+func decompressS3Part(format, content string) (string, error) {
+	switch format {
+	case api.USERDATA_FORMAT_SHELL_SCRIPT:
+		return content, nil
+	case api.USERDATA_FORMAT_CLOUD_CONFIG:
+		return api.DecompressCloudConfig(content)
+	}
+	buf := new(bytes.Buffer)
+	if err := json.Indent(buf, []byte(content), "", "  "); err != nil {
+		return "", err
+	}
+	buf.WriteString("\n")
+	return buf.String(), nil
+}
+
+// nodePoolForUserData returns the node pool named `pool`, or the only node pool when `pool` is empty
+func (cl *Cluster) nodePoolForUserData(pool string) (*model.Stack, error) {
+	names := []string{}
+	for _, np := range cl.nodePoolStacks {
+		if np.StackName == pool || pool == "" && len(cl.nodePoolStacks) == 1 {
+			return np, nil
+		}
+		names = append(names, np.StackName)
+	}
+	if pool == "" {
+		return nil, fmt.Errorf("specify the node pool of the worker. Specify one of %s", strings.Join(names, ", "))
+	}
+	return nil, fmt.Errorf("unknown node pool %s. Specify one of %s", pool, strings.Join(names, ", "))
+}
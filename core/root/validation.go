@@ -0,0 +1,12 @@
+package root
+
+// ValidationResult is the result of `kube-aws validate`
+type ValidationResult struct {
+	Valid bool `json:"valid" yaml:"valid"`
+	// Offline is true when the stacks have been checked locally by `kube-aws validate --offline`
+	Offline bool `json:"offline" yaml:"offline"`
+	// Errors are the schema violations in cluster.yaml and plugins, or the errors found in the stack templates
+	Errors []string `json:"errors" yaml:"errors"`
+	// Report is the validation report from CloudFormation or the offline checks, if any
+	Report string `json:"report,omitempty" yaml:"report,omitempty"`
+}
@@ -3,6 +3,7 @@ package credential
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/kubernetes-incubator/kube-aws/logger"
 )
@@ -35,3 +36,19 @@ func (e Store) EncryptedCredentialFromPath(filePath string, defaultValue *string
 
 	return cache, nil
 }
+
+// EncryptedSecret returns the ciphertext of `plaintext` cached at `filePath`.enc, or encrypts and caches it when the cache is missing
+// or was made from a different plaintext. Unlike EncryptedCredentialFromPath, the plaintext is never written to disk.
+func (e Store) EncryptedSecret(filePath string, plaintext []byte) (*EncryptedFile, error) {
+	if cache, err := EncryptedCredentialCacheFromPath(filePath, false); err == nil {
+		if fingerprint, err := loadFingerprint(fingerprintFilePath(filePath)); err == nil && fingerprint == calculateFingerprint(plaintext) {
+			return cache, nil
+		}
+		logger.Debugf("\"%s\" is not up-to-date. kube-aws is regenerating it\n", cacheFilePath(filePath))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %v", filePath, err)
+	}
+	return CreateEncryptedFile(filePath, plaintext, e.Encryptor)
+}
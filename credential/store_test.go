@@ -0,0 +1,35 @@
+package credential
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube-aws-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := NewKMSConfig("keyarn", &dummyEncryptService{}, nil).Store()
+	path := filepath.Join(dir, "secrets", "controller", "etc", "token")
+
+	created, err := store.EncryptedSecret(path, []byte("s3cr3t"))
+	require.NoError(t, err)
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "the plaintext must not be written to disk")
+
+	// The dummy encrypt service produces a different ciphertext for each encryption
+	read, err := store.EncryptedSecret(path, []byte("s3cr3t"))
+	require.NoError(t, err)
+	assert.Equal(t, created.String(), read.String(), "the cached ciphertext must be reused to not replace nodes")
+
+	updated, err := store.EncryptedSecret(path, []byte("upd4ted"))
+	require.NoError(t, err)
+	assert.NotEqual(t, created.String(), updated.String(), "the secret must be re-encrypted when updated")
+}
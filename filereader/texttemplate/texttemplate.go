@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"text/template"
@@ -16,6 +18,18 @@ import (
 	"github.com/kubernetes-incubator/kube-aws/tmpl"
 )
 
+// OfflinePlaceholder is inserted by insertTemplateFile in place of the credentials missing while rendering offline
+const OfflinePlaceholder = "offline"
+
+// offline is true while templates are rendered without credentials e.g. by `kube-aws validate --offline`
+var offline bool
+
+// SetOffline makes insertTemplateFile insert OfflinePlaceholder in place of the files missing under credentials/,
+// so that plugins can be rendered in a cluster whose credentials are not rendered e.g. in CI
+func SetOffline(enabled bool) {
+	offline = enabled
+}
+
 func ParseFile(filename string, funcs template.FuncMap) (*template.Template, error) {
 	raw, err := ioutil.ReadFile(filename)
 	if err != nil {
@@ -90,14 +104,21 @@ func Parse(name string, raw string, funcs template.FuncMap) (*template.Template,
 				err := t.ExecuteTemplate(&b, name, ctx)
 				return b.String(), err
 			},
-			"insertTemplateFile": func(path string, ctx interface{}) (string, error) {
-				return GetString(path, ctx)
-			},
+			"insertTemplateFile": insertTemplateFile,
 		})
 	}
 	return t, err
 }
 
+func insertTemplateFile(path string, ctx interface{}) (string, error) {
+	if offline && filepath.Base(filepath.Dir(filepath.Clean(path))) == "credentials" {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return OfflinePlaceholder, nil
+		}
+	}
+	return GetString(path, ctx)
+}
+
 func GetBytesBuffer(filename string, data interface{}) (*bytes.Buffer, error) {
 	logger.Debugf("Rendering filename %s with following data: \n%+v", filename, data)
 	tmpl, err := ParseFile(filename, nil)
@@ -1,9 +1,11 @@
 package texttemplate
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var tolabelFunc = funcs2["toLabel"].(func(string) string)
@@ -23,3 +25,26 @@ func TestMultipleSymbolsToReplace(t *testing.T) {
 
 	assert.Equal(t, "https___kubernetes.io_docs_admin_authorization_rbac__referring-to-subjects", label)
 }
+
+func TestInsertTemplateFileOffline(t *testing.T) {
+	render := func(path string) (string, error) {
+		tmpl, err := Parse("template", `{{ insertTemplateFile "`+path+`" . }}`, nil)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		err = tmpl.Execute(&buf, nil)
+		return buf.String(), err
+	}
+
+	_, err := render("credentials/missing.pem")
+	assert.Error(t, err)
+
+	SetOffline(true)
+	defer SetOffline(false)
+
+	content, err := render("credentials/missing.pem")
+	require.NoError(t, err)
+	assert.Equal(t, OfflinePlaceholder, content)
+
+	_, err = render("plugins/missing.yaml")
+	assert.Error(t, err, "only missing credentials are replaced with the placeholder")
+}
@@ -3,11 +3,52 @@ package amiregistry
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
 )
 
+// DefaultURLFormat is the format of the URLs of the amd64 Flatcar release feeds, which takes the release channel
+const DefaultURLFormat = "https://%s.release.flatcar-linux.net/amd64-usr/current/flatcar_production_ami_all.json"
+
+// Feed fetches the IDs of the AMIs of the latest Flatcar releases from the JSON feeds served per release channel.
+// Each feed is fetched once and cached, so that a cluster with many node pools doesn't fetch the same feed again and again
+type Feed struct {
+	// URLFormat takes the release channel e.g. `stable`
+	URLFormat string
+	HTTP      HTTPClient
+
+	mu    sync.Mutex
+	cache map[string][]map[string]string
+}
+
+// NewFeed returns the feed fetching the URLs formatted with `urlFormat` via `client`
+func NewFeed(urlFormat string, client HTTPClient) *Feed {
+	return &Feed{
+		URLFormat: urlFormat,
+		HTTP:      client,
+	}
+}
+
+var defaultFeed = NewFeed(DefaultURLFormat, newHttp())
+
+// DefaultFeed returns the feed served by flatcar-linux.net, shared by all the callers to fetch each feed once per kube-aws run
+func DefaultFeed() *Feed {
+	return defaultFeed
+}
+
+// GetAMI returns the ID of the AMI of the latest release in the channel from the default feed
 func GetAMI(region, channel string) (string, error) {
+	return defaultFeed.GetAMI(region, channel)
+}
 
-	amis, err := GetAMIData(channel)
+// GetAMIData returns the AMIs of the latest release in the channel from the default feed
+func GetAMIData(channel string) ([]map[string]string, error) {
+	return defaultFeed.GetAMIData(channel)
+}
+
+// GetAMI returns the ID of the AMI of the latest release in the channel for the region
+func (f *Feed) GetAMI(region, channel string) (string, error) {
+
+	amis, err := f.GetAMIData(channel)
 
 	if err != nil {
 		return "", fmt.Errorf("uanble to fetch AMI for channel \"%s\": %v", channel, err)
@@ -27,12 +68,22 @@ func GetAMI(region, channel string) (string, error) {
 	return "", fmt.Errorf("could not find \"hvm\" image for region \"%s\" in flatcar channel \"%s\"", region, channel)
 }
 
-func GetAMIData(channel string) ([]map[string]string, error) {
-	url := fmt.Sprintf("https://%s.release.flatcar-linux.net/amd64-usr/current/flatcar_production_ami_all.json", channel)
-	r, err := newHttp().Get(url)
+// GetAMIData returns the AMIs of the latest release in the channel, one per region
+func (f *Feed) GetAMIData(channel string) ([]map[string]string, error) {
+	url := fmt.Sprintf(f.URLFormat, channel)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if amis, ok := f.cache[url]; ok {
+		return amis, nil
+	}
+
+	r, err := f.HTTP.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get AMI data from url \"%s\": %v", channel, err)
+		return nil, fmt.Errorf("failed to get AMI data from url \"%s\": %v", url, err)
 	}
+	defer r.Body.Close()
 
 	if r.StatusCode != 200 {
 		return nil, fmt.Errorf("failed to get AMI data from url \"%s\": invalid status code: %d", url, r.StatusCode)
@@ -44,7 +95,11 @@ func GetAMIData(channel string) ([]map[string]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse AMI data from url \"%s\": %v", url, err)
 	}
-	r.Body.Close()
+
+	if f.cache == nil {
+		f.cache = map[string][]map[string]string{}
+	}
+	f.cache[url] = output["amis"]
 
 	return output["amis"], nil
 }
@@ -0,0 +1,57 @@
+package amiregistry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFeed(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/stable/amd64-usr/current/flatcar_production_ami_all.json":
+			fmt.Fprint(w, `{"amis":[{"name":"eu-west-1","hvm":"ami-stable"},{"name":"us-east-1","pv":"ami-pv"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	feed := NewFeed(server.URL+"/%s/amd64-usr/current/flatcar_production_ami_all.json", server.Client())
+
+	testCases := []struct {
+		region   string
+		channel  string
+		expected string
+		err      string
+	}{
+		{region: "eu-west-1", channel: "stable", expected: "ami-stable"},
+		{region: "eu-west-1", channel: "stable", expected: "ami-stable"},
+		{region: "us-east-1", channel: "stable", err: `could not find "hvm" image for region "us-east-1" in flatcar channel "stable"`},
+		{region: "eu-west-1", channel: "beta", err: fmt.Sprintf(`uanble to fetch AMI for channel "beta": failed to get AMI data from url "%s/beta/amd64-usr/current/flatcar_production_ami_all.json": invalid status code: 404`, server.URL)},
+	}
+
+	for _, tc := range testCases {
+		ami, err := feed.GetAMI(tc.region, tc.channel)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("expected error \"%s\" for %+v, but got: %v", tc.err, tc, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %+v: %v", tc, err)
+			continue
+		}
+		if ami != tc.expected {
+			t.Errorf("expected %s for %+v, but got %s", tc.expected, tc, ami)
+		}
+	}
+
+	if n := requests["/stable/amd64-usr/current/flatcar_production_ami_all.json"]; n != 1 {
+		t.Errorf("expected the stable feed to be fetched once and then cached, but it was fetched %d times", n)
+	}
+}
@@ -8,7 +8,8 @@ import (
 
 const MaxRetryCount = 2
 
-type reliableHttp interface {
+// HTTPClient sends HTTP GET requests. It is satisfied by *http.Client, so that tests can serve AMI data from a local server
+type HTTPClient interface {
 	Get(url string) (resp *http.Response, err error)
 }
 
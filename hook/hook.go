@@ -0,0 +1,82 @@
+package hook
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/kubernetes-incubator/kube-aws/logger"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+)
+
+// The points at which hooks run
+const (
+	PreRender  = "preRender"
+	PostRender = "postRender"
+	PreApply   = "preApply"
+	PostApply  = "postApply"
+	OnFailure  = "onFailure"
+)
+
+// Context is the context of the operation passed to hooks as JSON on stdin
+type Context struct {
+	// Hook is the point at which the hook runs e.g. `preApply`
+	Hook string `json:"hook"`
+	// Operation is one of `render`, `validate` and `apply`
+	Operation   string   `json:"operation"`
+	ClusterName string   `json:"clusterName"`
+	Targets     []string `json:"targets"`
+	// Templates are the paths to the rendered stack templates keyed by stack name, given to postRender hooks
+	Templates map[string]string `json:"templates,omitempty"`
+	// Diff summarizes the changes to the existing stacks, given to preApply hooks
+	Diff []StackDiff `json:"diff,omitempty"`
+	// Outputs are the outputs of the stacks keyed by stack name, given to postApply hooks
+	Outputs map[string]map[string]string `json:"outputs,omitempty"`
+	// Error is the error failing the operation, given to onFailure hooks
+	Error string `json:"error,omitempty"`
+}
+
+// StackDiff is the summary of the changes to a stack
+type StackDiff struct {
+	Target    string   `json:"target"`
+	Changes   int      `json:"changes"`
+	Resources []string `json:"resources"`
+}
+
+// Run runs `hooks` in order in `dir` with `ctx` on stdin, and fails on the first hook exiting non-zero.
+// The output of every hook is logged.
+func Run(point string, hooks []api.Hook, ctx Context, dir string) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	ctx.Hook = point
+	input, err := json.Marshal(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hook context: %v", err)
+	}
+
+	for _, h := range hooks {
+		log := logger.With(logger.Fields{"cluster": ctx.ClusterName, "phase": ctx.Operation, "hook": point, "name": h.String()})
+		log.Infof("Running %s hook %s\n", point, h)
+
+		cmd := exec.Command("/bin/sh", "-c", h.Command)
+		cmd.Dir = dir
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Env = append(os.Environ(), "KUBE_AWS_HOOK="+point, "KUBE_AWS_CLUSTER_NAME="+ctx.ClusterName)
+		out, err := cmd.CombinedOutput()
+
+		scanner := bufio.NewScanner(bytes.NewReader(out))
+		for scanner.Scan() {
+			log.Info(scanner.Text())
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s hook %s failed: %v", point, h, err)
+		}
+	}
+	return nil
+}
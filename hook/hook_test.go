@@ -0,0 +1,60 @@
+package hook
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube-aws-hook")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := Context{
+		Operation:   "apply",
+		ClusterName: "mycluster",
+		Targets:     []string{"control-plane"},
+		Diff:        []StackDiff{{Target: "controller-stack", Changes: 2, Resources: []string{"Controllers"}}},
+	}
+
+	t.Run("PassesContext", func(t *testing.T) {
+		hooks := []api.Hook{
+			{Name: "save", Command: "cat > context.json"},
+			{Command: `echo "$KUBE_AWS_HOOK $KUBE_AWS_CLUSTER_NAME" > env`},
+		}
+		require.NoError(t, Run(PreApply, hooks, ctx, dir))
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, "context.json"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+  "hook": "preApply",
+  "operation": "apply",
+  "clusterName": "mycluster",
+  "targets": ["control-plane"],
+  "diff": [{"target": "controller-stack", "changes": 2, "resources": ["Controllers"]}]
+}`, string(data))
+
+		env, err := ioutil.ReadFile(filepath.Join(dir, "env"))
+		require.NoError(t, err)
+		assert.Equal(t, "preApply mycluster\n", string(env))
+	})
+
+	t.Run("StopsOnFailure", func(t *testing.T) {
+		hooks := []api.Hook{
+			{Name: "check", Command: "echo denied; exit 3"},
+			{Command: "touch never"},
+		}
+		err := Run(PreRender, hooks, ctx, dir)
+		require.Error(t, err)
+		assert.Equal(t, "preRender hook check failed: exit status 3", err.Error())
+
+		_, err = os.Stat(filepath.Join(dir, "never"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
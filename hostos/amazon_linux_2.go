@@ -0,0 +1,81 @@
+package hostos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/ami"
+)
+
+// amazonLinux2AMIParameter is the SSM public parameter holding the ID of the latest amd64 AMI of Amazon Linux 2 in each region
+const amazonLinux2AMIParameter = "/aws/service/ami-amazon-linux-latest/amzn2-ami-hvm-x86_64-gp2"
+
+// amazonLinux2 is Amazon Linux 2, which worker node pools can run.
+// As it has neither coreos-cloudinit nor Ignition, nodes are provisioned with the cloud-config converted to a bash script
+type amazonLinux2 struct{}
+
+func (amazonLinux2) Name() string {
+	return AmazonLinux2
+}
+
+func (amazonLinux2) AMISource() ami.Source {
+	return ami.NewSSMParameters(amazonLinux2AMIParameter)
+}
+
+func (amazonLinux2) UserDataFormats() []string {
+	return []string{UserDataFormatShellScript}
+}
+
+func (amazonLinux2) Packages() []string {
+	return []string{
+		"aws-cfn-bootstrap",
+		"awscli",
+		"conntrack-tools",
+		"docker",
+		"ebtables",
+		"ethtool",
+		"ipset",
+		"jq",
+		"nfs-utils",
+		"socat",
+	}
+}
+
+func (amazonLinux2) BinDir() string {
+	return "/usr/local/bin"
+}
+
+// Path includes /opt/aws/bin, which aws-cfn-bootstrap installs cfn-init and cfn-signal to
+func (amazonLinux2) Path() string {
+	return "/opt/bin:/opt/aws/bin:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+}
+
+func (amazonLinux2) HasRkt() bool {
+	return false
+}
+
+// HasDockerOpts is false, as docker.service reads $OPTIONS from /etc/sysconfig/docker, which overrides the variables set in drop-ins
+func (amazonLinux2) HasDockerOpts() bool {
+	return false
+}
+
+// UpdateServices is yum-cron, which updates packages automatically when installed
+func (amazonLinux2) UpdateServices() []string {
+	return []string{"yum-cron.service"}
+}
+
+// Bootstrap installs the packages and provides the environment variables which coreos-metadata writes to /etc/environment on Flatcar
+func (os amazonLinux2) Bootstrap() string {
+	return fmt.Sprintf(`# Prepare Amazon Linux 2 to be provisioned by kube-aws
+export PATH=%s
+until yum install -y %s; do
+  sleep 5
+done
+systemctl enable docker.service
+mkdir -p /var/run/coreos /opt/bin
+if ! grep -q '^COREOS_PRIVATE_IPV4=' /etc/environment; then
+  echo "COREOS_PRIVATE_IPV4=$(curl -s -f http://169.254.169.254/latest/meta-data/local-ipv4)" >>/etc/environment
+  echo "COREOS_PUBLIC_IPV4=$(curl -s -f http://169.254.169.254/latest/meta-data/public-ipv4 || :)" >>/etc/environment
+fi
+`, os.Path(), strings.Join(os.Packages(), " "))
+}
@@ -0,0 +1,50 @@
+package hostos
+
+import (
+	"github.com/kubernetes-incubator/kube-aws/ami"
+)
+
+// flatcar is Flatcar Container Linux, which is what controller and etcd nodes run
+type flatcar struct{}
+
+func (flatcar) Name() string {
+	return Flatcar
+}
+
+func (flatcar) AMISource() ami.Source {
+	return ami.NewFlatcarFeed()
+}
+
+func (flatcar) UserDataFormats() []string {
+	return []string{UserDataFormatCloudConfig, UserDataFormatIgnition}
+}
+
+// Packages is empty as Flatcar has no package manager. Everything kube-aws needs is either shipped with Flatcar or run in containers
+func (flatcar) Packages() []string {
+	return nil
+}
+
+// BinDir is /opt/bin as /usr is read-only
+func (flatcar) BinDir() string {
+	return "/opt/bin"
+}
+
+func (flatcar) Path() string {
+	return "/opt/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+}
+
+func (flatcar) HasRkt() bool {
+	return true
+}
+
+func (flatcar) HasDockerOpts() bool {
+	return true
+}
+
+func (flatcar) UpdateServices() []string {
+	return []string{"update-engine.service", "locksmithd.service"}
+}
+
+func (flatcar) Bootstrap() string {
+	return ""
+}
@@ -0,0 +1,78 @@
+package hostos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/ami"
+)
+
+const (
+	Flatcar      = "flatcar"
+	AmazonLinux2 = "amazon-linux-2"
+)
+
+// The userdata formats nodes can be provisioned with, which are the values of `userDataFormat` in cluster.yaml
+const (
+	UserDataFormatCloudConfig = "cloud-config"
+	UserDataFormatIgnition    = "ignition"
+	// UserDataFormatShellScript is the cloud-config converted to a bash script for the OSes without coreos-cloudinit and Ignition
+	UserDataFormatShellScript = "shell-script"
+)
+
+// OS is an operating system nodes run, which is selected by `hostOS.name` in cluster.yaml
+type OS interface {
+	Name() string
+	// AMISource looks up the latest AMIs of the OS
+	AMISource() ami.Source
+	// UserDataFormats are the userdata formats the OS can be provisioned with. The first one is the default
+	UserDataFormats() []string
+	// Packages are the packages installed by Bootstrap, in addition to the ones shipped with the AMI
+	Packages() []string
+	// BinDir is where kube-aws installs binaries downloaded on nodes e.g. crictl
+	BinDir() string
+	// Path is the PATH of the scripts run on the host, including the directories binaries of the packages are installed to
+	Path() string
+	// HasRkt is true when rkt is available to run e.g. the AWS CLI image. Otherwise the AWS CLI of the host is used
+	HasRkt() bool
+	// HasDockerOpts is true when docker.service passes $DOCKER_OPTS to dockerd. Otherwise dockerd is configured with /etc/docker/daemon.json
+	HasDockerOpts() bool
+	// UpdateServices are the units updating the OS automatically, which are masked when automatic updates are disabled
+	UpdateServices() []string
+	// Bootstrap returns the bash script run at the beginning of the instance script, preparing the node to be provisioned by kube-aws
+	Bootstrap() string
+}
+
+var oses = []OS{flatcar{}, amazonLinux2{}}
+
+// Names returns the names of the supported OSes
+func Names() []string {
+	names := []string{}
+	for _, os := range oses {
+		names = append(names, os.Name())
+	}
+	return names
+}
+
+// Get returns the OS named name. An empty name defaults to Flatcar
+func Get(name string) (OS, error) {
+	if name == "" {
+		name = Flatcar
+	}
+	for _, os := range oses {
+		if os.Name() == name {
+			return os, nil
+		}
+	}
+	return nil, fmt.Errorf("host OS %s is not supported. Specify one of %s", name, strings.Join(Names(), ", "))
+}
+
+// SupportsUserDataFormat returns true when the OS can be provisioned with the userdata format
+func SupportsUserDataFormat(os OS, format string) bool {
+	for _, f := range os.UserDataFormats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package hostos
+
+import (
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-aws/ami"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	os, err := Get("")
+	require.NoError(t, err)
+	assert.Equal(t, Flatcar, os.Name())
+
+	os, err = Get(AmazonLinux2)
+	require.NoError(t, err)
+	assert.Equal(t, AmazonLinux2, os.Name())
+	assert.True(t, SupportsUserDataFormat(os, UserDataFormatShellScript))
+	assert.False(t, SupportsUserDataFormat(os, UserDataFormatIgnition))
+
+	_, err = Get("ubuntu")
+	assert.EqualError(t, err, "host OS ubuntu is not supported. Specify one of flatcar, amazon-linux-2")
+}
+
+func TestAMISource(t *testing.T) {
+	assert.True(t, flatcar{}.AMISource().HasChannels())
+
+	source, ok := amazonLinux2{}.AMISource().(ami.SSMParameters)
+	require.True(t, ok)
+	assert.False(t, source.HasChannels())
+	assert.Equal(t, "/aws/service/ami-amazon-linux-latest/amzn2-ami-hvm-x86_64-gp2", source.Name)
+}
+
+func TestHasDockerOpts(t *testing.T) {
+	assert.True(t, flatcar{}.HasDockerOpts())
+	assert.False(t, amazonLinux2{}.HasDockerOpts(), "docker log rotation must be configured in /etc/docker/daemon.json on Amazon Linux 2")
+}
@@ -0,0 +1,174 @@
+package hostos
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/ignition"
+)
+
+// systemdUnitDir is where the units in a cloud-config are written to
+const systemdUnitDir = "/etc/systemd/system"
+
+// heredocDelimiter is the delimiter of the here documents of the contents of files, units and authorized keys
+const heredocDelimiter = "KUBE_AWS_EOF"
+
+const shellScriptHeader = `#!/bin/bash -e
+# Provisions the node with the users, files and units of the cloud-config rendered by kube-aws
+
+write_file() {
+  mkdir -p "$(dirname "$1")"
+  cat >"$1"
+  chmod "$2" "$1"
+  chown "$3" "$1"
+}
+
+add_to_group() {
+  if getent group "$2" >/dev/null; then
+    usermod -a -G "$2" "$1"
+  fi
+}
+
+authorize_keys() {
+  home=$(getent passwd "$1" | cut -d: -f6)
+  mkdir -p -m 0700 "${home}/.ssh"
+  cat >"${home}/.ssh/authorized_keys"
+  chmod 0600 "${home}/.ssh/authorized_keys"
+  chown -R "$1:" "${home}/.ssh"
+}`
+
+// ShellScriptFromCloudConfig converts the cloud-config to the bash script of the UserDataFormatShellScript format.
+// It is converted as FromCloudConfig of the ignition package does, except that `coreos.update` is ignored as it configures
+// the updates of Flatcar only. Unlike Ignition, the script writes the files and units after systemd starts, and therefore
+// runs the `command`s of the units after reloading and enabling the units.
+func ShellScriptFromCloudConfig(cloudConfig string) (string, error) {
+	c, err := ignition.FromCloudConfig(cloudConfig)
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{shellScriptHeader}
+
+	for _, u := range c.Users {
+		lines = append(lines, "", fmt.Sprintf("# user %s", u.Name))
+		lines = append(lines, fmt.Sprintf("getent passwd %s >/dev/null || useradd%s %s", shellQuote(u.Name), useraddOptions(u), shellQuote(u.Name)))
+		if u.PasswordHash != "" {
+			lines = append(lines, fmt.Sprintf("usermod -p %s %s", shellQuote(u.PasswordHash), shellQuote(u.Name)))
+		}
+		for _, g := range u.Groups {
+			lines = append(lines, fmt.Sprintf("add_to_group %s %s", shellQuote(u.Name), shellQuote(g)))
+		}
+		if len(u.SSHAuthorizedKeys) > 0 {
+			lines = append(lines, heredoc(fmt.Sprintf("authorize_keys %s", shellQuote(u.Name)), strings.Join(u.SSHAuthorizedKeys, "\n")+"\n"))
+		}
+	}
+
+	for _, f := range c.Files {
+		if f.Path == ignition.UpdateConfPath {
+			continue
+		}
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		owner := ownerOf(f.User, f.Group)
+		lines = append(lines, "", heredoc(fmt.Sprintf("write_file %s %04o %s", shellQuote(f.Path), mode, shellQuote(owner)), f.Contents))
+	}
+
+	enabled := []string{}
+	masked := []string{}
+	commands := false
+	for _, u := range c.Units {
+		if u.Contents != "" {
+			lines = append(lines, "", heredoc(fmt.Sprintf("write_file %s 0644 'root:root'", shellQuote(path.Join(systemdUnitDir, u.Name))), u.Contents))
+		}
+		for _, d := range u.Dropins {
+			lines = append(lines, "", heredoc(fmt.Sprintf("write_file %s 0644 'root:root'", shellQuote(path.Join(systemdUnitDir, u.Name+".d", d.Name))), d.Contents))
+		}
+		if u.Mask {
+			masked = append(masked, shellQuote(u.Name))
+		}
+		if u.Enabled {
+			enabled = append(enabled, shellQuote(u.Name))
+		}
+		commands = commands || u.Name == ignition.CommandsUnitName
+	}
+
+	lines = append(lines, "", "systemctl daemon-reload")
+	if len(masked) > 0 {
+		lines = append(lines, "systemctl mask "+strings.Join(masked, " "))
+	}
+	if len(enabled) > 0 {
+		lines = append(lines, "systemctl enable "+strings.Join(enabled, " "))
+	}
+	if commands {
+		lines = append(lines, "systemctl start --no-block "+ignition.CommandsUnitName)
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+func useraddOptions(u ignition.User) string {
+	opts := []string{}
+	if u.System {
+		opts = append(opts, "--system")
+	}
+	if u.Gecos != "" {
+		opts = append(opts, "--comment", shellQuote(u.Gecos))
+	}
+	if u.HomeDir != "" {
+		opts = append(opts, "--home-dir", shellQuote(u.HomeDir))
+	}
+	if u.NoCreateHome {
+		opts = append(opts, "--no-create-home")
+	} else {
+		opts = append(opts, "--create-home")
+	}
+	if u.PrimaryGroup != "" {
+		opts = append(opts, "--gid", shellQuote(u.PrimaryGroup))
+	}
+	if u.NoUserGroup {
+		opts = append(opts, "--no-user-group")
+	}
+	if u.NoLogInit {
+		opts = append(opts, "--no-log-init")
+	}
+	if u.Shell != "" {
+		opts = append(opts, "--shell", shellQuote(u.Shell))
+	}
+	if len(opts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(opts, " ")
+}
+
+func ownerOf(user, group string) string {
+	if user == "" {
+		user = "root"
+	}
+	if group == "" {
+		group = "root"
+	}
+	return user + ":" + group
+}
+
+// heredoc returns the command reading the contents from a here document.
+// The contents are base64-encoded unless they can be written as is, which requires a trailing newline and no line equal to the delimiter
+func heredoc(command, contents string) string {
+	if strings.HasSuffix(contents, "\n") && !strings.Contains("\n"+contents, "\n"+heredocDelimiter+"\n") {
+		return fmt.Sprintf("%s <<'%s'\n%s%s", command, heredocDelimiter, contents, heredocDelimiter)
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte(contents))
+	wrapped := []string{}
+	for len(encoded) > 76 {
+		wrapped = append(wrapped, encoded[:76])
+		encoded = encoded[76:]
+	}
+	return fmt.Sprintf("base64 -d <<'%s' | %s\n%s\n%s", heredocDelimiter, command, strings.Join(append(wrapped, encoded), "\n"), heredocDelimiter)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,104 @@
+package hostos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellScriptFromCloudConfig(t *testing.T) {
+	script, err := ShellScriptFromCloudConfig(`#cloud-config
+coreos:
+  update:
+    reboot-strategy: "off"
+  units:
+  - name: yum-cron.service
+    mask: true
+  - name: docker.service
+    enable: true
+    drop-ins:
+    - name: 10-opts.conf
+      content: |
+        [Service]
+        Environment=DOCKER_OPTS=--foo
+  - name: kubelet.service
+    command: start
+    content: |
+      [Service]
+      ExecStart=/opt/bin/kubelet
+ssh_authorized_keys:
+- ssh-rsa AAAA
+users:
+- name: nvidia-persistenced
+  homedir: /
+  shell: /sbin/nologin
+  groups: [video]
+write_files:
+- path: /etc/foo.env
+  permissions: 0600
+  owner: etcd:etcd
+  content: |
+    foo=bar
+- path: /etc/kubernetes/ssl/ca.pem
+  content: "no newline"
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, shellScriptHeader+`
+
+# user nvidia-persistenced
+getent passwd 'nvidia-persistenced' >/dev/null || useradd --home-dir '/' --create-home --shell '/sbin/nologin' 'nvidia-persistenced'
+add_to_group 'nvidia-persistenced' 'video'
+
+# user core
+getent passwd 'core' >/dev/null || useradd --create-home 'core'
+authorize_keys 'core' <<'KUBE_AWS_EOF'
+ssh-rsa AAAA
+KUBE_AWS_EOF
+
+write_file '/etc/foo.env' 0600 'etcd:etcd' <<'KUBE_AWS_EOF'
+foo=bar
+KUBE_AWS_EOF
+
+base64 -d <<'KUBE_AWS_EOF' | write_file '/etc/kubernetes/ssl/ca.pem' 0644 'root:root'
+bm8gbmV3bGluZQ==
+KUBE_AWS_EOF
+
+write_file '/etc/systemd/system/docker.service.d/10-opts.conf' 0644 'root:root' <<'KUBE_AWS_EOF'
+[Service]
+Environment=DOCKER_OPTS=--foo
+KUBE_AWS_EOF
+
+write_file '/etc/systemd/system/kubelet.service' 0644 'root:root' <<'KUBE_AWS_EOF'
+[Service]
+ExecStart=/opt/bin/kubelet
+KUBE_AWS_EOF
+
+write_file '/etc/systemd/system/cloud-config-commands.service' 0644 'root:root' <<'KUBE_AWS_EOF'
+[Unit]
+Description=Run the commands of the units in the cloud-config converted to Ignition
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/usr/bin/systemctl --no-block start kubelet.service
+
+[Install]
+WantedBy=multi-user.target
+KUBE_AWS_EOF
+
+systemctl daemon-reload
+systemctl mask 'yum-cron.service'
+systemctl enable 'docker.service' 'cloud-config-commands.service'
+systemctl start --no-block cloud-config-commands.service
+`, script)
+}
+
+func TestHeredoc(t *testing.T) {
+	assert.Equal(t, "cat <<'KUBE_AWS_EOF'\nfoo\nKUBE_AWS_EOF", heredoc("cat", "foo\n"))
+	assert.Equal(t, "base64 -d <<'KUBE_AWS_EOF' | cat\nS1VCRV9BV1NfRU9GCg==\nKUBE_AWS_EOF", heredoc("cat", "KUBE_AWS_EOF\n"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
@@ -0,0 +1,194 @@
+package ignition
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	cloudinit "github.com/coreos/coreos-cloudinit/config"
+)
+
+// CommandsUnitName is the unit which runs the `command`s of the units in a cloud-config converted by FromCloudConfig
+const CommandsUnitName = "cloud-config-commands.service"
+
+// UpdateConfPath is the file `coreos.update` in a cloud-config is converted to
+const UpdateConfPath = "/etc/flatcar/update.conf"
+
+// FromCloudConfig converts the cloud-config consisting of `ssh_authorized_keys`, `hostname`, `users`, `write_files`,
+// `coreos.update` and `coreos.units` to the Butane-style config.
+// As Ignition writes files and enables units before systemd starts, the `command`s of the units such as `start` are run
+// by the unit named CommandsUnitName in the order of the units, as coreos-cloudinit does after writing files.
+// `runtime` units are persisted under /etc, and a file or a unit defined more than once is merged into the last definition.
+func FromCloudConfig(cloudConfig string) (*Config, error) {
+	cc, err := cloudinit.NewCloudConfig(cloudConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cloud-config: %v", err)
+	}
+
+	unsupported := []string{}
+	for key, value := range map[string]interface{}{
+		"manage_etc_hosts": cc.ManageEtcHosts,
+		"coreos.etcd":      cc.CoreOS.Etcd,
+		"coreos.etcd2":     cc.CoreOS.Etcd2,
+		"coreos.flannel":   cc.CoreOS.Flannel,
+		"coreos.fleet":     cc.CoreOS.Fleet,
+		"coreos.locksmith": cc.CoreOS.Locksmith,
+		"coreos.oem":       cc.CoreOS.OEM,
+	} {
+		if !cloudinit.IsZero(value) {
+			unsupported = append(unsupported, key)
+		}
+	}
+	for _, u := range cc.Users {
+		if u.SSHImportGithubUser != "" || len(u.SSHImportGithubUsers) > 0 || u.SSHImportURL != "" {
+			unsupported = append(unsupported, fmt.Sprintf("importing ssh keys of user %s", u.Name))
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return nil, fmt.Errorf("%s in cloud-config can't be converted to Ignition", strings.Join(unsupported, ", "))
+	}
+
+	c := &Config{}
+
+	for _, u := range cc.Users {
+		c.Users = append(c.Users, User{
+			Name:              u.Name,
+			PasswordHash:      u.PasswordHash,
+			SSHAuthorizedKeys: u.SSHAuthorizedKeys,
+			Gecos:             u.GECOS,
+			HomeDir:           u.Homedir,
+			NoCreateHome:      u.NoCreateHome,
+			PrimaryGroup:      u.PrimaryGroup,
+			Groups:            u.Groups,
+			NoUserGroup:       u.NoUserGroup,
+			NoLogInit:         u.NoLogInit,
+			Shell:             u.Shell,
+			System:            u.System,
+		})
+	}
+	if len(cc.SSHAuthorizedKeys) > 0 {
+		core := -1
+		for i, u := range c.Users {
+			if u.Name == "core" {
+				core = i
+			}
+		}
+		if core < 0 {
+			c.Users = append(c.Users, User{Name: "core"})
+			core = len(c.Users) - 1
+		}
+		c.Users[core].SSHAuthorizedKeys = append(c.Users[core].SSHAuthorizedKeys, cc.SSHAuthorizedKeys...)
+	}
+
+	if cc.Hostname != "" {
+		c.addFile(File{Path: "/etc/hostname", Contents: cc.Hostname + "\n"})
+	}
+
+	update := []string{}
+	for _, kv := range [][]string{{"REBOOT_STRATEGY", cc.CoreOS.Update.RebootStrategy}, {"GROUP", cc.CoreOS.Update.Group}, {"SERVER", cc.CoreOS.Update.Server}} {
+		if kv[1] != "" {
+			update = append(update, fmt.Sprintf("%s=%s\n", kv[0], kv[1]))
+		}
+	}
+	if len(update) > 0 {
+		c.addFile(File{Path: UpdateConfPath, Contents: strings.Join(update, "")})
+	}
+
+	for _, f := range cc.WriteFiles {
+		contents, err := cloudinit.DecodeContent(f.Content, f.Encoding)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the content of %s: %v", f.Path, err)
+		}
+		file := File{Path: f.Path, Contents: string(contents)}
+		if f.RawFilePermissions != "" {
+			mode, err := strconv.ParseInt(f.RawFilePermissions, 8, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid permissions of %s: %v", f.Path, err)
+			}
+			file.Mode = int(mode)
+		}
+		if f.Owner != "" {
+			owner := strings.SplitN(f.Owner, ":", 2)
+			file.User = owner[0]
+			if len(owner) == 2 {
+				file.Group = owner[1]
+			}
+		}
+		c.addFile(file)
+	}
+
+	commands := []string{}
+	for _, u := range cc.CoreOS.Units {
+		unit := Unit{Name: u.Name, Enabled: u.Enable, Mask: u.Mask, Contents: u.Content}
+		for _, d := range u.DropIns {
+			unit.Dropins = append(unit.Dropins, Dropin{Name: d.Name, Contents: d.Content})
+		}
+		c.addUnit(unit)
+		if u.Command != "" {
+			commands = append(commands, fmt.Sprintf("ExecStart=/usr/bin/systemctl --no-block %s %s\n", u.Command, u.Name))
+		}
+	}
+	if len(commands) > 0 {
+		c.addUnit(Unit{
+			Name:    CommandsUnitName,
+			Enabled: true,
+			Contents: `[Unit]
+Description=Run the commands of the units in the cloud-config converted to Ignition
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+` + strings.Join(commands, "") + `
+[Install]
+WantedBy=multi-user.target
+`,
+		})
+	}
+
+	return c, nil
+}
+
+// addFile adds the file, replacing the file with the same path
+func (c *Config) addFile(f File) {
+	for i := range c.Files {
+		if c.Files[i].Path == f.Path {
+			c.Files[i] = f
+			return
+		}
+	}
+	c.Files = append(c.Files, f)
+}
+
+// addUnit adds the unit, or merges it into the unit with the same name
+func (c *Config) addUnit(u Unit) {
+	for i := range c.Units {
+		existing := &c.Units[i]
+		if existing.Name != u.Name {
+			continue
+		}
+		existing.Enabled = existing.Enabled || u.Enabled
+		existing.Mask = existing.Mask || u.Mask
+		if u.Contents != "" {
+			existing.Contents = u.Contents
+		}
+		for _, d := range u.Dropins {
+			existing.addDropin(d)
+		}
+		return
+	}
+	c.Units = append(c.Units, u)
+}
+
+func (u *Unit) addDropin(d Dropin) {
+	for i := range u.Dropins {
+		if u.Dropins[i].Name == d.Name {
+			u.Dropins[i] = d
+			return
+		}
+	}
+	u.Dropins = append(u.Dropins, d)
+}
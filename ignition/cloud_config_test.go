@@ -0,0 +1,80 @@
+package ignition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromCloudConfig(t *testing.T) {
+	c, err := FromCloudConfig(`#cloud-config
+coreos:
+  update:
+    reboot-strategy: "off"
+  units:
+  - name: docker.service
+    drop-ins:
+    - name: 10-opts.conf
+      content: |
+        [Service]
+        Environment=DOCKER_OPTS=--foo
+  - name: kubelet.service
+    command: start
+    content: |
+      [Service]
+      ExecStart=/opt/bin/kubelet
+  - name: update-engine.service
+    mask: true
+  - name: docker.service
+    enable: true
+    drop-ins:
+    - name: 20-more.conf
+      content: |
+        [Unit]
+        After=foo.service
+ssh_authorized_keys:
+- ssh-rsa AAAA
+users:
+- name: nvidia-persistenced
+  homedir: /
+  shell: /sbin/nologin
+write_files:
+- path: /etc/foo.env
+  permissions: 0600
+  owner: etcd:etcd
+  encoding: gzip+base64
+  content: H4sIAAAAAAACA0vLz7dNSiziAgAU562LCAAAAA==
+- path: /etc/foo.env
+  content: |
+    foo=baz
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []User{
+		{Name: "nvidia-persistenced", HomeDir: "/", Shell: "/sbin/nologin"},
+		{Name: "core", SSHAuthorizedKeys: []string{"ssh-rsa AAAA"}},
+	}, c.Users)
+
+	assert.Equal(t, []File{
+		{Path: UpdateConfPath, Contents: "REBOOT_STRATEGY=off\n"},
+		{Path: "/etc/foo.env", Contents: "foo=baz\n"},
+	}, c.Files)
+
+	require.Len(t, c.Units, 4)
+	assert.Equal(t, Unit{Name: "docker.service", Enabled: true, Dropins: []Dropin{
+		{Name: "10-opts.conf", Contents: "[Service]\nEnvironment=DOCKER_OPTS=--foo\n"},
+		{Name: "20-more.conf", Contents: "[Unit]\nAfter=foo.service\n"},
+	}}, c.Units[0])
+	assert.Equal(t, Unit{Name: "kubelet.service", Contents: "[Service]\nExecStart=/opt/bin/kubelet\n"}, c.Units[1])
+	assert.Equal(t, Unit{Name: "update-engine.service", Mask: true}, c.Units[2])
+	assert.Equal(t, CommandsUnitName, c.Units[3].Name)
+	assert.True(t, c.Units[3].Enabled)
+	assert.Contains(t, c.Units[3].Contents, "ExecStart=/usr/bin/systemctl --no-block start kubelet.service\n")
+
+	_, err = c.Render()
+	assert.NoError(t, err)
+
+	_, err = FromCloudConfig("#cloud-config\ncoreos:\n  flannel:\n    interface: eth0\n  etcd2:\n    name: foo\n")
+	assert.EqualError(t, err, "coreos.etcd2, coreos.flannel in cloud-config can't be converted to Ignition")
+}
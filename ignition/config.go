@@ -0,0 +1,141 @@
+package ignition
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubernetes-incubator/kube-aws/gzipcompressor"
+)
+
+// Version is the version of the Ignition config spec rendered by Config.Render
+const Version = "3.3.0"
+
+// compressThreshold is the size of file contents in bytes from which they are gzipped
+const compressThreshold = 1024
+
+// Config is a Butane-style description of the files, units and users of a machine, which is rendered as an Ignition v3 config
+type Config struct {
+	// Merge are the URLs of the Ignition configs merged into this config e.g. `s3://bucket/key`
+	Merge []string
+	Users []User
+	Files []File
+	Units []Unit
+}
+
+type User struct {
+	Name              string
+	PasswordHash      string
+	SSHAuthorizedKeys []string
+	Gecos             string
+	HomeDir           string
+	NoCreateHome      bool
+	PrimaryGroup      string
+	Groups            []string
+	NoUserGroup       bool
+	NoLogInit         bool
+	Shell             string
+	System            bool
+}
+
+// File is a file overwritten on the first boot
+type File struct {
+	Path string
+	// Mode is the permissions of the file e.g. 0644, which is the default
+	Mode int
+	// User and Group are the names of the owner, which defaults to root
+	User     string
+	Group    string
+	Contents string
+	// Source is the URL of the contents used instead of Contents e.g. `s3://bucket/key`
+	Source string
+}
+
+type Unit struct {
+	Name     string
+	Enabled  bool
+	Mask     bool
+	Contents string
+	Dropins  []Dropin
+}
+
+type Dropin struct {
+	Name     string
+	Contents string
+}
+
+// Render returns the Ignition config in JSON, validated with Validate
+func (c Config) Render() (string, error) {
+	s := spec{Ignition: ignitionSection{Version: Version}}
+
+	for _, m := range c.Merge {
+		if s.Ignition.Config == nil {
+			s.Ignition.Config = &ignitionConfig{}
+		}
+		s.Ignition.Config.Merge = append(s.Ignition.Config.Merge, resource{Source: m})
+	}
+
+	for _, u := range c.Users {
+		if s.Passwd == nil {
+			s.Passwd = &passwd{}
+		}
+		s.Passwd.Users = append(s.Passwd.Users, passwdUser(u))
+	}
+
+	for _, f := range c.Files {
+		if s.Storage == nil {
+			s.Storage = &storage{}
+		}
+		contents := resource{Source: f.Source}
+		if f.Source == "" {
+			var err error
+			if contents, err = dataURL(f.Contents); err != nil {
+				return "", fmt.Errorf("failed to encode the contents of %s: %v", f.Path, err)
+			}
+		}
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		file := file{Path: f.Path, Overwrite: true, Mode: mode, Contents: contents}
+		if f.User != "" {
+			file.User = &nodeUser{Name: f.User}
+		}
+		if f.Group != "" {
+			file.Group = &nodeGroup{Name: f.Group}
+		}
+		s.Storage.Files = append(s.Storage.Files, file)
+	}
+
+	for _, u := range c.Units {
+		if s.Systemd == nil {
+			s.Systemd = &systemd{}
+		}
+		unit := unit{Name: u.Name, Enabled: u.Enabled, Mask: u.Mask, Contents: u.Contents}
+		for _, d := range u.Dropins {
+			unit.Dropins = append(unit.Dropins, dropin(d))
+		}
+		s.Systemd.Units = append(s.Systemd.Units, unit)
+	}
+
+	rendered, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to render Ignition config: %v", err)
+	}
+	if err := Validate(rendered); err != nil {
+		return "", err
+	}
+	return string(rendered), nil
+}
+
+// dataURL returns the contents as a data URL, gzipped when they are large
+func dataURL(contents string) (resource, error) {
+	if len(contents) < compressThreshold {
+		return resource{Source: "data:;base64," + base64.StdEncoding.EncodeToString([]byte(contents))}, nil
+	}
+	gzipped, err := gzipcompressor.BytesToGzippedBytes([]byte(contents))
+	if err != nil {
+		return resource{}, err
+	}
+	return resource{Source: "data:;base64," + base64.StdEncoding.EncodeToString(gzipped), Compression: "gzip"}, nil
+}
@@ -0,0 +1,81 @@
+package ignition
+
+// spec is the subset of the Ignition v3 config spec rendered and validated by this package.
+// See https://coreos.github.io/ignition/configuration-v3_3/
+type spec struct {
+	Ignition ignitionSection `json:"ignition"`
+	Passwd   *passwd         `json:"passwd,omitempty"`
+	Storage  *storage        `json:"storage,omitempty"`
+	Systemd  *systemd        `json:"systemd,omitempty"`
+}
+
+type ignitionSection struct {
+	Version string          `json:"version"`
+	Config  *ignitionConfig `json:"config,omitempty"`
+}
+
+type ignitionConfig struct {
+	Merge []resource `json:"merge,omitempty"`
+}
+
+type resource struct {
+	Source      string `json:"source,omitempty"`
+	Compression string `json:"compression,omitempty"`
+}
+
+type passwd struct {
+	Users []passwdUser `json:"users,omitempty"`
+}
+
+type passwdUser struct {
+	Name              string   `json:"name"`
+	PasswordHash      string   `json:"passwordHash,omitempty"`
+	SSHAuthorizedKeys []string `json:"sshAuthorizedKeys,omitempty"`
+	Gecos             string   `json:"gecos,omitempty"`
+	HomeDir           string   `json:"homeDir,omitempty"`
+	NoCreateHome      bool     `json:"noCreateHome,omitempty"`
+	PrimaryGroup      string   `json:"primaryGroup,omitempty"`
+	Groups            []string `json:"groups,omitempty"`
+	NoUserGroup       bool     `json:"noUserGroup,omitempty"`
+	NoLogInit         bool     `json:"noLogInit,omitempty"`
+	Shell             string   `json:"shell,omitempty"`
+	System            bool     `json:"system,omitempty"`
+}
+
+type storage struct {
+	Files []file `json:"files,omitempty"`
+}
+
+type file struct {
+	Path      string     `json:"path"`
+	Overwrite bool       `json:"overwrite,omitempty"`
+	Mode      int        `json:"mode,omitempty"`
+	User      *nodeUser  `json:"user,omitempty"`
+	Group     *nodeGroup `json:"group,omitempty"`
+	Contents  resource   `json:"contents"`
+}
+
+type nodeUser struct {
+	Name string `json:"name,omitempty"`
+}
+
+type nodeGroup struct {
+	Name string `json:"name,omitempty"`
+}
+
+type systemd struct {
+	Units []unit `json:"units,omitempty"`
+}
+
+type unit struct {
+	Name     string   `json:"name"`
+	Enabled  bool     `json:"enabled,omitempty"`
+	Mask     bool     `json:"mask,omitempty"`
+	Contents string   `json:"contents,omitempty"`
+	Dropins  []dropin `json:"dropins,omitempty"`
+}
+
+type dropin struct {
+	Name     string `json:"name"`
+	Contents string `json:"contents,omitempty"`
+}
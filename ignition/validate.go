@@ -0,0 +1,192 @@
+package ignition
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// unitTypes are the suffixes of the names of systemd units
+var unitTypes = []string{".service", ".socket", ".device", ".mount", ".automount", ".swap", ".target", ".path", ".timer", ".slice", ".scope"}
+
+// sourceSchemes are the URL schemes Ignition fetches configs and file contents from
+var sourceSchemes = []string{"data", "http", "https", "s3", "tftp", "gs", "arn"}
+
+// Validate checks the Ignition config locally as Ignition would do on boot, except for fetching remote contents.
+// All the problems found are reported at once.
+func Validate(config []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(config))
+	dec.DisallowUnknownFields()
+	s := spec{}
+	if err := dec.Decode(&s); err != nil {
+		return fmt.Errorf("failed to parse Ignition config: %v", err)
+	}
+
+	errs := []string{}
+	if s.Ignition.Version != Version {
+		errs = append(errs, fmt.Sprintf("ignition.version: expected %s but was \"%s\"", Version, s.Ignition.Version))
+	}
+
+	if s.Ignition.Config != nil {
+		for i, m := range s.Ignition.Config.Merge {
+			if err := validateResource(m); err != nil {
+				errs = append(errs, fmt.Sprintf("ignition.config.merge[%d]: %v", i, err))
+			}
+		}
+	}
+
+	if s.Passwd != nil {
+		users := map[string]bool{}
+		for i, u := range s.Passwd.Users {
+			switch {
+			case u.Name == "":
+				errs = append(errs, fmt.Sprintf("passwd.users[%d]: name is required", i))
+			case users[u.Name]:
+				errs = append(errs, fmt.Sprintf("passwd.users[%d]: duplicate user %s", i, u.Name))
+			}
+			users[u.Name] = true
+		}
+	}
+
+	if s.Storage != nil {
+		files := map[string]bool{}
+		for i, f := range s.Storage.Files {
+			switch {
+			case !path.IsAbs(f.Path) || path.Clean(f.Path) != f.Path:
+				errs = append(errs, fmt.Sprintf("storage.files[%d]: path must be absolute and clean but was \"%s\"", i, f.Path))
+			case files[f.Path]:
+				errs = append(errs, fmt.Sprintf("storage.files[%d]: duplicate file %s", i, f.Path))
+			}
+			files[f.Path] = true
+			if f.Mode < 0 || f.Mode > 07777 {
+				errs = append(errs, fmt.Sprintf("storage.files[%d]: invalid mode %o of %s", i, f.Mode, f.Path))
+			}
+			if err := validateResource(f.Contents); err != nil {
+				errs = append(errs, fmt.Sprintf("storage.files[%d]: contents of %s: %v", i, f.Path, err))
+			}
+		}
+	}
+
+	if s.Systemd != nil {
+		units := map[string]bool{}
+		for i, u := range s.Systemd.Units {
+			if !hasUnitType(u.Name) {
+				errs = append(errs, fmt.Sprintf("systemd.units[%d]: invalid unit name \"%s\"", i, u.Name))
+			}
+			if units[u.Name] {
+				errs = append(errs, fmt.Sprintf("systemd.units[%d]: duplicate unit %s", i, u.Name))
+			}
+			units[u.Name] = true
+			if err := validateUnitContents(u.Contents); err != nil {
+				errs = append(errs, fmt.Sprintf("systemd.units[%d]: contents of %s: %v", i, u.Name, err))
+			}
+
+			dropins := map[string]bool{}
+			for j, d := range u.Dropins {
+				if !strings.HasSuffix(d.Name, ".conf") || strings.Contains(d.Name, "/") {
+					errs = append(errs, fmt.Sprintf("systemd.units[%d].dropins[%d]: invalid drop-in name \"%s\" of %s", i, j, d.Name, u.Name))
+				}
+				if dropins[d.Name] {
+					errs = append(errs, fmt.Sprintf("systemd.units[%d].dropins[%d]: duplicate drop-in %s of %s", i, j, d.Name, u.Name))
+				}
+				dropins[d.Name] = true
+				if err := validateUnitContents(d.Contents); err != nil {
+					errs = append(errs, fmt.Sprintf("systemd.units[%d].dropins[%d]: contents of %s of %s: %v", i, j, d.Name, u.Name, err))
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Ignition config validation errors:\n%s\n", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func hasUnitType(name string) bool {
+	for _, t := range unitTypes {
+		if strings.HasSuffix(name, t) && len(name) > len(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateResource checks the source URL of a resource, and decodes it when it is a data URL
+func validateResource(r resource) error {
+	if r.Compression != "" && r.Compression != "gzip" {
+		return fmt.Errorf("unsupported compression \"%s\"", r.Compression)
+	}
+	if r.Source == "" {
+		return nil
+	}
+
+	u, err := url.Parse(r.Source)
+	if err != nil {
+		return fmt.Errorf("invalid source: %v", err)
+	}
+	known := false
+	for _, s := range sourceSchemes {
+		known = known || u.Scheme == s
+	}
+	if !known {
+		return fmt.Errorf("unsupported source scheme \"%s\". Specify one of %s", u.Scheme, strings.Join(sourceSchemes, ", "))
+	}
+	if u.Scheme != "data" {
+		return nil
+	}
+
+	i := strings.Index(r.Source, ",")
+	if i < 0 {
+		return fmt.Errorf("invalid data URL: missing comma")
+	}
+	data := []byte(r.Source[i+1:])
+	if strings.HasSuffix(r.Source[:i], ";base64") {
+		if data, err = base64.StdEncoding.DecodeString(string(data)); err != nil {
+			return fmt.Errorf("invalid data URL: %v", err)
+		}
+	}
+	if r.Compression == "gzip" {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("invalid gzipped data: %v", err)
+		}
+		if _, err := ioutil.ReadAll(gz); err != nil {
+			return fmt.Errorf("invalid gzipped data: %v", err)
+		}
+	}
+	return nil
+}
+
+// validateUnitContents checks that the contents of a unit or a drop-in consist of sections, settings and comments
+func validateUnitContents(contents string) error {
+	section := ""
+	continued := false
+	for n, line := range strings.Split(contents, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case continued:
+		case trimmed == "", strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, ";"):
+			continue
+		case strings.HasPrefix(trimmed, "["):
+			if !strings.HasSuffix(trimmed, "]") {
+				return fmt.Errorf("line %d: invalid section header \"%s\"", n+1, trimmed)
+			}
+			section = trimmed
+			continue
+		case section == "":
+			return fmt.Errorf("line %d: setting outside of any section \"%s\"", n+1, trimmed)
+		case !strings.Contains(trimmed, "="):
+			return fmt.Errorf("line %d: invalid setting \"%s\" in %s", n+1, trimmed, section)
+		}
+		// A setting continues on the next line when it ends with a backslash
+		continued = strings.HasSuffix(trimmed, "\\")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package ignition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		context  string
+		config   string
+		expected string
+	}{
+		{
+			context: "Valid",
+			config: `{
+  "ignition": {"version": "3.3.0", "config": {"merge": [{"source": "s3://bucket/key"}]}},
+  "storage": {"files": [{"path": "/etc/foo", "mode": 420, "contents": {"source": "data:,foo"}}]},
+  "systemd": {"units": [{"name": "foo.service", "enabled": true, "contents": "[Service]\nExecStart=/bin/foo \\\n  --bar\n", "dropins": [{"name": "10-foo.conf"}]}]}
+}`,
+		},
+		{
+			context:  "UnknownKey",
+			config:   `{"ignition": {"version": "3.3.0"}, "storage": {"filez": []}}`,
+			expected: `failed to parse Ignition config: json: unknown field "filez"`,
+		},
+		{
+			context: "Invalid",
+			config: `{
+  "ignition": {"version": "3.0.0", "config": {"merge": [{"source": "ftp://host/key"}]}},
+  "passwd": {"users": [{"name": "core"}, {"name": "core"}]},
+  "storage": {"files": [
+    {"path": "etc/foo", "contents": {"source": "data:;base64,%%%"}},
+    {"path": "/etc/bar", "contents": {"source": "data:;base64,Zm9v", "compression": "gzip"}}
+  ]},
+  "systemd": {"units": [{"name": "foo", "contents": "ExecStart=/bin/foo", "dropins": [{"name": "10-foo"}]}]}
+}`,
+			expected: `Ignition config validation errors:
+ignition.version: expected 3.3.0 but was "3.0.0"
+ignition.config.merge[0]: unsupported source scheme "ftp". Specify one of data, http, https, s3, tftp, gs, arn
+passwd.users[1]: duplicate user core
+storage.files[0]: path must be absolute and clean but was "etc/foo"
+storage.files[0]: contents of etc/foo: invalid data URL: illegal base64 data at input byte 0
+storage.files[1]: contents of /etc/bar: invalid gzipped data: unexpected EOF
+systemd.units[0]: invalid unit name "foo"
+systemd.units[0]: contents of foo: line 1: setting outside of any section "ExecStart=/bin/foo"
+systemd.units[0].dropins[0]: invalid drop-in name "10-foo" of foo
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			err := Validate([]byte(tc.config))
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+)
+
+// Fields are the key-value pairs attached to log records, e.g. `cluster`, `stack` and `phase`
+type Fields map[string]interface{}
+
+// Entry writes log records with the fields attached to it
+type Entry struct {
+	fields Fields
+}
+
+// With returns an entry writing records with `fields`
+func With(fields Fields) *Entry {
+	return std.With(fields)
+}
+
+// With returns an entry writing records with the fields of `e` and `fields`. `fields` take precedence on conflicts.
+func (e *Entry) With(fields Fields) *Entry {
+	merged := Fields{}
+	for k, v := range e.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Entry{fields: merged}
+}
+
+func (e *Entry) log(l Level, heading bool, msg string) {
+	if !enabled(l) {
+		return
+	}
+	write(&Record{
+		Time:    time.Now(),
+		Level:   l,
+		Heading: heading,
+		Message: msg,
+		Fields:  e.fields,
+	})
+}
+
+func (e *Entry) Error(v ...interface{}) {
+	e.log(ErrorLevel, false, fmt.Sprint(v...))
+}
+
+func (e *Entry) Errorf(format string, v ...interface{}) {
+	e.log(ErrorLevel, false, fmt.Sprintf(format, v...))
+}
+
+func (e *Entry) Warn(v ...interface{}) {
+	e.log(WarnLevel, false, fmt.Sprint(v...))
+}
+
+func (e *Entry) Warnf(format string, v ...interface{}) {
+	e.log(WarnLevel, false, fmt.Sprintf(format, v...))
+}
+
+func (e *Entry) Heading(v ...interface{}) {
+	e.log(InfoLevel, true, fmt.Sprint(v...))
+}
+
+func (e *Entry) Headingf(format string, v ...interface{}) {
+	e.log(InfoLevel, true, fmt.Sprintf(format, v...))
+}
+
+func (e *Entry) Info(v ...interface{}) {
+	e.log(InfoLevel, false, fmt.Sprint(v...))
+}
+
+func (e *Entry) Infof(format string, v ...interface{}) {
+	e.log(InfoLevel, false, fmt.Sprintf(format, v...))
+}
+
+func (e *Entry) Debug(v ...interface{}) {
+	e.log(DebugLevel, false, fmt.Sprint(v...))
+}
+
+func (e *Entry) Debugf(format string, v ...interface{}) {
+	e.log(DebugLevel, false, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of a log record
+type Level int
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < DebugLevel || l > ErrorLevel {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level named `name`, which is one of `debug`, `info`, `warn` and `error`
+func ParseLevel(name string) (Level, error) {
+	for i, n := range levelNames {
+		if strings.ToLower(name) == n {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level: %s. Specify one of %s", name, strings.Join(levelNames, ", "))
+}
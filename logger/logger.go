@@ -1,23 +1,39 @@
 package logger
 
 import (
-	"fmt"
-	"log"
 	"os"
-	"regexp"
+	"sync"
 )
 
-const calldepth = 3
-
 var (
-	Silent           bool
-	Verbose          bool
-	Color            bool
-	stdOutLogger     = log.New(os.Stdout, "", 0)
-	stdOutWarnLogger = log.New(os.Stdout, "WARNING: ", 0)
-	stdErrLogger     = log.New(os.Stderr, "ERROR: ", 0)
+	Silent  bool
+	Verbose bool
+	Color   bool
+	// MinLevel is the least severe level of the records written to the sink. Verbose lowers it to DebugLevel
+	MinLevel = InfoLevel
+
+	mu   sync.Mutex
+	sink Sink = NewTextSink()
+	std       = &Entry{}
 )
 
+// SetSink makes every record written to `s` instead of the current sink, e.g. to route kube-aws logs into the logs of
+// a program embedding it
+func SetSink(s Sink) {
+	mu.Lock()
+	defer mu.Unlock()
+	sink = s
+}
+
+// WarnToStdErr writes warnings to stderr instead of stdout, so that stdout contains nothing but the output of a command
+func WarnToStdErr() {
+	mu.Lock()
+	defer mu.Unlock()
+	if t, ok := sink.(*TextSink); ok {
+		t.warn.SetOutput(os.Stderr)
+	}
+}
+
 func StdErrOutput(b []byte) (n int, err error) {
 	if Color {
 		b = append([]byte(ColorRed), b...)
@@ -35,77 +51,61 @@ func (w Writer) Write(b []byte) (n int, err error) {
 }
 
 func Error(v ...interface{}) {
-	Log(stdErrLogger, ColorRed, v...)
+	std.Error(v...)
 }
 
 func Errorf(format string, v ...interface{}) {
-	Logf(stdErrLogger, ColorRed, format, v...)
+	std.Errorf(format, v...)
 }
 
 func Warn(v ...interface{}) {
-	Log(stdOutWarnLogger, ColorLightRed, v...)
+	std.Warn(v...)
 }
 
 func Warnf(format string, v ...interface{}) {
-	Logf(stdOutWarnLogger, ColorLightRed, format, v...)
+	std.Warnf(format, v...)
 }
 
 func Heading(v ...interface{}) {
-	if !Silent {
-		Log(stdOutLogger, ColorGreen, v...)
-	}
+	std.Heading(v...)
 }
 
 func Headingf(format string, v ...interface{}) {
-	if !Silent {
-		Logf(stdOutLogger, ColorGreen, format, v...)
-	}
+	std.Headingf(format, v...)
 }
 
 func Info(v ...interface{}) {
-	if !Silent {
-		Log(stdOutLogger, ColorCyan, v...)
-	}
+	std.Info(v...)
 }
 
 func Infof(format string, v ...interface{}) {
-	if !Silent {
-		Logf(stdOutLogger, ColorCyan, format, v...)
-	}
+	std.Infof(format, v...)
 }
 
 func Debug(v ...interface{}) {
-	if Verbose && !Silent {
-		Log(stdOutLogger, ColorLightGrey, v...)
-	}
+	std.Debug(v...)
 }
 
 func Debugf(format string, v ...interface{}) {
-	if Verbose && !Silent {
-		Logf(stdOutLogger, ColorLightGrey, format, v...)
-	}
+	std.Debugf(format, v...)
 }
 
-func Log(l *log.Logger, color string, v ...interface{}) {
-	msg := fmt.Sprint(v...)
-	if Color {
-		msg = colorizeMessage(color, msg)
+// enabled returns true when records at the level are written to the sink
+func enabled(l Level) bool {
+	if Silent && l < WarnLevel {
+		return false
 	}
-	l.Output(calldepth, msg)
-}
-
-func Logf(l *log.Logger, color, format string, v ...interface{}) {
-	msg := fmt.Sprintf(format, v...)
-	if Color {
-		msg = colorizeMessage(color, msg)
+	min := MinLevel
+	if Verbose {
+		min = DebugLevel
 	}
-	l.Output(calldepth, msg)
+	return l >= min
 }
 
-func colorizeMessage(color, s string) string {
-	whitespace := regexp.MustCompile(`\s*$`)
-	trimmed := whitespace.ReplaceAllString(s, "")
-	trailing := whitespace.FindString(s)
-
-	return color + trimmed + ColorNC + trailing
+func write(r *Record) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := sink.Write(r); err != nil {
+		os.Stderr.WriteString("failed to write log record: " + err.Error() + "\n")
+	}
 }
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func captureRecords(t *testing.T) *[]*Record {
+	records := []*Record{}
+	SetSink(SinkFunc(func(r *Record) error {
+		records = append(records, r)
+		return nil
+	}))
+	t.Cleanup(func() {
+		SetSink(NewTextSink())
+		Silent, Verbose, MinLevel = false, false, InfoLevel
+	})
+	return &records
+}
+
+func TestLevels(t *testing.T) {
+	testCases := []struct {
+		context  string
+		silent   bool
+		verbose  bool
+		minLevel Level
+		expected []string
+	}{
+		{context: "Default", minLevel: InfoLevel, expected: []string{"info", "heading", "warn", "error"}},
+		{context: "Verbose", verbose: true, minLevel: InfoLevel, expected: []string{"debug", "info", "heading", "warn", "error"}},
+		{context: "VerboseMinLevelWarn", verbose: true, minLevel: WarnLevel, expected: []string{"debug", "info", "heading", "warn", "error"}},
+		{context: "Silent", silent: true, verbose: true, minLevel: DebugLevel, expected: []string{"warn", "error"}},
+		{context: "MinLevelWarn", minLevel: WarnLevel, expected: []string{"warn", "error"}},
+		{context: "MinLevelDebug", minLevel: DebugLevel, expected: []string{"debug", "info", "heading", "warn", "error"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			records := captureRecords(t)
+			Silent, Verbose, MinLevel = tc.silent, tc.verbose, tc.minLevel
+
+			Debug("debug")
+			Infof("%s", "info")
+			Heading("heading")
+			Warnf("%s", "warn")
+			Error("error")
+
+			messages := []string{}
+			for _, r := range *records {
+				messages = append(messages, r.Message)
+			}
+			assert.Equal(t, tc.expected, messages)
+		})
+	}
+}
+
+func TestWith(t *testing.T) {
+	records := captureRecords(t)
+
+	log := With(Fields{"cluster": "mycluster", "phase": "create"})
+	log.With(Fields{"phase": "update", "stack": "mycluster-Controlplane"}).Warnf("updating %s\n", "controlplane")
+	log.Info("created")
+
+	require.Len(t, *records, 2)
+	assert.Equal(t, WarnLevel, (*records)[0].Level)
+	assert.Equal(t, "updating controlplane\n", (*records)[0].Message)
+	assert.Equal(t, Fields{"cluster": "mycluster", "phase": "update", "stack": "mycluster-Controlplane"}, (*records)[0].Fields)
+	assert.Equal(t, Fields{"cluster": "mycluster", "phase": "create"}, (*records)[1].Fields)
+}
+
+func TestJSONSink(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := NewJSONSink(buf)
+
+	err := s.Write(&Record{
+		Time:    time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   ErrorLevel,
+		Message: "failed to update\n",
+		Fields:  Fields{"cluster": "mycluster", "error": errors.New("rollback"), "msg": "ignored"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"cluster":"mycluster","error":"rollback","level":"error","msg":"failed to update","time":"2019-01-02T03:04:05Z"}`+"\n", buf.String())
+}
+
+func TestParseLevel(t *testing.T) {
+	l, err := ParseLevel("WARN")
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, l)
+
+	_, err = ParseLevel("trace")
+	assert.EqualError(t, err, "unknown log level: trace. Specify one of debug, info, warn, error")
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Record is a log message along with its level and fields
+type Record struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  Fields
+	// Heading is true for the messages emphasized as headings when written as text
+	Heading bool
+}
+
+// Sink writes log records somewhere. Writes are serialized, so that a sink doesn't need to be safe for concurrent use.
+type Sink interface {
+	Write(r *Record) error
+}
+
+// SinkFunc adapts a function to Sink
+type SinkFunc func(r *Record) error
+
+func (f SinkFunc) Write(r *Record) error {
+	return f(r)
+}
+
+// TextSink writes the messages of records as human-readable lines without their fields.
+// Debug, info and warning messages are written to stdout, while errors are written to stderr.
+type TextSink struct {
+	out, warn, err *log.Logger
+}
+
+// NewTextSink returns the sink used by default
+func NewTextSink() *TextSink {
+	return &TextSink{
+		out:  log.New(os.Stdout, "", 0),
+		warn: log.New(os.Stdout, "WARNING: ", 0),
+		err:  log.New(os.Stderr, "ERROR: ", 0),
+	}
+}
+
+func (s *TextSink) Write(r *Record) error {
+	l, color := s.out, ColorCyan
+	switch {
+	case r.Level >= ErrorLevel:
+		l, color = s.err, ColorRed
+	case r.Level == WarnLevel:
+		l, color = s.warn, ColorLightRed
+	case r.Heading:
+		color = ColorGreen
+	case r.Level == DebugLevel:
+		color = ColorLightGrey
+	}
+
+	msg := r.Message
+	if Color {
+		msg = colorizeMessage(color, msg)
+	}
+	return l.Output(0, msg)
+}
+
+func colorizeMessage(color, s string) string {
+	whitespace := regexp.MustCompile(`\s*$`)
+	trimmed := whitespace.ReplaceAllString(s, "")
+	trailing := whitespace.FindString(s)
+
+	return color + trimmed + ColorNC + trailing
+}
+
+// JSONSink writes every record as a JSON object on a line, which has `time`, `level` and `msg` along with the fields of the record.
+// Fields named `time`, `level` or `msg` are ignored.
+type JSONSink struct {
+	w io.Writer
+}
+
+// NewJSONSink returns a sink writing records to `w`
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w: w}
+}
+
+func (s *JSONSink) Write(r *Record) error {
+	obj := map[string]interface{}{}
+	for k, v := range r.Fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		obj[k] = v
+	}
+	obj["time"] = r.Time.UTC().Format(time.RFC3339Nano)
+	obj["level"] = r.Level.String()
+	obj["msg"] = strings.TrimRight(r.Message, " \t\r\n")
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log record: %v", err)
+	}
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
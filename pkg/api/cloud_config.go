@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/kubernetes-incubator/kube-aws/gzipcompressor"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// DecompressCloudConfig decodes the `gzip+base64` and `base64` encoded contents of `write_files` in the cloud-config,
+// so that the files can be read as they are written on the node.
+// Contents which aren't text after decoding, like encrypted files, are left encoded.
+func DecompressCloudConfig(cloudConfig string) (string, error) {
+	doc := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal([]byte(cloudConfig), doc); err != nil {
+		return "", fmt.Errorf("failed to parse cloud-config: %v", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yamlv3.MappingNode {
+		return cloudConfig, nil
+	}
+
+	i := mappingKeyIndex(doc.Content[0], "write_files")
+	if i < 0 || doc.Content[0].Content[i+1].Kind != yamlv3.SequenceNode {
+		return cloudConfig, nil
+	}
+
+	for _, file := range doc.Content[0].Content[i+1].Content {
+		e, c := mappingKeyIndex(file, "encoding"), mappingKeyIndex(file, "content")
+		if file.Kind != yamlv3.MappingNode || e < 0 || c < 0 {
+			continue
+		}
+		decoded, err := decodeFileContent(file.Content[e+1].Value, file.Content[c+1].Value)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode the content of %s: %v", mappingValue(file, "path"), err)
+		}
+		if decoded == nil || !utf8.Valid(decoded) {
+			continue
+		}
+		file.Content[c+1].Value = string(decoded)
+		file.Content[c+1].Style = yamlv3.LiteralStyle
+		file.Content = append(file.Content[:e], file.Content[e+2:]...)
+	}
+
+	buf := new(bytes.Buffer)
+	enc := yamlv3.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", fmt.Errorf("failed to encode cloud-config: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode cloud-config: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// decodeFileContent decodes the content of a file in `write_files`, or returns nil for an encoding it doesn't know
+func decodeFileContent(encoding, content string) ([]byte, error) {
+	switch encoding {
+	case "gzip+base64", "gz+base64", "gzip+b64", "gz+b64":
+		decoded, err := gzipcompressor.GzippedBase64StringToString(content)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(decoded), nil
+	case "base64", "b64":
+		return base64.StdEncoding.DecodeString(content)
+	}
+	return nil, nil
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecompressCloudConfig(t *testing.T) {
+	decompressed, err := DecompressCloudConfig(`#cloud-config
+coreos:
+  units:
+  - name: foo.service
+    command: start
+write_files:
+- path: /etc/foo.env
+  permissions: 0644
+  encoding: gzip+base64
+  content: H4sIAAAAAAACA0vLz7dNSiziAgAU562LCAAAAA==
+- path: /etc/hello
+  encoding: base64
+  content: aGVsbG8K
+- path: /etc/key.pem.enc
+  encoding: base64
+  content: /w==
+- path: /etc/plain
+  content: plain
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, `#cloud-config
+coreos:
+  units:
+    - name: foo.service
+      command: start
+write_files:
+  - path: /etc/foo.env
+    permissions: 0644
+    content: |
+      foo=bar
+  - path: /etc/hello
+    content: |
+      hello
+  - path: /etc/key.pem.enc
+    encoding: base64
+    content: /w==
+  - path: /etc/plain
+    content: plain
+`, decompressed)
+
+	_, err = DecompressCloudConfig("write_files:\n- path: /etc/foo\n  encoding: base64\n  content: '%'\n")
+	assert.EqualError(t, err, "failed to decode the content of /etc/foo: illegal base64 data at input byte 0")
+}
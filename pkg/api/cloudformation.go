@@ -3,4 +3,8 @@ package api
 type CloudFormation struct {
 	RoleARN            string             `yaml:"roleARN,omitempty"`
 	StackNameOverrides StackNameOverrides `yaml:"stackNameOverrides,omitempty"`
+	// TerminationProtection prevents the root stack, and hence the whole cluster, from being deleted
+	TerminationProtection bool `yaml:"terminationProtection,omitempty"`
+	// StackPolicies prevent updates to the resources of the root stack and the nested stacks
+	StackPolicies StackPolicies `yaml:"stackPolicies,omitempty"`
 }
@@ -151,7 +151,9 @@ func NewDefaultCluster() *Cluster {
 			ReleaseChannel:     "stable",
 			KubeAWSVersion:     "UNKNOWN",
 			K8sVer:             KUBERNETES_VERSION,
-			ContainerRuntime:   "docker",
+			ContainerRuntime:   ContainerRuntimeDocker,
+			Containerd:         newDefaultContainerd(),
+			UserDataFormat:     USERDATA_FORMAT_CLOUD_CONFIG,
 			Subnets:            []Subnet{},
 			EIPAllocationIDs:   []string{},
 			Experimental:       experimental,
@@ -354,12 +356,12 @@ func (c *Cluster) Load() error {
 func (c *Cluster) ConsumeDeprecatedKeys() {
 	// TODO Remove in v0.9.9-rc.1
 	if c.DeprecatedVPCID != "" {
-		logger.Warn("vpcId is deprecated and will be removed in v0.9.9. Please use vpc.id instead")
+		logger.Warn("vpcId is deprecated and will be removed in v0.9.9. Please use vpc.id instead, or run `kube-aws migrate` to rewrite cluster.yaml")
 		c.VPC.ID = c.DeprecatedVPCID
 	}
 
 	if c.DeprecatedInternetGatewayID != "" {
-		logger.Warn("internetGatewayId is deprecated and will be removed in v0.9.9. Please use internetGateway.id instead")
+		logger.Warn("internetGatewayId is deprecated and will be removed in v0.9.9. Please use internetGateway.id instead, or run `kube-aws migrate` to rewrite cluster.yaml")
 		c.InternetGateway.ID = c.DeprecatedInternetGatewayID
 	}
 }
@@ -498,6 +500,8 @@ type DeploymentSettings struct {
 	K8sVer                    string `yaml:"kubernetesVersion,omitempty"`
 	KubeAWSVersion            string
 	ContainerRuntime          string            `yaml:"containerRuntime,omitempty"`
+	Containerd                Containerd        `yaml:"containerd,omitempty"`
+	UserDataFormat            string            `yaml:"userDataFormat,omitempty"`
 	KMSKeyARN                 string            `yaml:"kmsKeyArn,omitempty"`
 	StackTags                 map[string]string `yaml:"stackTags,omitempty"`
 	Subnets                   Subnets           `yaml:"subnets,omitempty"`
@@ -578,7 +582,8 @@ type Cluster struct {
 	CustomApiServerSettings     CustomApiServerSettings `yaml:"customApiServerSettings,omitempty"`
 	CustomSettings              map[string]interface{}  `yaml:"customSettings,omitempty"`
 	KubeResourcesAutosave       `yaml:"kubeResourcesAutosave,omitempty"`
-	OpenICMP                    bool `yaml:"openICMP,omitempty"`
+	OpenICMP                    bool  `yaml:"openICMP,omitempty"`
+	Hooks                       Hooks `yaml:"hooks,omitempty"`
 }
 
 type WaitSignal struct {
@@ -769,6 +774,10 @@ func (c Cluster) validate(cpStackName string) error {
 		return err
 	}
 
+	if err := c.Hooks.Validate(); err != nil {
+		return err
+	}
+
 	if c.WorkerTenancy != "default" && c.WorkerSpotPrice != "" {
 		return fmt.Errorf("selected worker tenancy (%s) is incompatible with spot instances", c.WorkerTenancy)
 	}
@@ -782,6 +791,12 @@ func (c Cluster) validate(cpStackName string) error {
 		return fmt.Errorf("awsNodeLabels can't be enabled for controllers because the total number of characters in clusterName(=\"%s\") exceeds the limit of %d", c.ClusterName, limit)
 	}
 
+	for _, t := range []string{c.Controller.InstanceType, c.Etcd.InstanceType} {
+		if err := validateEC2InstanceType(t); err != nil {
+			return fmt.Errorf("invalid controller or etcd nodes: %v", err)
+		}
+	}
+
 	if c.Controller.InstanceType == "t2.micro" || c.Etcd.InstanceType == "t2.micro" || c.Controller.InstanceType == "t2.nano" || c.Etcd.InstanceType == "t2.nano" {
 		logger.Warn(`instance types "t2.nano" and "t2.micro" are not recommended. See https://github.com/kubernetes-incubator/kube-aws/issues/258 for more information`)
 	}
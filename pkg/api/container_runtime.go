@@ -0,0 +1,151 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	ContainerRuntimeDocker     = "docker"
+	ContainerRuntimeRkt        = "rkt"
+	ContainerRuntimeContainerd = "containerd"
+)
+
+var containerRuntimes = []string{ContainerRuntimeDocker, ContainerRuntimeRkt, ContainerRuntimeContainerd}
+
+// dockerOnlyKubeletFlags are the kubelet flags which are only read by dockershim, and therefore silently ignored with containerd
+var dockerOnlyKubeletFlags = []string{
+	"cni-bin-dir",
+	"cni-cache-dir",
+	"cni-conf-dir",
+	"image-pull-progress-deadline",
+	"network-plugin",
+	"network-plugin-mtu",
+	"pod-infra-container-image",
+}
+
+// Containerd is the configuration of containerd used when `containerRuntime` is `containerd`
+type Containerd struct {
+	// RegistryMirrors are the endpoints containerd pulls images from instead of each registry host
+	// e.g. `docker.io: ["https://mirror.gcr.io"]`
+	RegistryMirrors map[string][]string `yaml:"registryMirrors,omitempty"`
+	Crictl          Crictl              `yaml:"crictl,omitempty"`
+	// NvidiaContainerRuntime is the absolute path to nvidia-container-runtime on GPU nodes, which containerd runs containers with
+	// when `experimental.gpuSupport` is enabled. It isn't installed by kube-aws
+	NvidiaContainerRuntime string `yaml:"nvidiaContainerRuntime,omitempty"`
+}
+
+// Crictl is the CLI for CRI-compatible container runtimes installed to /opt/bin/crictl
+type Crictl struct {
+	DownloadUrl string `yaml:"downloadUrl,omitempty"`
+	// Sha256Sum is the optional checksum of the archive at DownloadUrl
+	Sha256Sum string `yaml:"sha256sum,omitempty"`
+}
+
+func newDefaultContainerd() Containerd {
+	return Containerd{
+		Crictl: Crictl{
+			DownloadUrl: "https://github.com/kubernetes-sigs/cri-tools/releases/download/v1.14.0/crictl-v1.14.0-linux-amd64.tar.gz",
+		},
+	}
+}
+
+// RegistryHosts returns the hosts of RegistryMirrors in a stable order for rendering
+func (c Containerd) RegistryHosts() []string {
+	hosts := []string{}
+	for h := range c.RegistryMirrors {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// MergeIfEmpty inherits the settings of the main cluster which are not customized for a node pool
+func (c *Containerd) MergeIfEmpty(other Containerd) {
+	if len(c.RegistryMirrors) == 0 {
+		c.RegistryMirrors = other.RegistryMirrors
+	}
+	if c.Crictl.DownloadUrl == "" {
+		c.Crictl = other.Crictl
+	}
+	if c.NvidiaContainerRuntime == "" {
+		c.NvidiaContainerRuntime = other.NvidiaContainerRuntime
+	}
+}
+
+func (c Containerd) Validate() error {
+	for _, host := range c.RegistryHosts() {
+		if host == "" || strings.ContainsAny(host, `/"`) {
+			return fmt.Errorf("invalid registry host \"%s\" in containerd.registryMirrors", host)
+		}
+		if len(c.RegistryMirrors[host]) == 0 {
+			return fmt.Errorf("containerd.registryMirrors.%s must have one or more endpoints", host)
+		}
+		for _, e := range c.RegistryMirrors[host] {
+			u, err := url.Parse(e)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Contains(e, `"`) {
+				return fmt.Errorf("invalid endpoint \"%s\" of containerd.registryMirrors.%s: it must be a http or https URL", e, host)
+			}
+		}
+	}
+	if c.Crictl.DownloadUrl == "" {
+		return fmt.Errorf("containerd.crictl.downloadUrl must be set")
+	}
+	if c.NvidiaContainerRuntime != "" && (!path.IsAbs(c.NvidiaContainerRuntime) || strings.Contains(c.NvidiaContainerRuntime, `"`)) {
+		return fmt.Errorf("containerd.nvidiaContainerRuntime must be an absolute path, but it is %s", c.NvidiaContainerRuntime)
+	}
+	return nil
+}
+
+// validateContainerRuntime rejects the runtime settings which only work with docker when the runtime is containerd
+func (c DeploymentSettings) validateContainerRuntime() error {
+	supported := false
+	for _, r := range containerRuntimes {
+		supported = supported || c.ContainerRuntime == r
+	}
+	if !supported {
+		return fmt.Errorf("containerRuntime %s is not supported. Specify one of %s", c.ContainerRuntime, strings.Join(containerRuntimes, ", "))
+	}
+	if c.ContainerRuntime != ContainerRuntimeContainerd {
+		return nil
+	}
+
+	if err := c.Containerd.Validate(); err != nil {
+		return err
+	}
+	for _, f := range c.Kubelet.Flags {
+		if isDockerOnlyKubeletFlag(f.Name) {
+			return fmt.Errorf("kubelet flag --%s works only with the docker container runtime but containerRuntime is %s", f.Name, c.ContainerRuntime)
+		}
+	}
+	if strings.Contains(c.Experimental.KubeletOpts, "--docker") {
+		return fmt.Errorf("experimental.kubeletOpts contains docker flags although containerRuntime is %s", c.ContainerRuntime)
+	}
+	if c.Experimental.GpuSupport.Enabled && c.Containerd.NvidiaContainerRuntime == "" {
+		return fmt.Errorf("containerd.nvidiaContainerRuntime must be set to the path to nvidia-container-runtime installed on GPU nodes e.g. with customFiles, as experimental.gpuSupport is enabled and containerRuntime is %s", c.ContainerRuntime)
+	}
+	return nil
+}
+
+func isDockerOnlyKubeletFlag(name string) bool {
+	if strings.HasPrefix(name, "docker-") {
+		return true
+	}
+	for _, f := range dockerOnlyKubeletFlags {
+		if name == f {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateContainerRuntime rejects the GPU settings which only work with docker
+func (c Gpu) ValidateContainerRuntime(runtime string) error {
+	if c.Nvidia.Enabled && runtime == ContainerRuntimeContainerd {
+		return fmt.Errorf("gpu.nvidia relies on the Accelerators feature of kubelet which works only with the docker container runtime, but containerRuntime is %s", runtime)
+	}
+	return nil
+}
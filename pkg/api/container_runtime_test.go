@@ -0,0 +1,155 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateContainerRuntime(t *testing.T) {
+	containerd := func(f func(s *DeploymentSettings)) DeploymentSettings {
+		s := NewDefaultCluster().DeploymentSettings
+		s.ContainerRuntime = ContainerRuntimeContainerd
+		f(&s)
+		return s
+	}
+
+	testCases := []struct {
+		context  string
+		settings DeploymentSettings
+		expected string
+	}{
+		{
+			context:  "Docker",
+			settings: NewDefaultCluster().DeploymentSettings,
+		},
+		{
+			context: "Containerd",
+			settings: containerd(func(s *DeploymentSettings) {
+				s.Containerd.RegistryMirrors = map[string][]string{"docker.io": {"https://mirror.gcr.io", "http://10.0.0.5:5000"}}
+				s.Kubelet.Flags = CommandLineFlags{{Name: "image-gc-high-threshold", Value: "80"}}
+			}),
+		},
+		{
+			context: "Unsupported",
+			settings: containerd(func(s *DeploymentSettings) {
+				s.ContainerRuntime = "cri-o"
+			}),
+			expected: "containerRuntime cri-o is not supported. Specify one of docker, rkt, containerd",
+		},
+		{
+			context: "DockerOnlyKubeletFlag",
+			settings: containerd(func(s *DeploymentSettings) {
+				s.Kubelet.Flags = CommandLineFlags{{Name: "docker-endpoint", Value: "unix:///var/run/docker.sock"}}
+			}),
+			expected: "kubelet flag --docker-endpoint works only with the docker container runtime but containerRuntime is containerd",
+		},
+		{
+			context: "DockerOnlyKubeletOpts",
+			settings: containerd(func(s *DeploymentSettings) {
+				s.Experimental.KubeletOpts = "--docker-disable-shared-pid=false"
+			}),
+			expected: "experimental.kubeletOpts contains docker flags although containerRuntime is containerd",
+		},
+		{
+			context: "GpuSupport",
+			settings: containerd(func(s *DeploymentSettings) {
+				s.Experimental.GpuSupport.Enabled = true
+				s.Containerd.NvidiaContainerRuntime = "/opt/bin/nvidia-container-runtime"
+			}),
+		},
+		{
+			context: "GpuSupportWithoutNvidiaContainerRuntime",
+			settings: containerd(func(s *DeploymentSettings) {
+				s.Experimental.GpuSupport.Enabled = true
+			}),
+			expected: "containerd.nvidiaContainerRuntime must be set to the path to nvidia-container-runtime installed on GPU nodes e.g. with customFiles, as experimental.gpuSupport is enabled and containerRuntime is containerd",
+		},
+		{
+			context: "RelativeNvidiaContainerRuntime",
+			settings: containerd(func(s *DeploymentSettings) {
+				s.Containerd.NvidiaContainerRuntime = "nvidia-container-runtime"
+			}),
+			expected: "containerd.nvidiaContainerRuntime must be an absolute path, but it is nvidia-container-runtime",
+		},
+		{
+			context: "InvalidMirror",
+			settings: containerd(func(s *DeploymentSettings) {
+				s.Containerd.RegistryMirrors = map[string][]string{"quay.io": {"mirror.example.com"}}
+			}),
+			expected: `invalid endpoint "mirror.example.com" of containerd.registryMirrors.quay.io: it must be a http or https URL`,
+		},
+		{
+			context: "NoMirrors",
+			settings: containerd(func(s *DeploymentSettings) {
+				s.Containerd.RegistryMirrors = map[string][]string{"quay.io": {}}
+			}),
+			expected: "containerd.registryMirrors.quay.io must have one or more endpoints",
+		},
+		{
+			context: "NoCrictl",
+			settings: containerd(func(s *DeploymentSettings) {
+				s.Containerd.Crictl.DownloadUrl = ""
+			}),
+			expected: "containerd.crictl.downloadUrl must be set",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			err := tc.settings.validateContainerRuntime()
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGpuValidateContainerRuntime(t *testing.T) {
+	gpu := Gpu{Nvidia: NvidiaSetting{Enabled: true, Version: "384.66"}}
+
+	assert.NoError(t, gpu.ValidateContainerRuntime(ContainerRuntimeDocker))
+	assert.NoError(t, Gpu{}.ValidateContainerRuntime(ContainerRuntimeContainerd))
+	assert.EqualError(t, gpu.ValidateContainerRuntime(ContainerRuntimeContainerd), "gpu.nvidia relies on the Accelerators feature of kubelet which works only with the docker container runtime, but containerRuntime is containerd")
+}
+
+func TestContainerdMergeIfEmpty(t *testing.T) {
+	main := Containerd{
+		RegistryMirrors:        map[string][]string{"docker.io": {"https://mirror.gcr.io"}},
+		Crictl:                 Crictl{DownloadUrl: "https://example.com/crictl.tar.gz"},
+		NvidiaContainerRuntime: "/opt/bin/nvidia-container-runtime",
+	}
+
+	pool := Containerd{}
+	pool.MergeIfEmpty(main)
+	assert.Equal(t, main, pool)
+
+	pool = Containerd{RegistryMirrors: map[string][]string{"quay.io": {"https://quay.example.com"}}}
+	pool.MergeIfEmpty(main)
+	assert.Equal(t, []string{"quay.io"}, pool.RegistryHosts())
+	assert.Equal(t, main.Crictl, pool.Crictl)
+	assert.Equal(t, main.NvidiaContainerRuntime, pool.NvidiaContainerRuntime)
+}
+
+func TestGpuSupportIsEnabledOn(t *testing.T) {
+	assert.True(t, GpuSupport{Enabled: true}.IsEnabledOn("p2.xlarge"))
+	assert.False(t, GpuSupport{Enabled: true}.IsEnabledOn("m5.large"))
+	assert.False(t, GpuSupport{}.IsEnabledOn("p2.xlarge"))
+}
+
+func TestNodePoolCrictlDefaults(t *testing.T) {
+	main := NewDefaultCluster().DeploymentSettings
+
+	pool := DeploymentSettings{}.WithDefaultsFrom(main)
+	assert.Equal(t, "https://github.com/kubernetes-sigs/cri-tools/releases/download/v1.14.0/crictl-v1.14.0-linux-amd64.tar.gz", pool.Containerd.Crictl.DownloadUrl)
+
+	main.Containerd.Crictl = Crictl{DownloadUrl: "https://example.com/crictl.tar.gz"}
+	pool = DeploymentSettings{}.WithDefaultsFrom(main)
+	assert.Equal(t, "https://example.com/crictl.tar.gz", pool.Containerd.Crictl.DownloadUrl)
+
+	custom := Crictl{DownloadUrl: "https://example.com/crictl-custom.tar.gz", Sha256Sum: "abc"}
+	pool = DeploymentSettings{Containerd: Containerd{Crictl: custom}}.WithDefaultsFrom(main)
+	assert.Equal(t, custom, pool.Containerd.Crictl)
+}
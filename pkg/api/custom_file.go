@@ -16,6 +16,8 @@ type CustomFile struct {
 	Content     string `yaml:"content,omitempty"`
 	Template    string `yaml:"template,omitempty"`
 	Type        string `yaml:"type,omitempty"`
+	// Secret is true when the content is resolved from secrets, so that it must be encrypted before being rendered
+	Secret      bool `yaml:"-"`
 	UnknownKeys `yaml:",inline"`
 }
 
@@ -1,10 +1,30 @@
 package api
 
+import (
+	"errors"
+	"fmt"
+)
+
 type DataVolume struct {
-	Size        int    `yaml:"size,omitempty"`
-	Type        string `yaml:"type,omitempty"`
-	IOPS        int    `yaml:"iops,omitempty"`
-	Ephemeral   bool   `yaml:"ephemeral,omitempty"`
-	Encrypted   bool   `yaml:"encrypted,omitempty"`
-	UnknownKeys `yaml:",inline"`
+	Size      int    `yaml:"size,omitempty"`
+	Type      string `yaml:"type,omitempty"`
+	IOPS      int    `yaml:"iops,omitempty"`
+	Ephemeral bool   `yaml:"ephemeral,omitempty"`
+	Encrypted bool   `yaml:"encrypted,omitempty"`
+	// DeletionPolicy is either `Delete`, `Retain` or `Snapshot`, which decides what happens to the volume when it is
+	// deleted or replaced by CloudFormation
+	DeletionPolicy string `yaml:"deletionPolicy,omitempty"`
+	UnknownKeys    `yaml:",inline"`
+}
+
+func (v DataVolume) Validate() error {
+	switch v.DeletionPolicy {
+	case "", "Delete", "Retain", "Snapshot":
+	default:
+		return fmt.Errorf("invalid deletionPolicy: %s. Specify one of Delete, Retain, Snapshot", v.DeletionPolicy)
+	}
+	if v.Ephemeral && v.DeletionPolicy != "" {
+		return errors.New("deletionPolicy can not be specified for an ephemeral data volume")
+	}
+	return nil
 }
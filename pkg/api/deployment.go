@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver"
+	"github.com/kubernetes-incubator/kube-aws/hostos"
 	"github.com/kubernetes-incubator/kube-aws/netutil"
 )
 
@@ -14,6 +15,12 @@ func (s DeploymentSettings) ValidateNodePool(name string) error {
 	if err := s.Experimental.Validate(name); err != nil {
 		return err
 	}
+	if err := s.validateHostOS(); err != nil {
+		return fmt.Errorf("invalid node pool %s: %v", name, err)
+	}
+	if err := s.validateContainerRuntime(); err != nil {
+		return fmt.Errorf("invalid node pool %s: %v", name, err)
+	}
 	return nil
 }
 
@@ -27,13 +34,19 @@ func (c DeploymentSettings) WithDefaultsFrom(main DeploymentSettings) Deployment
 
 	// No defaulting for AvailabilityZone: It must be set explicitly for high availability
 
+	// Node pools can run a host OS other than the one of controller and etcd nodes
+	if c.HostOS.Name == "" {
+		c.HostOS.Name = main.HostOS.Name
+	}
+	sameOS := c.HostOS.OS().Name() == main.HostOS.OS().Name()
+
 	// If there was a specific release channel specified for this node pool,
 	// the user would want to use the latest AMI for the channel, not the latest AMI for the default release channel
 	// specified in the top level of cluster.yaml
 	if c.ReleaseChannel == "" {
 		c.ReleaseChannel = main.ReleaseChannel
 
-		if c.AmiId == "" {
+		if c.AmiId == "" && sameOS {
 			c.AmiId = main.AmiId
 		}
 	}
@@ -42,6 +55,15 @@ func (c DeploymentSettings) WithDefaultsFrom(main DeploymentSettings) Deployment
 		c.K8sVer = main.K8sVer
 	}
 
+	// Node pools can be migrated to Ignition one by one.
+	// A node pool running another host OS defaults to the userdata format of the OS
+	if c.UserDataFormat == "" {
+		c.UserDataFormat = main.UserDataFormat
+		if !sameOS {
+			c.UserDataFormat = c.HostOS.OS().UserDataFormats()[0]
+		}
+	}
+
 	// Use main images if not defined in nodepool configuration
 	c.HyperkubeImage.MergeIfEmpty(main.HyperkubeImage)
 	c.HyperkubeImage.Tag = c.K8sVer
@@ -64,6 +86,9 @@ func (c DeploymentSettings) WithDefaultsFrom(main DeploymentSettings) Deployment
 	c.ContainerRuntime = main.ContainerRuntime
 	c.KMSKeyARN = main.KMSKeyARN
 
+	// Node pools can use their own registry mirrors
+	c.Containerd.MergeIfEmpty(main.Containerd)
+
 	// TODO Allow providing one or more elasticFileSystemId's to be mounted both per-node-pool/cluster-wide
 	// TODO Allow providing elasticFileSystemId to a node pool in managed subnets.
 	// Currently, per-node-pool elasticFileSystemId requires existing subnets configured by users to have appropriate MountTargets associated
@@ -96,6 +121,17 @@ func (c DeploymentSettings) Validate() (*DeploymentValidationResult, error) {
 		return nil, fmt.Errorf("releaseChannel %s is not supported", c.ReleaseChannel)
 	}
 
+	if c.HostOS.Name != "" && c.HostOS.Name != hostos.Flatcar {
+		return nil, fmt.Errorf("hostOS.name %s is not supported by controller and etcd nodes, which run only %s. Specify it for worker node pools instead", c.HostOS.Name, hostos.Flatcar)
+	}
+	if err := c.validateHostOS(); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateContainerRuntime(); err != nil {
+		return nil, err
+	}
+
 	if c.KeyName == "" && len(c.SSHAuthorizedKeys) == 0 {
 		return nil, errors.New("Either keyName or sshAuthorizedKeys must be set")
 	}
@@ -1,6 +1,10 @@
 package api
 
-import "strings"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 type EC2Instance struct {
 	Count         int    `yaml:"count,omitempty"`
@@ -26,3 +30,14 @@ func isNvmeEC2InstanceType(instanceType string) bool {
 func (e EC2Instance) HasNvmeDevices() bool {
 	return isNvmeEC2InstanceType(e.InstanceType)
 }
+
+// arm64EC2InstanceType matches the instance types of AWS Graviton processors e.g. `a1.large`, `m6g.large` and `c6gn.xlarge`
+var arm64EC2InstanceType = regexp.MustCompile(`^(a1|[a-z]+[0-9]+g[a-z]*)\.`)
+
+// validateEC2InstanceType rejects the instance type which can't boot the amd64 AMIs nodes run
+func validateEC2InstanceType(instanceType string) error {
+	if arm64EC2InstanceType.MatchString(instanceType) {
+		return fmt.Errorf("instance type %s has arm64 processors, which are not supported as nodes run amd64 AMIs and images", instanceType)
+	}
+	return nil
+}
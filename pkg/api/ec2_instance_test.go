@@ -0,0 +1,16 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEC2InstanceType(t *testing.T) {
+	for _, instanceType := range []string{"t2.medium", "m5.large", "g4dn.xlarge", "p3.2xlarge"} {
+		assert.NoError(t, validateEC2InstanceType(instanceType), instanceType)
+	}
+	for _, instanceType := range []string{"a1.large", "m6g.large", "t4g.medium", "c6gn.xlarge", "r6gd.large"} {
+		assert.EqualError(t, validateEC2InstanceType(instanceType), "instance type "+instanceType+" has arm64 processors, which are not supported as nodes run amd64 AMIs and images")
+	}
+}
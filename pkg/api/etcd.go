@@ -162,6 +162,10 @@ func (e Etcd) Validate() error {
 		return err
 	}
 
+	if err := e.DataVolume.Validate(); err != nil {
+		return fmt.Errorf("invalid etcd.dataVolume: %v", err)
+	}
+
 	if err := ValidateQuotaBackendBytes(e.UserSuppliedArgs.QuotaBackendBytes); err != nil {
 		return err
 	}
@@ -41,6 +41,11 @@ func (c NvidiaSetting) IsEnabledOn(instanceType string) bool {
 	return isGpuEnabledInstanceType(instanceType) && c.Enabled
 }
 
+// IsEnabledOn is true when GPUs of the instance type are set up by the NVIDIA driver installer. This function is used when rendering cloud-config-worker
+func (c GpuSupport) IsEnabledOn(instanceType string) bool {
+	return isGpuEnabledInstanceType(instanceType) && c.Enabled
+}
+
 func (c Gpu) Validate(instanceType string, experimentalGpuSupportEnabled bool) error {
 	if c.Nvidia.Enabled && !isGpuEnabledInstanceType(instanceType) {
 		return errors.New(fmt.Sprintf("instance type %v doesn't support GPU. You can enable Nvidia driver intallation support only when use %v instance family.", instanceType, GPUEnabledInstanceFamily))
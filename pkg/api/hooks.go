@@ -0,0 +1,52 @@
+package api
+
+import "fmt"
+
+// Hooks are the commands run at the defined points of `kube-aws render`, `validate` and `apply`
+type Hooks struct {
+	// PreRender hooks run before the stack templates are rendered
+	PreRender []Hook `yaml:"preRender,omitempty"`
+	// PostRender hooks run after the stack templates are rendered, so that they can inspect the templates
+	PostRender []Hook `yaml:"postRender,omitempty"`
+	// PreApply hooks run before the stacks are created or updated
+	PreApply []Hook `yaml:"preApply,omitempty"`
+	// PostApply hooks run after the stacks are successfully created or updated
+	PostApply []Hook `yaml:"postApply,omitempty"`
+	// OnFailure hooks run when rendering, validating or applying fails
+	OnFailure []Hook `yaml:"onFailure,omitempty"`
+}
+
+// Hook is a shell command receiving the context of the operation as JSON on stdin
+type Hook struct {
+	// Name identifies the hook in logs. Defaults to the command
+	Name    string `yaml:"name,omitempty"`
+	Command string `yaml:"command"`
+}
+
+func (h Hook) String() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Command
+}
+
+func (h Hooks) Validate() error {
+	points := []struct {
+		name  string
+		hooks []Hook
+	}{
+		{"preRender", h.PreRender},
+		{"postRender", h.PostRender},
+		{"preApply", h.PreApply},
+		{"postApply", h.PostApply},
+		{"onFailure", h.OnFailure},
+	}
+	for _, p := range points {
+		for i, hook := range p.hooks {
+			if hook.Command == "" {
+				return fmt.Errorf("hooks.%s[%d].command must not be empty", p.name, i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/ami"
+	"github.com/kubernetes-incubator/kube-aws/hostos"
+)
+
+// OS returns the host OS named Name. An unsupported name, which is rejected by validation, falls back to Flatcar
+func (h HostOS) OS() hostos.OS {
+	os, err := hostos.Get(h.Name)
+	if err != nil {
+		os, _ = hostos.Get(hostos.Flatcar)
+	}
+	return os
+}
+
+// validateHostOS rejects the settings which the host OS doesn't support
+func (c DeploymentSettings) validateHostOS() error {
+	os, err := hostos.Get(c.HostOS.Name)
+	if err != nil {
+		return fmt.Errorf("invalid hostOS.name: %v", err)
+	}
+	if !hostos.SupportsUserDataFormat(os, c.UserDataFormat) {
+		return fmt.Errorf("userDataFormat %s is not supported by host OS %s. Specify one of %s", c.UserDataFormat, os.Name(), strings.Join(os.UserDataFormats(), ", "))
+	}
+	if c.ContainerRuntime == ContainerRuntimeRkt && !os.HasRkt() {
+		return fmt.Errorf("containerRuntime %s is not supported by host OS %s", c.ContainerRuntime, os.Name())
+	}
+	return nil
+}
+
+// AMIQuery returns the query to look up the AMI of nodes from the source of the host OS
+func (c DeploymentSettings) AMIQuery() ami.Query {
+	os := c.HostOS.OS()
+	q := ami.Query{OS: os.Name(), Region: c.Region.String(), Channel: c.ReleaseChannel}
+	if !os.AMISource().HasChannels() {
+		q.Channel = ""
+	}
+	return q
+}
+
+// ValidateHostOS rejects the GPU settings which only work with Flatcar
+func (c Gpu) ValidateHostOS(os hostos.OS) error {
+	if c.Nvidia.Enabled && os.Name() != hostos.Flatcar {
+		return fmt.Errorf("gpu.nvidia is not supported by host OS %s, as the driver is built in the Flatcar developer container", os.Name())
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-aws/ami"
+	"github.com/kubernetes-incubator/kube-aws/hostos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateHostOS(t *testing.T) {
+	amazonLinux2 := func(f func(s *DeploymentSettings)) DeploymentSettings {
+		s := NewDefaultCluster().DeploymentSettings
+		s.HostOS.Name = hostos.AmazonLinux2
+		s.UserDataFormat = USERDATA_FORMAT_SHELL_SCRIPT
+		f(&s)
+		return s
+	}
+
+	testCases := []struct {
+		context  string
+		settings DeploymentSettings
+		expected string
+	}{
+		{
+			context:  "Flatcar",
+			settings: NewDefaultCluster().DeploymentSettings,
+		},
+		{
+			context:  "AmazonLinux2",
+			settings: amazonLinux2(func(s *DeploymentSettings) {}),
+		},
+		{
+			context: "AmazonLinux2WithContainerd",
+			settings: amazonLinux2(func(s *DeploymentSettings) {
+				s.ContainerRuntime = ContainerRuntimeContainerd
+			}),
+		},
+		{
+			context: "Unsupported",
+			settings: amazonLinux2(func(s *DeploymentSettings) {
+				s.HostOS.Name = "ubuntu"
+			}),
+			expected: "invalid hostOS.name: host OS ubuntu is not supported. Specify one of flatcar, amazon-linux-2",
+		},
+		{
+			context: "UnsupportedUserDataFormat",
+			settings: amazonLinux2(func(s *DeploymentSettings) {
+				s.UserDataFormat = USERDATA_FORMAT_IGNITION
+			}),
+			expected: "userDataFormat ignition is not supported by host OS amazon-linux-2. Specify one of shell-script",
+		},
+		{
+			context: "ShellScriptOnFlatcar",
+			settings: amazonLinux2(func(s *DeploymentSettings) {
+				s.HostOS.Name = ""
+			}),
+			expected: "userDataFormat shell-script is not supported by host OS flatcar. Specify one of cloud-config, ignition",
+		},
+		{
+			context: "Rkt",
+			settings: amazonLinux2(func(s *DeploymentSettings) {
+				s.ContainerRuntime = ContainerRuntimeRkt
+			}),
+			expected: "containerRuntime rkt is not supported by host OS amazon-linux-2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			err := tc.settings.validateHostOS()
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNodePoolHostOSDefaults(t *testing.T) {
+	main := NewDefaultCluster().DeploymentSettings
+	main.AmiId = "ami-flatcar"
+	main.UserDataFormat = USERDATA_FORMAT_IGNITION
+
+	pool := DeploymentSettings{}.WithDefaultsFrom(main)
+	assert.Equal(t, "", pool.HostOS.Name)
+	assert.Equal(t, "ami-flatcar", pool.AmiId)
+	assert.Equal(t, USERDATA_FORMAT_IGNITION, pool.UserDataFormat)
+
+	pool = DeploymentSettings{HostOS: HostOS{Name: hostos.AmazonLinux2}}.WithDefaultsFrom(main)
+	assert.Equal(t, hostos.AmazonLinux2, pool.HostOS.OS().Name())
+	assert.Equal(t, "", pool.AmiId)
+	assert.Equal(t, USERDATA_FORMAT_SHELL_SCRIPT, pool.UserDataFormat)
+}
+
+func TestAMIQuery(t *testing.T) {
+	main := NewDefaultCluster().DeploymentSettings
+	main.Region = RegionForName("eu-west-1")
+
+	assert.Equal(t, ami.Query{OS: hostos.Flatcar, Region: "eu-west-1", Channel: "stable"}, main.AMIQuery())
+
+	pool := DeploymentSettings{HostOS: HostOS{Name: hostos.AmazonLinux2}}.WithDefaultsFrom(main)
+	assert.Equal(t, ami.Query{OS: hostos.AmazonLinux2, Region: "eu-west-1"}, pool.AMIQuery())
+}
+
+func TestGpuValidateHostOS(t *testing.T) {
+	gpu := Gpu{Nvidia: NvidiaSetting{Enabled: true, Version: "384.66"}}
+
+	assert.NoError(t, gpu.ValidateHostOS(HostOS{}.OS()))
+	assert.NoError(t, Gpu{}.ValidateHostOS(HostOS{Name: hostos.AmazonLinux2}.OS()))
+	assert.EqualError(t, gpu.ValidateHostOS(HostOS{Name: hostos.AmazonLinux2}.OS()), "gpu.nvidia is not supported by host OS amazon-linux-2, as the driver is built in the Flatcar developer container")
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// KeyMigration moves the value of a deprecated key in cluster.yaml to the key replacing it
+type KeyMigration struct {
+	// Deprecated is the deprecated top-level key e.g. `vpcId`
+	Deprecated string
+	// Replacement is the key replacing it in the form of `<top-level key>.<key>` e.g. `vpc.id`
+	Replacement string
+}
+
+// KeyMigrations is the list of all the deprecated keys translated by Cluster.ConsumeDeprecatedKeys
+var KeyMigrations = []KeyMigration{
+	{Deprecated: "vpcId", Replacement: "vpc.id"},
+	{Deprecated: "internetGatewayId", Replacement: "internetGateway.id"},
+}
+
+// MigrateClusterYaml rewrites all the deprecated keys in cluster.yaml with their replacements.
+// Only the lines containing the keys are rewritten so that comments, blank lines and the order of keys are kept as-is.
+// It returns the rewritten cluster.yaml and the descriptions of the changes made.
+func MigrateClusterYaml(data []byte) ([]byte, []string, error) {
+	changes := []string{}
+	for _, m := range KeyMigrations {
+		migrated, change, err := m.apply(string(data))
+		if err != nil {
+			return nil, nil, err
+		}
+		if change != "" {
+			data = []byte(migrated)
+			changes = append(changes, change)
+		}
+	}
+	return data, changes, nil
+}
+
+// apply rewrites the deprecated key in `data`. The deprecated key takes precedence over the replacement
+// as it does in Cluster.ConsumeDeprecatedKeys.
+func (m KeyMigration) apply(data string) (string, string, error) {
+	doc := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal([]byte(data), doc); err != nil {
+		return "", "", fmt.Errorf("failed to parse cluster.yaml: %v", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yamlv3.MappingNode {
+		return "", "", nil
+	}
+	root := doc.Content[0]
+
+	i := mappingKeyIndex(root, m.Deprecated)
+	if i < 0 {
+		return "", "", nil
+	}
+	key, value := root.Content[i], root.Content[i+1]
+	if value.Kind != yamlv3.ScalarNode || value.Line != key.Line {
+		return "", "", fmt.Errorf("unable to migrate %s: only a value in the same line as the key can be migrated. Replace it with %s manually", m.Deprecated, m.Replacement)
+	}
+
+	lines := strings.Split(data, "\n")
+	// The colon and the value following the key, including the comment if any
+	rest := lines[key.Line-1][key.Column-1+len(m.Deprecated):]
+	if key.Style&(yamlv3.DoubleQuotedStyle|yamlv3.SingleQuotedStyle) != 0 {
+		rest = rest[2:]
+	}
+
+	if value.Tag == "!!null" {
+		lines = append(lines[:key.Line-1], lines[key.Line:]...)
+		return strings.Join(lines, "\n"), fmt.Sprintf("removed %s without a value", m.Deprecated), nil
+	}
+
+	path := strings.SplitN(m.Replacement, ".", 2)
+	parentName, name := path[0], path[1]
+
+	j := mappingKeyIndex(root, parentName)
+	if j < 0 {
+		// Replace the deprecated key with the replacement at the same position
+		lines[key.Line-1] = parentName + ":\n  " + name + rest
+		return strings.Join(lines, "\n"), fmt.Sprintf("moved %s to %s", m.Deprecated, m.Replacement), nil
+	}
+
+	parentKey, parent := root.Content[j], root.Content[j+1]
+	switch {
+	case parent.Kind == yamlv3.ScalarNode && parent.Tag == "!!null" && parent.Value == "":
+		lines[parentKey.Line-1] += "\n  " + name + rest
+	case parent.Kind == yamlv3.MappingNode && parent.Style&yamlv3.FlowStyle == 0 && len(parent.Content) > 0:
+		indent := strings.Repeat(" ", parent.Content[0].Column-1)
+		if k := mappingKeyIndex(parent, name); k >= 0 {
+			existing := parent.Content[k]
+			if parent.Content[k+1].Kind != yamlv3.ScalarNode || parent.Content[k+1].Line != existing.Line {
+				return "", "", fmt.Errorf("unable to migrate %s: %s is not a single-line value. Replace %s with it manually", m.Deprecated, m.Replacement, m.Deprecated)
+			}
+			lines[existing.Line-1] = indent + name + rest
+		} else {
+			lines[parentKey.Line-1] += "\n" + indent + name + rest
+		}
+	default:
+		return "", "", fmt.Errorf("unable to migrate %s: %s is not a block mapping. Replace %s with %s manually", m.Deprecated, parentName, m.Deprecated, m.Replacement)
+	}
+	// The lines added above are joined with newlines, so the line numbers of the others are unchanged
+	lines = append(lines[:key.Line-1], lines[key.Line:]...)
+
+	return strings.Join(lines, "\n"), fmt.Sprintf("moved %s to %s", m.Deprecated, m.Replacement), nil
+}
+
+// mappingKeyIndex returns the index of the key named `name` in the content of the mapping node, or -1 when not found
+func mappingKeyIndex(mapping *yamlv3.Node, name string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateClusterYaml(t *testing.T) {
+	testCases := []struct {
+		context  string
+		input    string
+		expected string
+		changes  []string
+	}{
+		{
+			context: "WithoutReplacements",
+			input: `clusterName: mycluster
+
+# Use an existing VPC
+vpcId: vpc-123 # the vpc
+internetGatewayId: "igw-1"
+subnets:
+- name: a
+  instanceCIDR: 10.0.0.0/24
+`,
+			expected: `clusterName: mycluster
+
+# Use an existing VPC
+vpc:
+  id: vpc-123 # the vpc
+internetGateway:
+  id: "igw-1"
+subnets:
+- name: a
+  instanceCIDR: 10.0.0.0/24
+`,
+			changes: []string{"moved vpcId to vpc.id", "moved internetGatewayId to internetGateway.id"},
+		},
+		{
+			context: "WithReplacements",
+			input: `clusterName: mycluster
+vpcId: vpc-123
+vpc:
+    id: vpc-456
+    cidr: 10.0.0.0/16
+internetGateway:
+    # Comment
+    createTimeout: PT10M
+internetGatewayId: igw-1
+`,
+			expected: `clusterName: mycluster
+vpc:
+    id: vpc-123
+    cidr: 10.0.0.0/16
+internetGateway:
+    id: igw-1
+    # Comment
+    createTimeout: PT10M
+`,
+			changes: []string{"moved vpcId to vpc.id", "moved internetGatewayId to internetGateway.id"},
+		},
+		{
+			context: "WithEmptyValues",
+			input: `clusterName: mycluster
+vpcId:
+vpc:
+internetGatewayId: igw-1
+`,
+			expected: `clusterName: mycluster
+vpc:
+internetGateway:
+  id: igw-1
+`,
+			changes: []string{"removed vpcId without a value", "moved internetGatewayId to internetGateway.id"},
+		},
+		{
+			context:  "WithoutDeprecatedKeys",
+			input:    "clusterName: mycluster\nvpc:\n  id: vpc-123\n#vpcId: vpc-123\n",
+			expected: "clusterName: mycluster\nvpc:\n  id: vpc-123\n#vpcId: vpc-123\n",
+			changes:  []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			out, changes, err := MigrateClusterYaml([]byte(tc.input))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+			assert.Equal(t, tc.changes, changes)
+		})
+	}
+}
+
+func TestMigrateClusterYamlWithFlowMapping(t *testing.T) {
+	_, _, err := MigrateClusterYaml([]byte("vpcId: vpc-123\nvpc: {cidr: 10.0.0.0/16}\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Replace vpcId with vpc.id manually")
+	}
+}
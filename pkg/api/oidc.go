@@ -6,4 +6,13 @@ type Oidc struct {
 	ClientId      string `yaml:"clientId"`
 	UsernameClaim string `yaml:"usernameClaim"`
 	GroupsClaim   string `yaml:"groupsClaim,omitempty"`
+	// SecretValues are the settings resolved from secrets, which are substituted in the kube-apiserver manifest once decrypted on controller nodes
+	SecretValues []SecretValue `yaml:"-"`
+}
+
+// SecretValue is a setting resolved from secrets, which is encrypted into the controller custom file at Path
+// and substituted for the placeholder `$Name` once decrypted
+type SecretValue struct {
+	Name string
+	Path string
 }
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// OverlayListKeys are the lists in cluster.yaml whose items are merged with the items of the same key in an overlay,
+// keyed by the paths to the lists. Any other list in an overlay replaces the list in cluster.yaml as a whole.
+var OverlayListKeys = map[string]string{
+	"apiEndpoints":     "name",
+	"subnets":          "name",
+	"worker.nodePools": "name",
+}
+
+// MergeClusterYaml deep-merges the overlay into cluster.yaml and returns the merged cluster.yaml.
+// Mappings are merged key by key, the lists in OverlayListKeys are merged item by item, and anything else in the overlay
+// replaces the value in cluster.yaml. A key set to null in the overlay removes the key from cluster.yaml.
+func MergeClusterYaml(base []byte, overlay []byte) ([]byte, error) {
+	baseDoc := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(base, baseDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster.yaml: %v", err)
+	}
+	overlayDoc := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(overlay, overlayDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse overlay: %v", err)
+	}
+
+	if len(overlayDoc.Content) == 0 {
+		return base, nil
+	}
+	if overlayDoc.Content[0].Kind != yamlv3.MappingNode {
+		return nil, fmt.Errorf("overlay must be a mapping")
+	}
+	if len(baseDoc.Content) == 0 {
+		baseDoc = overlayDoc
+	} else {
+		if baseDoc.Content[0].Kind != yamlv3.MappingNode {
+			return nil, fmt.Errorf("cluster.yaml must be a mapping")
+		}
+		merged, err := mergeNodes(baseDoc.Content[0], overlayDoc.Content[0], "")
+		if err != nil {
+			return nil, err
+		}
+		baseDoc.Content[0] = merged
+	}
+
+	buf := new(bytes.Buffer)
+	enc := yamlv3.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(baseDoc); err != nil {
+		return nil, fmt.Errorf("failed to encode merged cluster.yaml: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode merged cluster.yaml: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeNodes merges `overlay` into `base` found at `path` in cluster.yaml and returns the merged node
+func mergeNodes(base *yamlv3.Node, overlay *yamlv3.Node, path string) (*yamlv3.Node, error) {
+	if base.Kind == yamlv3.MappingNode && overlay.Kind == yamlv3.MappingNode {
+		for i := 0; i+1 < len(overlay.Content); i += 2 {
+			key, value := overlay.Content[i], overlay.Content[i+1]
+			keyPath := key.Value
+			if path != "" {
+				keyPath = path + "." + key.Value
+			}
+
+			j := mappingKeyIndex(base, key.Value)
+			switch {
+			case value.Kind == yamlv3.ScalarNode && value.Tag == "!!null":
+				if j >= 0 {
+					base.Content = append(base.Content[:j], base.Content[j+2:]...)
+				}
+			case j < 0:
+				base.Content = append(base.Content, key, value)
+			default:
+				merged, err := mergeNodes(base.Content[j+1], value, keyPath)
+				if err != nil {
+					return nil, err
+				}
+				base.Content[j+1] = merged
+			}
+		}
+		return base, nil
+	}
+
+	if name, ok := OverlayListKeys[path]; ok && base.Kind == yamlv3.SequenceNode && overlay.Kind == yamlv3.SequenceNode {
+		for i, item := range overlay.Content {
+			id := mappingValue(item, name)
+			if id == "" {
+				return nil, fmt.Errorf("%s[%d] in overlay: missing %s to merge with the item of the same %s", path, i, name, name)
+			}
+			j := -1
+			for k, existing := range base.Content {
+				if mappingValue(existing, name) == id {
+					j = k
+					break
+				}
+			}
+			if j < 0 {
+				base.Content = append(base.Content, item)
+				continue
+			}
+			merged, err := mergeNodes(base.Content[j], item, fmt.Sprintf("%s[%s=%s]", path, name, id))
+			if err != nil {
+				return nil, err
+			}
+			base.Content[j] = merged
+		}
+		return base, nil
+	}
+
+	return overlay, nil
+}
+
+// mappingValue returns the scalar value of the key named `name` in the mapping node, or an empty string when not found
+func mappingValue(mapping *yamlv3.Node, name string) string {
+	if mapping.Kind != yamlv3.MappingNode {
+		return ""
+	}
+	i := mappingKeyIndex(mapping, name)
+	if i < 0 || mapping.Content[i+1].Kind != yamlv3.ScalarNode {
+		return ""
+	}
+	return mapping.Content[i+1].Value
+}
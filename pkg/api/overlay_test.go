@@ -0,0 +1,139 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeClusterYaml(t *testing.T) {
+	base := `clusterName: mycluster
+# The region
+region: us-west-1
+kubernetesVersion: v1.14.9
+subnets:
+- name: a
+  instanceCIDR: 10.0.0.0/24
+worker:
+  nodePools:
+  - name: general
+    count: 1
+    instanceType: t2.medium
+  - name: gpu
+    count: 1
+controller:
+  count: 1
+  instanceType: t2.medium
+`
+
+	testCases := []struct {
+		context  string
+		overlay  string
+		expected string
+		err      string
+	}{
+		{
+			context: "DeepMerge",
+			overlay: `clusterName: prod
+controller:
+  count: 3
+worker:
+  nodePools:
+  - name: general
+    count: 5
+    rootVolume:
+      size: 100
+  - name: spot
+    count: 2
+subnets:
+- name: b
+  instanceCIDR: 10.0.1.0/24
+kubernetesVersion: null
+`,
+			expected: `clusterName: prod
+# The region
+region: us-west-1
+subnets:
+  - name: a
+    instanceCIDR: 10.0.0.0/24
+  - name: b
+    instanceCIDR: 10.0.1.0/24
+worker:
+  nodePools:
+    - name: general
+      count: 5
+      instanceType: t2.medium
+      rootVolume:
+        size: 100
+    - name: gpu
+      count: 1
+    - name: spot
+      count: 2
+controller:
+  count: 3
+  instanceType: t2.medium
+`,
+		},
+		{
+			context: "ReplaceUnkeyedList",
+			overlay: `worker:
+  nodePools:
+  - name: gpu
+    subnets:
+    - name: b
+controller:
+  subnets:
+  - name: b
+`,
+			expected: `clusterName: mycluster
+# The region
+region: us-west-1
+kubernetesVersion: v1.14.9
+subnets:
+  - name: a
+    instanceCIDR: 10.0.0.0/24
+worker:
+  nodePools:
+    - name: general
+      count: 1
+      instanceType: t2.medium
+    - name: gpu
+      count: 1
+      subnets:
+        - name: b
+controller:
+  count: 1
+  instanceType: t2.medium
+  subnets:
+    - name: b
+`,
+		},
+		{
+			context: "MissingKey",
+			overlay: `worker:
+  nodePools:
+  - count: 2
+`,
+			err: "worker.nodePools[0] in overlay: missing name to merge with the item of the same name",
+		},
+		{
+			context: "NotMapping",
+			overlay: `- name: a`,
+			err:     "overlay must be a mapping",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			merged, err := MergeClusterYaml([]byte(base), []byte(tc.overlay))
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(merged))
+		})
+	}
+}
@@ -29,6 +29,10 @@ func (n S3Folders) ClusterBackups() S3Folder {
 	return n.Cluster().subFolder("backup")
 }
 
+func (n S3Folders) ClusterRevisions() S3Folder {
+	return n.ClusterBackups().subFolder("revisions")
+}
+
 func (n S3Folders) ClusterExportedStacks() S3Folder {
 	return n.Cluster().subFolder("exported/stacks")
 }
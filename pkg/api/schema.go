@@ -0,0 +1,79 @@
+package api
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/kubernetes-incubator/kube-aws/hostos"
+	"github.com/kubernetes-incubator/kube-aws/provisioner"
+	"github.com/kubernetes-incubator/kube-aws/schema"
+)
+
+var volumeTypes = []string{"standard", "gp2", "io1"}
+
+var nodePoolRollingStrategies = []string{"Parallel", "Sequential", "AvailabilityZone", "Batched", "Canary"}
+
+// clusterYaml is the structure of cluster.yaml, in which unknown keys are rejected
+type clusterYaml struct {
+	Cluster     `yaml:",inline"`
+	UnknownKeys `yaml:",inline"`
+}
+
+func schemaEnums() map[string][]string {
+	releaseChannels := []string{}
+	for c := range supportedReleaseChannels {
+		releaseChannels = append(releaseChannels, c)
+	}
+	sort.Strings(releaseChannels)
+
+	return map[string][]string{
+		"DeploymentSettings.ReleaseChannel":          releaseChannels,
+		"DeploymentSettings.UserDataFormat":          {USERDATA_FORMAT_CLOUD_CONFIG, USERDATA_FORMAT_IGNITION, USERDATA_FORMAT_SHELL_SCRIPT},
+		"DeploymentSettings.ContainerRuntime":        containerRuntimes,
+		"HostOS.Name":                                hostos.Names(),
+		"DefaultWorkerSettings.WorkerRootVolumeType": volumeTypes,
+		"RootVolume.Type":                            volumeTypes,
+		"NodeVolumeMount.Type":                       volumeTypes,
+		"NodeVolumeMount.Filesystem":                 {"xfs", "ext4"},
+		"Raid0Mount.Type":                            volumeTypes,
+		"SpotFleet.RootVolumeType":                   volumeTypes,
+		"Worker.NodePoolRollingStrategy":             nodePoolRollingStrategies,
+		"WorkerNodePool.NodePoolRollingStrategy":     nodePoolRollingStrategies,
+		"APIEndpointLB.Type":                         {"classic", "network"},
+		"DataVolume.DeletionPolicy":                  {"Delete", "Retain", "Snapshot"},
+		"StackPolicyStatement.Effect":                {"Allow", "Deny"},
+	}
+}
+
+func schemaGenerator() schema.Generator {
+	shellColours := []interface{}{}
+	for _, c := range _ShellColourValues {
+		shellColours = append(shellColours, c.String())
+	}
+
+	return schema.Generator{
+		UnknownKeysTypes: []reflect.Type{reflect.TypeOf(UnknownKeys{})},
+		Enums:            schemaEnums(),
+		Defaults: map[reflect.Type]interface{}{
+			reflect.TypeOf(WorkerNodePool{}): NewDefaultNodePoolConfig(),
+			reflect.TypeOf(SpotFleet{}):      newDefaultSpotFleet(),
+		},
+		Overrides: map[reflect.Type]*schema.Schema{
+			reflect.TypeOf(provisioner.Content{}): {Type: schema.Types{schema.TypeString}},
+			reflect.TypeOf(CIDRRange{}):           {Type: schema.Types{schema.TypeString}, Description: "IP network range in CIDR notation"},
+			reflect.TypeOf(ShellColour(0)):        {Type: schema.Types{schema.TypeString}, Enum: shellColours},
+		},
+	}
+}
+
+// ClusterSchema returns the JSON Schema of cluster.yaml with the defaults of kube-aws
+func ClusterSchema() *schema.Schema {
+	return schemaGenerator().Generate("cluster.yaml", clusterYaml{Cluster: *NewDefaultCluster()})
+}
+
+// PluginSchema returns the JSON Schema of plugin.yaml, which is strictly unmarshalled
+func PluginSchema() *schema.Schema {
+	g := schemaGenerator()
+	g.Strict = true
+	return g.Generate("plugin.yaml", Plugin{})
+}
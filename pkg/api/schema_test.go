@@ -0,0 +1,68 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterSchema(t *testing.T) {
+	s := ClusterSchema()
+
+	nodePool := s.Properties["worker"].Properties["nodePools"].Items
+	require.NotNil(t, nodePool)
+	assert.Equal(t, "t2.medium", nodePool.Properties["instanceType"].Default)
+	assert.Equal(t, []interface{}{"Parallel", "Sequential", "AvailabilityZone", "Batched", "Canary"}, nodePool.Properties["nodePoolRollingStrategy"].Enum)
+	assert.Contains(t, s.Properties["releaseChannel"].Enum, "stable")
+
+	violations, err := s.ValidateYAML([]byte(`clusterName: mycluster
+keyName: mykey
+region: us-west-1
+kmsKeyArn: "arn:aws:kms:us-west-1:xxxxxxxxx:key/xxxxxxxxxxxxxxxxxxx"
+worker:
+  nodePools:
+  - name: pool1
+    instanceType: t2.large
+    rootVolume:
+      size: 30
+      type: gp3
+    count: two
+s3URI: s3://mybucket/mydir
+apiEndpoints:
+- name: public
+  dnsName: api.example.com
+  loadBalancer:
+    hostedZone:
+      id: hostedzone-xxxx
+controler:
+  count: 2
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"controler: unknown key",
+		"worker.nodePools[0].count: expected integer but was string",
+		`worker.nodePools[0].rootVolume.type: "gp3" is not one of "standard", "gp2", "io1"`,
+	}, violations)
+}
+
+func TestPluginSchema(t *testing.T) {
+	s := PluginSchema()
+
+	violations, err := s.ValidateYAML([]byte(`metadata:
+  name: my-plugin
+  version: 0.0.1
+spec:
+  cluster:
+    values:
+      foo: bar
+    cloudformation:
+      stacks:
+        controlPlane:
+          resources:
+            content: "{}"
+  clustr: {}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"spec.clustr: unknown key"}, violations)
+}
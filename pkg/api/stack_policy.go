@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RootStackPolicyKey is the key of the stack policy of the root stack in `cloudformation.stackPolicies`
+const RootStackPolicyKey = "root"
+
+// StackPolicies are the stack policies keyed by `root`, the names of the network, etcd and control-plane stacks, or node pool names
+type StackPolicies map[string]StackPolicy
+
+// StackPolicy is a CloudFormation stack policy. Updates to all the resources are allowed unless denied by its statements
+type StackPolicy struct {
+	Statements []StackPolicyStatement `yaml:"statements,omitempty"`
+}
+
+// StackPolicyStatement allows or denies update actions on the resources of a stack.
+// See https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/protect-stack-resources.html
+type StackPolicyStatement struct {
+	// Effect is either `Allow` or `Deny`
+	Effect string `yaml:"effect"`
+	// Actions are the update actions e.g. `Update:Replace` and `Update:Delete`
+	Actions    []string `yaml:"actions,omitempty"`
+	NotActions []string `yaml:"notActions,omitempty"`
+	// Resources are the logical IDs of the resources e.g. `Etcd0EBS`, or `*` which is the default
+	Resources    []string `yaml:"resources,omitempty"`
+	NotResources []string `yaml:"notResources,omitempty"`
+	// ResourceTypes limits the statement to the resources of the types e.g. `AWS::EC2::Volume`. Wildcards are allowed
+	ResourceTypes []string `yaml:"resourceTypes,omitempty"`
+}
+
+// allowAllUpdates is the statement prepended to every stack policy, which kube-aws used to set to all the stacks
+var allowAllUpdates = map[string]interface{}{
+	"Effect":    "Allow",
+	"Principal": "*",
+	"Action":    "Update:*",
+	"Resource":  "*",
+}
+
+// Validate checks the stack policies are keyed by known stacks and consist of valid statements
+func (p StackPolicies) Validate(stackNames []string) error {
+	keys := []string{}
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		known := false
+		for _, n := range stackNames {
+			known = known || n == k
+		}
+		if !known {
+			return fmt.Errorf("invalid cloudformation.stackPolicies: unknown stack %s. Specify one of %s", k, strings.Join(stackNames, ", "))
+		}
+		if err := p[k].validate(); err != nil {
+			return fmt.Errorf("invalid cloudformation.stackPolicies.%s: %v", k, err)
+		}
+	}
+	return nil
+}
+
+func (p StackPolicy) validate() error {
+	for i, s := range p.Statements {
+		if s.Effect != "Allow" && s.Effect != "Deny" {
+			return fmt.Errorf("statement #%d: effect must be either Allow or Deny but was \"%s\"", i, s.Effect)
+		}
+		if (len(s.Actions) == 0) == (len(s.NotActions) == 0) {
+			return fmt.Errorf("statement #%d: specify either actions or notActions", i)
+		}
+		if len(s.Resources) > 0 && len(s.NotResources) > 0 {
+			return fmt.Errorf("statement #%d: resources and notResources can not be specified together", i)
+		}
+		for _, a := range append(append([]string{}, s.Actions...), s.NotActions...) {
+			if !strings.HasPrefix(a, "Update:") {
+				return fmt.Errorf("statement #%d: unknown action %s. Specify one of Update:Modify, Update:Replace, Update:Delete and Update:*", i, a)
+			}
+		}
+	}
+	return nil
+}
+
+// Body returns the JSON stack policy body, which allows all updates except denied by the statements
+func (p StackPolicy) Body() string {
+	statements := []interface{}{allowAllUpdates}
+	for _, s := range p.Statements {
+		statement := map[string]interface{}{
+			"Effect":    s.Effect,
+			"Principal": "*",
+		}
+		if len(s.Actions) > 0 {
+			statement["Action"] = s.Actions
+		} else {
+			statement["NotAction"] = s.NotActions
+		}
+		if len(s.NotResources) > 0 {
+			statement["NotResource"] = logicalResourceIDs(s.NotResources)
+		} else if len(s.Resources) > 0 {
+			statement["Resource"] = logicalResourceIDs(s.Resources)
+		} else {
+			statement["Resource"] = "*"
+		}
+		if len(s.ResourceTypes) > 0 {
+			statement["Condition"] = map[string]interface{}{
+				"StringLike": map[string]interface{}{"ResourceType": s.ResourceTypes},
+			}
+		}
+		statements = append(statements, statement)
+	}
+
+	// Marshalling never fails as the policy consists only of strings and lists of strings
+	body, _ := json.MarshalIndent(map[string]interface{}{"Statement": statements}, "", "  ")
+	return string(body)
+}
+
+// logicalResourceIDs qualifies bare logical IDs with `LogicalResourceId/` as required in stack policies
+func logicalResourceIDs(ids []string) []string {
+	qualified := []string{}
+	for _, id := range ids {
+		if id != "*" && !strings.HasPrefix(id, "LogicalResourceId/") {
+			id = "LogicalResourceId/" + id
+		}
+		qualified = append(qualified, id)
+	}
+	return qualified
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStackPolicyBody(t *testing.T) {
+	p := StackPolicy{Statements: []StackPolicyStatement{
+		{Effect: "Deny", Actions: []string{"Update:Replace", "Update:Delete"}, Resources: []string{"Etcd0EBS", "LogicalResourceId/Etcd1EBS"}},
+		{Effect: "Deny", NotActions: []string{"Update:Modify"}, ResourceTypes: []string{"AWS::EC2::Volume"}},
+	}}
+
+	assert.JSONEq(t, `{
+  "Statement": [
+    {"Effect": "Allow", "Principal": "*", "Action": "Update:*", "Resource": "*"},
+    {"Effect": "Deny", "Principal": "*", "Action": ["Update:Replace", "Update:Delete"], "Resource": ["LogicalResourceId/Etcd0EBS", "LogicalResourceId/Etcd1EBS"]},
+    {"Effect": "Deny", "Principal": "*", "NotAction": ["Update:Modify"], "Resource": "*", "Condition": {"StringLike": {"ResourceType": ["AWS::EC2::Volume"]}}}
+  ]
+}`, p.Body())
+
+	assert.JSONEq(t, `{"Statement": [{"Effect": "Allow", "Principal": "*", "Action": "Update:*", "Resource": "*"}]}`, StackPolicy{}.Body())
+}
+
+func TestStackPoliciesValidate(t *testing.T) {
+	stackNames := []string{"root", "network", "etcd", "control-plane", "pool1"}
+
+	testCases := []struct {
+		context  string
+		policies StackPolicies
+		expected string
+	}{
+		{
+			context:  "Valid",
+			policies: StackPolicies{"etcd": {Statements: []StackPolicyStatement{{Effect: "Deny", Actions: []string{"Update:Delete"}}}}, "pool1": {}},
+		},
+		{
+			context:  "UnknownStack",
+			policies: StackPolicies{"pool2": {}},
+			expected: "invalid cloudformation.stackPolicies: unknown stack pool2. Specify one of root, network, etcd, control-plane, pool1",
+		},
+		{
+			context:  "InvalidEffect",
+			policies: StackPolicies{"root": {Statements: []StackPolicyStatement{{Effect: "deny", Actions: []string{"Update:Delete"}}}}},
+			expected: `invalid cloudformation.stackPolicies.root: statement #0: effect must be either Allow or Deny but was "deny"`,
+		},
+		{
+			context:  "NoActions",
+			policies: StackPolicies{"etcd": {Statements: []StackPolicyStatement{{Effect: "Deny"}}}},
+			expected: "invalid cloudformation.stackPolicies.etcd: statement #0: specify either actions or notActions",
+		},
+		{
+			context:  "UnknownAction",
+			policies: StackPolicies{"etcd": {Statements: []StackPolicyStatement{{Effect: "Deny", Actions: []string{"Delete"}}}}},
+			expected: "invalid cloudformation.stackPolicies.etcd: statement #0: unknown action Delete. Specify one of Update:Modify, Update:Replace, Update:Delete and Update:*",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			err := tc.policies.Validate(stackNames)
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expected)
+			}
+		})
+	}
+}
@@ -4,7 +4,11 @@ type Worker struct {
 	APIEndpointName         string           `yaml:"apiEndpointName,omitempty"`
 	NodePools               []WorkerNodePool `yaml:"nodePools,omitempty"`
 	NodePoolRollingStrategy string           `yaml:"nodePoolRollingStrategy,omitempty"`
-	UnknownKeys             `yaml:",inline"`
+	// NodePoolRollingMaxParallel is the number of node pools rolled at a time by the 'Batched' and 'Canary' rolling strategies
+	NodePoolRollingMaxParallel int `yaml:"nodePoolRollingMaxParallel,omitempty"`
+	// NodePoolRollingCanary is the name of the node pool rolled before the others by the 'Canary' rolling strategy
+	NodePoolRollingCanary string `yaml:"nodePoolRollingCanary,omitempty"`
+	UnknownKeys           `yaml:",inline"`
 }
 
 // Kubelet options
@@ -98,6 +102,10 @@ type Webhook struct {
 	Enabled  bool   `yaml:"enabled"`
 	CacheTTL string `yaml:"cacheTTL"`
 	Config   string `yaml:"configBase64"`
+	// Secret is true when the config is resolved from secrets, so that it must be encrypted before being rendered
+	Secret bool `yaml:"-"`
+	// Encrypted is true when the config has been replaced with the base64-encoded ciphertext of the config encrypted with KMS
+	Encrypted bool `yaml:"-"`
 }
 
 type AwsEnvironment struct {
@@ -153,6 +161,9 @@ type LocalStreaming struct {
 }
 
 type HostOS struct {
+	// Name is the name of the operating system of nodes, which defaults to flatcar.
+	// Controller and etcd nodes run only Flatcar, whereas worker node pools can run any OS supported by the hostos package
+	Name       string     `yaml:"name,omitempty"`
 	BashPrompt BashPrompt `yaml:"bashPrompt,omitempty"`
 	MOTDBanner MOTDBanner `yaml:"motdBanner,omitempty"`
 }
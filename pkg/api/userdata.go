@@ -7,6 +7,7 @@ import (
 	"github.com/kubernetes-incubator/kube-aws/filereader/texttemplate"
 
 	"github.com/kubernetes-incubator/kube-aws/gzipcompressor"
+	"github.com/kubernetes-incubator/kube-aws/hostos"
 
 	"bytes"
 	"encoding/base64"
@@ -23,12 +24,18 @@ const (
 	USERDATA_INSTANCE = "instance"
 
 	USERDATA_INSTANCE_SCRIPT = "instance-script"
+
+	USERDATA_FORMAT_CLOUD_CONFIG = hostos.UserDataFormatCloudConfig
+	USERDATA_FORMAT_IGNITION     = hostos.UserDataFormatIgnition
+	USERDATA_FORMAT_SHELL_SCRIPT = hostos.UserDataFormatShellScript
 )
 
 // UserData represents userdata which might be split across multiple storage types
 type UserData struct {
 	Parts map[string]*UserDataPart
 	Path  string
+	// Format is one of USERDATA_FORMAT_CLOUD_CONFIG, USERDATA_FORMAT_IGNITION and USERDATA_FORMAT_SHELL_SCRIPT
+	Format string
 }
 
 type UserDataPart struct {
@@ -36,6 +43,8 @@ type UserDataPart struct {
 	tmpl     *template.Template
 	tmplData interface{}
 	validate UserDataValidateFunc
+	// convert converts the validated content e.g. to an Ignition config or a shell script. nil leaves the content as is
+	convert func(content string) (string, error)
 }
 
 type PartDesc struct {
@@ -51,7 +60,8 @@ var (
 )
 
 type userDataOpt struct {
-	Parts []PartDesc // userdata Parts in template file
+	Parts  []PartDesc // userdata Parts in template file
+	Format string
 }
 
 type UserDataOption func(*userDataOpt)
@@ -63,11 +73,19 @@ func UserDataPartsOpt(Parts ...PartDesc) UserDataOption {
 	}
 }
 
+// UserDataFormatOpt makes the S3 and instance parts converted to Ignition configs when the format is USERDATA_FORMAT_IGNITION,
+// and the S3 part converted to a shell script when the format is USERDATA_FORMAT_SHELL_SCRIPT
+func UserDataFormatOpt(format string) UserDataOption {
+	return func(o *userDataOpt) {
+		o.Format = format
+	}
+}
+
 // NewUserDataFromTemplateFile creates userdata struct from template file.
 // Template file is expected to have defined subtemplates (Parts) which are of various part and storage types
 // TODO Extract this out of the clusterapi package as this is an "implementation"
 func NewUserDataFromTemplateFile(templateFile string, context interface{}, opts ...UserDataOption) (UserData, error) {
-	v := UserData{Parts: make(map[string]*UserDataPart), Path: templateFile}
+	v := UserData{Parts: make(map[string]*UserDataPart), Path: templateFile, Format: USERDATA_FORMAT_CLOUD_CONFIG}
 
 	funcs := template.FuncMap{
 		"self": func() UserData { return v },
@@ -104,6 +122,24 @@ func NewUserDataFromTemplateFile(templateFile string, context interface{}, opts
 			validate: p.validateFunc,
 		}
 	}
+
+	switch o.Format {
+	case USERDATA_FORMAT_IGNITION:
+		v.Format = o.Format
+		if p, ok := v.Parts[USERDATA_S3]; ok {
+			p.convert = ignitionS3Part
+		}
+		if p, ok := v.Parts[USERDATA_INSTANCE]; ok {
+			p.convert = func(instance string) (string, error) {
+				return ignitionInstancePart(v, instance)
+			}
+		}
+	case USERDATA_FORMAT_SHELL_SCRIPT:
+		v.Format = o.Format
+		if p, ok := v.Parts[USERDATA_S3]; ok {
+			p.convert = hostos.ShellScriptFromCloudConfig
+		}
+	}
 	return v, nil
 }
 
@@ -142,7 +178,10 @@ func (self UserDataPart) Template(extra ...map[string]interface{}) (string, erro
 
 	// we validate userdata at render time, because we need to wait for
 	// optional extra context to produce final output
-	return result, self.validate(buf.Bytes())
+	if err := self.validate(buf.Bytes()); err != nil || self.convert == nil {
+		return result, err
+	}
+	return self.convert(result)
 }
 
 func validateCoreosCloudInit(content []byte) error {
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-aws/ignition"
+)
+
+const (
+	// ignitionInstanceScriptPath is where the Ignition instance part writes the instance script to
+	ignitionInstanceScriptPath = "/opt/kube-aws/bin/instance-script"
+	// ignitionInstanceScriptUnit is the unit running the instance script on every boot
+	ignitionInstanceScriptUnit = "kube-aws-instance-script.service"
+	// ignitionInstanceScriptMarker is the base64-encoded placeholder of the instance script, which is replaced with the
+	// script base64-encoded by CloudFormation as the script contains references to e.g. the stack name
+	ignitionInstanceScriptMarker = "S1VCRS1BV1MtSU5TVEFOQ0UtU0NSSVBU"
+)
+
+// ignitionS3Part converts the cloud-config rendered for the S3 part to an Ignition config
+func ignitionS3Part(cloudConfig string) (string, error) {
+	c, err := ignition.FromCloudConfig(cloudConfig)
+	if err != nil {
+		return "", err
+	}
+	return c.Render()
+}
+
+// ignitionInstancePart converts the instance part, which is the CloudFormation expression of the bash script
+// in `Fn::Base64`, to the expression of an Ignition config.
+// The Ignition config merges the S3 part, and runs the script on every boot before the commands of the units in the S3 part.
+func ignitionInstancePart(ud UserData, instance string) (string, error) {
+	expr := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(instance), &expr); err != nil {
+		return "", fmt.Errorf("failed to parse the instance part: %v", err)
+	}
+	script, ok := expr["Fn::Base64"]
+	if !ok || len(expr) != 1 {
+		return "", fmt.Errorf("the instance part must be a Fn::Base64 expression to be converted to Ignition")
+	}
+
+	s3, ok := ud.Parts[USERDATA_S3]
+	if !ok {
+		return "", fmt.Errorf("the instance part can't be converted to Ignition without the s3 part")
+	}
+	s3URL, err := s3.Asset.S3URL()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the s3 part: %v", err)
+	}
+
+	config, err := ignition.Config{
+		Merge: []string{s3URL},
+		Files: []ignition.File{{
+			Path:   ignitionInstanceScriptPath,
+			Mode:   0755,
+			Source: "data:;base64," + ignitionInstanceScriptMarker,
+		}},
+		Units: []ignition.Unit{{
+			Name:    ignitionInstanceScriptUnit,
+			Enabled: true,
+			Contents: fmt.Sprintf(`[Unit]
+Description=Run the kube-aws instance script
+Wants=network-online.target
+After=network-online.target
+Before=%s
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=%s
+
+[Install]
+WantedBy=multi-user.target
+`, ignition.CommandsUnitName, ignitionInstanceScriptPath),
+		}},
+	}.Render()
+	if err != nil {
+		return "", err
+	}
+
+	surrounding := strings.Split(config, ignitionInstanceScriptMarker)
+	if len(surrounding) != 2 {
+		return "", fmt.Errorf("[bug] expected exactly one instance script in the Ignition config but found %d", len(surrounding)-1)
+	}
+	converted, err := json.Marshal(map[string]interface{}{
+		"Fn::Base64": map[string]interface{}{
+			"Fn::Join": []interface{}{"", []interface{}{
+				surrounding[0],
+				map[string]interface{}{"Fn::Base64": script},
+				surrounding[1],
+			}},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render the instance part: %v", err)
+	}
+	return string(converted), nil
+}
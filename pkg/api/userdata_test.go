@@ -1,8 +1,10 @@
 package api
 
 import (
+	"github.com/kubernetes-incubator/kube-aws/ignition"
 	"github.com/stretchr/testify/assert"
 
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -113,3 +115,26 @@ func TestUserDataNew(t *testing.T) {
 		})
 	}
 }
+
+func TestIgnitionInstancePart(t *testing.T) {
+	ud := UserData{Parts: map[string]*UserDataPart{
+		USERDATA_S3: {Asset: Asset{AssetLocation: AssetLocation{Bucket: "bucket", Key: "cluster/userdata-worker"}}},
+	}}
+	converted, err := ignitionInstancePart(ud, `{"Fn::Base64": {"Fn::Sub": "#!/bin/bash\necho ${AWS::StackName}"}}`)
+	assert.NoError(t, err)
+
+	expr := map[string]map[string][]interface{}{}
+	if !assert.NoError(t, json.Unmarshal([]byte(converted), &expr)) {
+		return
+	}
+	joined := expr["Fn::Base64"]["Fn::Join"][1].([]interface{})
+	assert.Len(t, joined, 3)
+	assert.Equal(t, map[string]interface{}{"Fn::Base64": map[string]interface{}{"Fn::Sub": "#!/bin/bash\necho ${AWS::StackName}"}}, joined[1])
+
+	config := joined[0].(string) + "ZWNobw==" + joined[2].(string)
+	assert.NoError(t, ignition.Validate([]byte(config)))
+	assert.Contains(t, config, `"merge":[{"source":"s3://bucket/cluster/userdata-worker"}]`)
+
+	_, err = ignitionInstancePart(ud, `{"Fn::Join": ["", ["#!/bin/bash"]]}`)
+	assert.EqualError(t, err, "the instance part must be a Fn::Base64 expression to be converted to Ignition")
+}
@@ -219,6 +219,28 @@ func (c WorkerNodePool) Validate(experimental Experimental) error {
 	return c.validate(experimental.GpuSupport.Enabled)
 }
 
+// ValidateInstanceTypes rejects the instance types of the node pool which can't boot the amd64 AMIs nodes run
+func (c WorkerNodePool) ValidateInstanceTypes() error {
+	instanceTypes := []string{c.InstanceType}
+	if c.AutoScalingGroup.MixedInstances.Enabled {
+		instanceTypes = append(instanceTypes, c.AutoScalingGroup.MixedInstances.InstanceTypes...)
+	}
+	if c.SpotFleet.Enabled() {
+		for _, spec := range c.SpotFleet.LaunchSpecifications {
+			instanceTypes = append(instanceTypes, spec.InstanceType)
+		}
+	}
+	for _, t := range instanceTypes {
+		if t == "" {
+			continue
+		}
+		if err := validateEC2InstanceType(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c WorkerNodePool) WithDefaultsFrom(main DefaultWorkerSettings) WorkerNodePool {
 	if c.RootVolume.Type == "" {
 		c.RootVolume.Type = main.WorkerRootVolumeType
@@ -2,6 +2,8 @@ package api
 
 import (
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func intp(v int) *int {
@@ -118,3 +120,16 @@ func TestNodePoolRollingUpdateMinInstancesInService(t *testing.T) {
 		t.Errorf("min instances in service should be 2 but was %d in %+v", c6.RollingUpdateMinInstancesInService(), c6)
 	}
 }
+
+func TestNodePoolValidateInstanceTypes(t *testing.T) {
+	pool := NewDefaultNodePoolConfig()
+	assert.NoError(t, pool.ValidateInstanceTypes())
+
+	pool.AutoScalingGroup.MixedInstances = MixedInstances{Enabled: true, InstanceTypes: []string{"m5.large", "m6g.large"}}
+	assert.EqualError(t, pool.ValidateInstanceTypes(), "instance type m6g.large has arm64 processors, which are not supported as nodes run amd64 AMIs and images")
+
+	pool = NewDefaultNodePoolConfig()
+	pool.SpotFleet.TargetCapacity = 2
+	pool.SpotFleet.LaunchSpecifications = []LaunchSpecification{NewLaunchSpecification(1, "c5.large"), NewLaunchSpecification(2, "c6g.xlarge")}
+	assert.EqualError(t, pool.ValidateInstanceTypes(), "instance type c6g.xlarge has arm64 processors, which are not supported as nodes run amd64 AMIs and images")
+}
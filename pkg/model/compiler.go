@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/kubernetes-incubator/kube-aws/flatcar/amiregistry"
 	"github.com/kubernetes-incubator/kube-aws/pkg/api"
 	"github.com/pkg/errors"
 )
@@ -25,7 +24,7 @@ func Compile(cfgRef *api.Cluster, opts api.ClusterOptions) (*Config, error) {
 
 	if c.AmiId == "" {
 		var err error
-		if config.AMI, err = amiregistry.GetAMI(config.Region.String(), config.ReleaseChannel); err != nil {
+		if config.AMI, err = c.HostOS.OS().AMISource().Lookup(c.AMIQuery()); err != nil {
 			return nil, errors.Wrapf(err, "failed getting AMI for config: %v", err)
 		}
 	} else {
@@ -123,8 +122,8 @@ func Compile(cfgRef *api.Cluster, opts api.ClusterOptions) (*Config, error) {
 			}
 		}
 
-		if np.NodePoolRollingStrategy != "Parallel" && np.NodePoolRollingStrategy != "Sequential" && np.NodePoolRollingStrategy != "AvailabilityZone" {
-			if c.Worker.NodePoolRollingStrategy == "Sequential" || c.Worker.NodePoolRollingStrategy == "Parallel" || c.Worker.NodePoolRollingStrategy == "AvailabilityZone" {
+		if !isNodePoolRollingStrategy(np.NodePoolRollingStrategy) {
+			if isNodePoolRollingStrategy(c.Worker.NodePoolRollingStrategy) {
 				np.NodePoolRollingStrategy = c.Worker.NodePoolRollingStrategy
 			} else {
 				np.NodePoolRollingStrategy = "AvailabilityZone"
@@ -134,5 +133,21 @@ func Compile(cfgRef *api.Cluster, opts api.ClusterOptions) (*Config, error) {
 		config.Worker.NodePools[i] = np
 	}
 
+	stackNames := []string{api.RootStackPolicyKey, config.NetworkStackName(), config.EtcdStackName(), config.ControlPlaneStackName()}
+	for _, np := range config.Worker.NodePools {
+		stackNames = append(stackNames, np.NodePoolName)
+	}
+	if err := c.CloudFormation.StackPolicies.Validate(stackNames); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
+
+func isNodePoolRollingStrategy(s string) bool {
+	switch s {
+	case "Parallel", "Sequential", "AvailabilityZone", "Batched", "Canary":
+		return true
+	}
+	return false
+}
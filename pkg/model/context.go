@@ -50,6 +50,11 @@ func (s *Context) stackProvisioner(c *Stack) *cfnstack.Provisioner {
 }
 
 func (s *Context) InspectEtcdExistingState(c *Config) (api.EtcdExistingState, error) {
+	if s.Offline {
+		// Render the etcd stack as if it were created for the first time
+		return api.EtcdExistingState{}, nil
+	}
+
 	var err error
 	if s.ProvidedCFInterrogator == nil {
 		s.ProvidedCFInterrogator = cloudformation.New(s.Session)
@@ -1,8 +1,11 @@
 package model
 
 import (
+	"encoding/base64"
+
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/kubernetes-incubator/kube-aws/credential"
+	"github.com/kubernetes-incubator/kube-aws/gzipcompressor"
 	"github.com/kubernetes-incubator/kube-aws/pkg/api"
 )
 
@@ -12,6 +15,9 @@ func LoadCredentials(sess *session.Session, cfg *Config, opts api.StackTemplateO
 }
 
 func (s *Context) LoadCredentials(cfg *Config, opts api.StackTemplateOptions) (*credential.CompactAssets, error) {
+	if s.Offline {
+		return offlineCompactAssets()
+	}
 	if cfg.AssetsEncryptionEnabled() {
 		kmsConfig := credential.NewKMSConfig(cfg.KMSKeyARN, s.ProvidedEncryptService, s.Session)
 		compactAssets, err := credential.ReadOrCreateCompactAssets(opts.AssetsDir, cfg.ManageCertificates, true, kmsConfig)
@@ -55,3 +61,41 @@ func (s *Context) GenerateAssetsOnDisk(c *Config, dir string, opts credential.Ge
 	r := NewCredentialGenerator(c)
 	return r.GenerateAssetsOnDisk(dir, opts)
 }
+
+// offlinePlaceholderAsset is used in place of every credential when stacks are rendered offline,
+// so that neither KMS nor the credentials on disk are required
+const offlinePlaceholderAsset = "offline"
+
+func offlineCompactAssets() (*credential.CompactAssets, error) {
+	p, err := gzipcompressor.StringToGzippedBase64String(offlinePlaceholderAsset)
+	if err != nil {
+		return nil, err
+	}
+	return &credential.CompactAssets{
+		CACert:                    p,
+		CAKey:                     p,
+		WorkerCACert:              p,
+		WorkerCAKey:               p,
+		APIServerCert:             p,
+		APIServerKey:              p,
+		APIServerAggregatorCert:   p,
+		APIServerAggregatorKey:    p,
+		KubeControllerManagerCert: p,
+		KubeControllerManagerKey:  p,
+		KubeSchedulerCert:         p,
+		KubeSchedulerKey:          p,
+		WorkerCert:                p,
+		WorkerKey:                 p,
+		AdminCert:                 p,
+		AdminKey:                  p,
+		EtcdCert:                  p,
+		EtcdClientCert:            p,
+		EtcdClientKey:             p,
+		EtcdKey:                   p,
+		EtcdTrustedCA:             p,
+		ServiceAccountKey:         p,
+		AuthTokens:                p,
+		TLSBootstrapToken:         p,
+		EncryptionConfig:          base64.StdEncoding.EncodeToString([]byte(offlinePlaceholderAsset)),
+	}, nil
+}
@@ -8,8 +8,8 @@ import (
 )
 
 type Info struct {
-	Name            string
-	ControllerHosts []string
+	Name            string   `json:"name" yaml:"name"`
+	ControllerHosts []string `json:"controllerHosts" yaml:"controllerHosts"`
 }
 
 func (c *Info) String() string {
@@ -3,7 +3,6 @@ package model
 import (
 	"fmt"
 
-	"github.com/kubernetes-incubator/kube-aws/flatcar/amiregistry"
 	"github.com/kubernetes-incubator/kube-aws/logger"
 	"github.com/kubernetes-incubator/kube-aws/pkg/api"
 	"github.com/pkg/errors"
@@ -21,7 +20,10 @@ func nodePoolPreprocess(c api.WorkerNodePool, main *Config) (*api.WorkerNodePool
 
 	// Inherit parameters from the control plane stack
 	c.KubeClusterSettings = main.KubeClusterSettings
+	// Node pools inherit the bash prompt and the MOTD banner, whereas the host OS is defaulted by WithDefaultsFrom
+	hostOSName := c.HostOS.Name
 	c.HostOS = main.HostOS
+	c.HostOS.Name = hostOSName
 	c.Experimental.NodeDrainer = main.DeploymentSettings.Experimental.NodeDrainer
 	c.Experimental.GpuSupport = main.DeploymentSettings.Experimental.GpuSupport
 	c.Experimental.CloudControllerManager = main.DeploymentSettings.Experimental.CloudControllerManager
@@ -82,7 +84,7 @@ func NodePoolCompile(spec api.WorkerNodePool, main *Config) (*NodePoolConfig, er
 	var ami string
 	if spec.AmiId == "" {
 		var err error
-		if ami, err = amiregistry.GetAMI(main.Region.String(), cfg.ReleaseChannel); err != nil {
+		if ami, err = cfg.HostOS.OS().AMISource().Lookup(cfg.AMIQuery()); err != nil {
 			return nil, errors.Wrapf(err, "unable to fetch AMI for worker node pool \"%s\"", spec.NodePoolName)
 		}
 	} else {
@@ -114,7 +116,7 @@ func NodePoolCompile(spec api.WorkerNodePool, main *Config) (*NodePoolConfig, er
 	}
 
 	if !apiEndpoint.LoadBalancer.ManageELBRecordSet() {
-		fmt.Printf(`WARN: the worker node pool "%s" is associated to a k8s API endpoint behind the DNS name "%s" managed by YOU!
+		logger.Warnf(`the worker node pool "%s" is associated to a k8s API endpoint behind the DNS name "%s" managed by YOU!
 Please never point the DNS record for it to a different k8s cluster, especially when the name is a "stable" one which is shared among multiple k8s clusters for achieving blue-green deployments of k8s clusters!
 kube-aws can't save users from mistakes like that
 `, c.NodePoolName, apiEndpoint.DNSName)
@@ -103,6 +103,18 @@ func (c NodePoolConfig) Validate() error {
 		return err
 	}
 
+	if err := c.Gpu.ValidateContainerRuntime(c.ContainerRuntime); err != nil {
+		return fmt.Errorf("invalid node pool %s: %v", c.NodePoolName, err)
+	}
+
+	if err := c.Gpu.ValidateHostOS(c.HostOS.OS()); err != nil {
+		return fmt.Errorf("invalid node pool %s: %v", c.NodePoolName, err)
+	}
+
+	if err := c.WorkerNodePool.ValidateInstanceTypes(); err != nil {
+		return fmt.Errorf("invalid node pool %s: %v", c.NodePoolName, err)
+	}
+
 	if err := c.WorkerDeploymentSettings().Validate(); err != nil {
 		return err
 	}
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"text/tabwriter"
+
+	"github.com/kubernetes-incubator/kube-aws/cfnstack"
 )
 
 type NodePoolStackInfo struct {
-	Name string
+	Name  string                `json:"name" yaml:"name"`
+	Stack *cfnstack.StackStatus `json:"stack,omitempty" yaml:"stack,omitempty"`
 }
 
 func (c *NodePoolStackInfo) String() string {
@@ -15,7 +18,14 @@ func (c *NodePoolStackInfo) String() string {
 	w := new(tabwriter.Writer)
 	w.Init(buf, 0, 8, 0, '\t', 0)
 
-	fmt.Fprintf(w, "Cluster Name:\t%s\n", c.Name)
+	fmt.Fprintf(w, "Node Pool Name:\t%s\n", c.Name)
+	if c.Stack != nil {
+		fmt.Fprintf(w, "Stack Name:\t%s\n", c.Stack.StackName)
+		fmt.Fprintf(w, "Stack Status:\t%s\n", c.Stack.Status)
+		for _, g := range c.Stack.Groups {
+			fmt.Fprintf(w, "%s:\tdesired=%d in-service=%d pending=%d\n", g.LogicalID, g.Desired, g.InService, g.Pending)
+		}
+	}
 
 	w.Flush()
 	return buf.String()
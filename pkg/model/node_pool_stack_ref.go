@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/kubernetes-incubator/kube-aws/cfnstack"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
 )
 
 type ec2DescribeKeyPairsService interface {
@@ -29,6 +30,11 @@ func newNodePoolStackRef(cfg *NodePoolConfig, session *session.Session) *NodePoo
 	}
 }
 
+// NewNodePoolStackRef returns a reference to the existing stack of the node pool without compiling its config
+func NewNodePoolStackRef(np api.WorkerNodePool, session *session.Session) *NodePoolStackRef {
+	return newNodePoolStackRef(&NodePoolConfig{WorkerNodePool: np}, session)
+}
+
 func (c *NodePoolStackRef) validateKeyPair(ec2Svc ec2DescribeKeyPairsService) error {
 	_, err := ec2Svc.DescribeKeyPairs(&ec2.DescribeKeyPairsInput{
 		KeyNames: []*string{aws.String(c.KeyName)},
@@ -100,6 +106,20 @@ func (c *NodePoolStackRef) Info() (*NodePoolStackInfo, error) {
 	return &info, nil
 }
 
+// InfoWithStatus returns the info of this node pool along with the status of its nested stack named `stackName`
+func (c *NodePoolStackRef) InfoWithStatus(describer cfnstack.StatusDescriber, stackName string) (*NodePoolStackInfo, error) {
+	info, err := c.Info()
+	if err != nil {
+		return nil, err
+	}
+	status, _, err := describer.Describe(c.NodePoolName, stackName)
+	if err != nil {
+		return nil, err
+	}
+	info.Stack = status
+	return info, nil
+}
+
 func (c *NodePoolStackRef) Destroy() error {
 	return cfnstack.NewDestroyer(c.StackName(), c.session, c.CloudFormation.RoleARN).Destroy()
 }
@@ -145,6 +145,61 @@ worker:
 `,
 			expectedErrorMessage: "although you can't customize `region` per node pool but you did specify",
 		},
+		{
+			context: "WithNvidiaGpuAndContainerd",
+			configYaml: mainClusterYaml + `
+    instanceType: p2.xlarge
+    gpu:
+      nvidia:
+        enabled: true
+        version: "384.66"
+containerRuntime: containerd
+`,
+			expectedErrorMessage: "invalid node pool foo: gpu.nvidia relies on the Accelerators feature of kubelet which works only with the docker container runtime",
+		},
+		{
+			context: "WithDockerOnlyKubeletFlagAndContainerd",
+			configYaml: mainClusterYaml + `
+    kubelet:
+      flags:
+      - name: network-plugin
+        value: cni
+containerRuntime: containerd
+`,
+			expectedErrorMessage: "invalid node pool foo: kubelet flag --network-plugin works only with the docker container runtime but containerRuntime is containerd",
+		},
+		{
+			context: "WithNvidiaGpuAndAmazonLinux2",
+			configYaml: mainClusterYaml + `
+    instanceType: p2.xlarge
+    amiId: ami-0123456789abcdef0
+    hostOS:
+      name: amazon-linux-2
+    gpu:
+      nvidia:
+        enabled: true
+        version: "384.66"
+`,
+			expectedErrorMessage: "invalid node pool foo: gpu.nvidia is not supported by host OS amazon-linux-2",
+		},
+		{
+			context: "WithIgnitionAndAmazonLinux2",
+			configYaml: mainClusterYaml + `
+    amiId: ami-0123456789abcdef0
+    hostOS:
+      name: amazon-linux-2
+    userDataFormat: ignition
+`,
+			expectedErrorMessage: "invalid node pool foo: userDataFormat ignition is not supported by host OS amazon-linux-2",
+		},
+		{
+			context: "WithArm64InstanceType",
+			configYaml: mainClusterYaml + `
+    instanceType: m6g.large
+    amiId: ami-0123456789abcdef0
+`,
+			expectedErrorMessage: "invalid node pool foo: instance type m6g.large has arm64 processors, which are not supported as nodes run amd64 AMIs and images",
+		},
 	}
 
 	for _, invalidCase := range parseErrorCases {
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+
+	"github.com/kubernetes-incubator/kube-aws/credential"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+)
+
+// EncryptSecrets encrypts the content of the controller and worker custom files and the authentication webhook config resolved from secrets with KMS,
+// so that they are written to userdata as credentials and decrypted on nodes like the other credentials.
+// The ciphertexts are cached under `assetsDir` to not replace nodes on every apply, as KMS produces a different ciphertext every time.
+func (s *Context) EncryptSecrets(cfg *Config, assetsDir string) error {
+	if s.Offline {
+		return nil
+	}
+
+	var store *credential.Store
+	encrypt := func(name string, path string, plaintext []byte) (*credential.EncryptedFile, error) {
+		if !cfg.AssetsEncryptionEnabled() {
+			return nil, fmt.Errorf("%s: secrets can't be referenced without encrypting assets with KMS", name)
+		}
+		if store == nil {
+			s := credential.NewKMSConfig(cfg.KMSKeyARN, s.ProvidedEncryptService, s.Session).Store()
+			store = &s
+		}
+		encrypted, err := store.EncryptedSecret(filepath.Join(assetsDir, "secrets", path), plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt %s: %v", name, err)
+		}
+		return encrypted, nil
+	}
+
+	encryptCustomFile := func(dir string, f *api.CustomFile) error {
+		if !f.Secret || f.Encrypted() {
+			return nil
+		}
+		encrypted, err := encrypt(f.Path, filepath.Join(dir, f.Path), []byte(f.Content))
+		if err != nil {
+			return err
+		}
+		f.Content = encrypted.String()
+		f.Type = "credential"
+		return nil
+	}
+
+	for i := range cfg.Controller.CustomFiles {
+		if err := encryptCustomFile("controller", &cfg.Controller.CustomFiles[i]); err != nil {
+			return err
+		}
+	}
+	for i := range cfg.NodePools {
+		np := &cfg.NodePools[i]
+		for j := range np.CustomFiles {
+			if err := encryptCustomFile(filepath.Join("nodepools", np.NodePoolName), &np.CustomFiles[j]); err != nil {
+				return err
+			}
+		}
+	}
+
+	webhook := &cfg.Experimental.Authentication.Webhook
+	if webhook.Enabled && webhook.Secret && !webhook.Encrypted {
+		config, err := base64.StdEncoding.DecodeString(webhook.Config)
+		if err != nil {
+			return fmt.Errorf("experimental.authentication.webhook.configBase64 must be base64-encoded: %v", err)
+		}
+		encrypted, err := encrypt("the authentication webhook config", "controller/webhooks/authentication.yaml", config)
+		if err != nil {
+			return err
+		}
+		webhook.Config = base64.StdEncoding.EncodeToString(encrypted.Bytes())
+		webhook.Encrypted = true
+	}
+	return nil
+}
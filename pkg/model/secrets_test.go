@@ -0,0 +1,125 @@
+package model
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/coreos-cloudinit/config/validate"
+	"github.com/kubernetes-incubator/kube-aws/credential"
+	"github.com/kubernetes-incubator/kube-aws/pkg/api"
+	"github.com/kubernetes-incubator/kube-aws/plugin/clusterextension"
+	"github.com/kubernetes-incubator/kube-aws/test/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptSecrets(t *testing.T) {
+	pwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	webhookConfig := base64.StdEncoding.EncodeToString([]byte("kind: Config\n"))
+
+	newCluster := func() *api.Cluster {
+		c := api.NewDefaultCluster()
+		c.HyperkubeImage.Tag = c.K8sVer
+		c.Region = api.RegionForName("us-west-1")
+		c.Subnets = []api.Subnet{
+			api.NewPublicSubnet("us-west-1a", "10.0.1.0/24"),
+		}
+		c.ExternalDNSName = "foo.example.com"
+		c.KeyName = "mykey"
+		c.AmiId = "ami-12345678"
+		c.S3URI = "s3://mybucket/mydir"
+		c.KMSKeyARN = "arn:aws:kms:us-west-1:xxxxxxxxx:key/xxxxxxxxxxxxxxxxxxx"
+		c.Controller.CustomFiles = []api.CustomFile{
+			{Path: "/etc/app/token", Permissions: 0600, Content: "mytoken", Secret: true},
+			{Path: "/etc/app/config", Permissions: 0644, Content: "myconfig"},
+		}
+		c.Experimental.Authentication.Webhook = api.Webhook{
+			Enabled:  true,
+			CacheTTL: "5m0s",
+			Config:   webhookConfig,
+			Secret:   true,
+		}
+		require.NoError(t, c.Load())
+		return c
+	}
+
+	t.Run("Encrypted", func(t *testing.T) {
+		helper.WithDummyCredentials(func(dir string) {
+			c := newCluster()
+			c.Experimental.Oidc = api.Oidc{
+				Enabled:      true,
+				IssuerUrl:    "https://accounts.example.com",
+				ClientId:     "$OIDC_CLIENT_ID",
+				SecretValues: []api.SecretValue{{Name: "OIDC_CLIENT_ID", Path: "/etc/kubernetes/secrets/oidc/clientId"}},
+			}
+			c.Controller.CustomFiles = append(c.Controller.CustomFiles, api.CustomFile{Path: "/etc/kubernetes/secrets/oidc/clientId", Permissions: 0600, Content: "myclientid", Secret: true})
+			compiled, err := Compile(c, api.ClusterOptions{})
+			require.NoError(t, err)
+
+			ctx := &Context{ProvidedEncryptService: helper.DummyEncryptService{}}
+			_, err = ctx.GenerateAssetsOnDisk(compiled, dir, credential.GeneratorOptions{GenerateCA: true})
+			require.NoError(t, err)
+
+			require.NoError(t, ctx.EncryptSecrets(compiled, dir))
+
+			assert.True(t, compiled.Controller.CustomFiles[0].Encrypted())
+			assert.False(t, compiled.Controller.CustomFiles[1].Encrypted())
+			webhook := compiled.Experimental.Authentication.Webhook
+			assert.True(t, webhook.Encrypted)
+			// The dummy encrypt service returns the plaintext as the ciphertext
+			assert.Equal(t, webhookConfig, webhook.Config)
+			_, err = os.Stat(filepath.Join(dir, "secrets", "controller", "webhooks", "authentication.yaml.enc"))
+			assert.NoError(t, err, "the ciphertext of the webhook config must be cached")
+
+			opts := api.StackTemplateOptions{
+				AssetsDir:             dir,
+				ControllerTmplFile:    filepath.Join(pwd, "../../builtin/files/userdata/cloud-config-controller"),
+				StackTemplateTmplFile: filepath.Join(pwd, "../../builtin/files/stack-templates/control-plane.json.tmpl"),
+			}
+			assets, err := ctx.LoadCredentials(compiled, opts)
+			require.NoError(t, err)
+			stack, err := NewControlPlaneStack(compiled, opts, clusterextension.NewExtras(), assets)
+			require.NoError(t, err)
+
+			content, err := stack.UserData["Controller"].Parts[api.USERDATA_S3].Template()
+			require.NoError(t, err)
+			_, err = validate.Validate([]byte(content))
+			assert.NoError(t, err)
+			assert.Contains(t, content, "- path: /etc/kubernetes/webhooks/authentication.yaml.enc\n")
+			assert.Contains(t, content, "encKey=/etc/kubernetes/webhooks/authentication.yaml.enc\n")
+			assert.Contains(t, content, "encKey=/etc/app/token.enc\n")
+			assert.NotContains(t, content, "encKey=/etc/app/config.enc\n")
+			assert.Contains(t, content, "encKey=/etc/kubernetes/secrets/oidc/clientId.enc\n")
+			assert.Contains(t, content, "- --oidc-client-id=$OIDC_CLIENT_ID\n")
+			assert.Contains(t, content, `sed -i -e "s#\$OIDC_CLIENT_ID#${value}#g" /etc/kubernetes/manifests/kube-apiserver.yaml`)
+		})
+	})
+
+	t.Run("WithoutAssetsEncryption", func(t *testing.T) {
+		helper.WithTempDir(func(dir string) {
+			c := newCluster()
+			c.ManageCertificates = false
+			compiled, err := Compile(c, api.ClusterOptions{})
+			require.NoError(t, err)
+
+			ctx := &Context{ProvidedEncryptService: helper.DummyEncryptService{}}
+			assert.EqualError(t, ctx.EncryptSecrets(compiled, dir), "/etc/app/token: secrets can't be referenced without encrypting assets with KMS")
+		})
+	})
+
+	t.Run("Offline", func(t *testing.T) {
+		helper.WithTempDir(func(dir string) {
+			compiled, err := Compile(newCluster(), api.ClusterOptions{})
+			require.NoError(t, err)
+
+			ctx := &Context{Offline: true}
+			require.NoError(t, ctx.EncryptSecrets(compiled, dir))
+			assert.False(t, compiled.Controller.CustomFiles[0].Encrypted())
+			assert.False(t, compiled.Experimental.Authentication.Webhook.Encrypted)
+		})
+	})
+}
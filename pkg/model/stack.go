@@ -28,7 +28,7 @@ const STACK_TEMPLATE_FILENAME = "stack.json"
 
 // RenderAndAddUserData adds a userdata with the id that is loaded from the file located at `userdataTmplPath`.
 // When the id is "Controller", the loaded useradata can be referenced by `Userdata.Controller` in templates.
-func (s *Stack) RenderAndAddUserData(id, userdataTmplPath string) error {
+func (s *Stack) RenderAndAddUserData(id, userdataTmplPath string, opts ...api.UserDataOption) error {
 	var err error
 
 	id = strings.Title(id)
@@ -37,7 +37,7 @@ func (s *Stack) RenderAndAddUserData(id, userdataTmplPath string) error {
 		s.UserData = map[string]api.UserData{}
 	}
 
-	s.UserData[id], err = api.NewUserDataFromTemplateFile(userdataTmplPath, s.tmplCtx)
+	s.UserData[id], err = api.NewUserDataFromTemplateFile(userdataTmplPath, s.tmplCtx, opts...)
 
 	if err != nil {
 		return fmt.Errorf("failed to render userdata: %v", err)
@@ -50,6 +50,7 @@ func (p *Stack) RenderAddControllerUserdata(opts api.StackTemplateOptions) error
 	return p.RenderAndAddUserData(
 		"Controller",
 		p.ControllerTmplFile,
+		api.UserDataFormatOpt(p.Config.UserDataFormat),
 	)
 }
 
@@ -57,6 +58,7 @@ func (p *Stack) RenderAddEtcdUserdata(opts api.StackTemplateOptions) error {
 	return p.RenderAndAddUserData(
 		"Etcd",
 		p.EtcdTmplFile,
+		api.UserDataFormatOpt(p.Config.UserDataFormat),
 	)
 }
 
@@ -64,6 +66,7 @@ func (p *Stack) RenderAddWorkerUserdata(opts api.StackTemplateOptions) error {
 	return p.RenderAndAddUserData(
 		"Worker",
 		p.WorkerTmplFile,
+		api.UserDataFormatOpt(p.NodePoolConfig.UserDataFormat),
 	)
 }
 
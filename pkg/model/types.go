@@ -55,6 +55,9 @@ type Context struct {
 	ProvidedCFInterrogator  cfnstack.CFInterrogator
 	ProvidedEC2Interrogator cfnstack.EC2Interrogator
 	StackTemplateGetter     StackTemplateGetter
+
+	// Offline is true when stacks are rendered without accessing AWS, with placeholder credentials and no existing etcd state
+	Offline bool
 }
 
 // An EtcdTmplCtx contains configuration settings/options mixed with existing state in a way that can be
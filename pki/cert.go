@@ -11,6 +11,7 @@ import (
 // format for NotBefore and NotAfter fields to make output similar to openssl
 var ValidityFormat = "Jan _2 15:04:05 2006 MST"
 
+// Certificates are the certificates in a PEM file, printed by `kube-aws show certificates`
 type Certificates []Certificate
 
 // returns certificate that matches subject CN match regex (Subject.CommonName), if the certificate cannot be found,
@@ -26,12 +27,12 @@ func (cs Certificates) GetBySubjectCommonNamePattern(subjectCNMatch string) (cer
 }
 
 type Certificate struct {
-	Issuer      DN
-	NotBefore   time.Time
-	NotAfter    time.Time
-	Subject     DN
-	DNSNames    []string
-	IPAddresses []net.IP
+	Issuer      DN        `json:"issuer" yaml:"issuer"`
+	NotBefore   time.Time `json:"notBefore" yaml:"notBefore"`
+	NotAfter    time.Time `json:"notAfter" yaml:"notAfter"`
+	Subject     DN        `json:"subject" yaml:"subject"`
+	DNSNames    []string  `json:"dnsNames" yaml:"dnsNames"`
+	IPAddresses []net.IP  `json:"ipAddresses" yaml:"ipAddresses"`
 }
 
 func (c Certificate) IsExpired() bool {
@@ -76,8 +77,8 @@ func (c Certificate) String() string {
 }
 
 type DN struct {
-	Organization []string
-	CommonName   string
+	Organization []string `json:"organization" yaml:"organization"`
+	CommonName   string   `json:"commonName" yaml:"commonName"`
 }
 
 func (dn DN) String() string {
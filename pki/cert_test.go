@@ -1,6 +1,7 @@
 package pki
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"net"
@@ -46,6 +47,28 @@ func TestCertificateDoesNOTContainIPAddress(t *testing.T) {
 	assert.False(t, cert.ContainsIPAddress(localhost))
 }
 
+func TestCertificateJSON(t *testing.T) {
+
+	cert := Certificate{
+		Issuer:      DN{Organization: []string{"kube-aws"}, CommonName: "kube-ca"},
+		NotBefore:   time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Subject:     DN{CommonName: "kube-apiserver"},
+		DNSNames:    []string{"kubernetes"},
+		IPAddresses: []net.IP{net.IPv4(10, 3, 0, 1)},
+	}
+	data, err := json.Marshal(cert)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "issuer": {"organization": ["kube-aws"], "commonName": "kube-ca"},
+  "notBefore": "2019-01-01T00:00:00Z",
+  "notAfter": "2020-01-01T00:00:00Z",
+  "subject": {"organization": null, "commonName": "kube-apiserver"},
+  "dnsNames": ["kubernetes"],
+  "ipAddresses": ["10.3.0.1"]
+}`, string(data))
+}
+
 func TestCertificatesFromBytes(t *testing.T) {
 
 	cert1 := EncodeCertificatePEM(getSelfSignedCert(t, "test CN", "ABC organization"))
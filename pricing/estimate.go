@@ -0,0 +1,286 @@
+package pricing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"text/tabwriter"
+)
+
+const (
+	KindController   = "controller"
+	KindEtcd         = "etcd"
+	KindNodePool     = "nodePool"
+	KindNATGateway   = "natGateway"
+	KindLoadBalancer = "loadBalancer"
+)
+
+// Cluster is the set of billable resources in a cluster
+type Cluster struct {
+	Region               string
+	Groups               []Group
+	NATGateways          int
+	ClassicLoadBalancers int
+	NetworkLoadBalancers int
+}
+
+// InstanceTypes returns all the instance types used in the cluster
+func (c Cluster) InstanceTypes() []string {
+	types := []string{}
+	for _, g := range c.Groups {
+		if g.InstanceType != "" {
+			types = append(types, g.InstanceType)
+		}
+		for _, s := range g.SpotFleet {
+			types = append(types, s.InstanceType)
+		}
+	}
+	return types
+}
+
+// Group is a set of instances sharing the same configuration e.g. controller nodes, etcd nodes or a node pool
+type Group struct {
+	Name         string
+	Kind         string
+	InstanceType string
+	// MinCount and MaxCount are numbers of instances, or units of capacity for spot fleets
+	MinCount int
+	MaxCount int
+	// Volumes are the EBS volumes attached to each instance
+	Volumes []Volume
+	// SpotFleet is the list of instances any of which is launched to fulfill the capacity of a spot fleet
+	SpotFleet []SpotInstance
+}
+
+// SpotInstance is a launch specification of a spot fleet
+type SpotInstance struct {
+	InstanceType     string
+	WeightedCapacity int
+	// SpotPrice is the maximum hourly price per instance. The on-demand price is assumed when zero
+	SpotPrice float64
+	Volumes   []Volume
+}
+
+// Volume is an EBS volume
+type Volume struct {
+	Type string
+	Size int
+	IOPS int
+}
+
+// Cost is the range of a monthly cost
+type Cost struct {
+	Min float64 `json:"min" yaml:"min"`
+	Max float64 `json:"max" yaml:"max"`
+}
+
+func (c Cost) add(o Cost) Cost {
+	return Cost{Min: c.Min + o.Min, Max: c.Max + o.Max}
+}
+
+func (c Cost) String() string {
+	if c.Min == c.Max {
+		return fmt.Sprintf("%.2f", c.Min)
+	}
+	return fmt.Sprintf("%.2f-%.2f", c.Min, c.Max)
+}
+
+// Item is the monthly cost of a group of instances or other resources
+type Item struct {
+	Name         string `json:"name" yaml:"name"`
+	Kind         string `json:"kind" yaml:"kind"`
+	InstanceType string `json:"instanceType,omitempty" yaml:"instanceType,omitempty"`
+	MinCount     int    `json:"minCount" yaml:"minCount"`
+	MaxCount     int    `json:"maxCount" yaml:"maxCount"`
+	Compute      Cost   `json:"compute" yaml:"compute"`
+	Storage      Cost   `json:"storage" yaml:"storage"`
+	Total        Cost   `json:"total" yaml:"total"`
+}
+
+// Estimate is the monthly cost of a cluster broken down per group of resources
+type Estimate struct {
+	PricingVersion string `json:"pricingVersion" yaml:"pricingVersion"`
+	Region         string `json:"region" yaml:"region"`
+	Currency       string `json:"currency" yaml:"currency"`
+	Items          []Item `json:"items" yaml:"items"`
+	Total          Cost   `json:"total" yaml:"total"`
+}
+
+// Estimate computes the monthly cost of the cluster from the prices in the table
+func (t *Table) Estimate(c Cluster) (*Estimate, error) {
+	prices, err := t.Region(c.Region)
+	if err != nil {
+		return nil, err
+	}
+	e := &Estimate{
+		PricingVersion: t.Version,
+		Region:         c.Region,
+		Currency:       t.Currency,
+		Items:          []Item{},
+	}
+
+	for _, g := range c.Groups {
+		var item *Item
+		if len(g.SpotFleet) > 0 {
+			item, err = t.estimateSpotFleet(prices, g)
+		} else {
+			item, err = t.estimateGroup(prices, g)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate the cost of %s: %v", g.Name, err)
+		}
+		e.add(*item)
+	}
+
+	if c.NATGateways > 0 {
+		e.add(t.hourly("nat-gateways", KindNATGateway, c.NATGateways, prices.NATGateway))
+	}
+	if c.ClassicLoadBalancers > 0 {
+		e.add(t.hourly("classic-load-balancers", KindLoadBalancer, c.ClassicLoadBalancers, prices.ClassicLoadBalancer))
+	}
+	if c.NetworkLoadBalancers > 0 {
+		e.add(t.hourly("network-load-balancers", KindLoadBalancer, c.NetworkLoadBalancers, prices.NetworkLoadBalancer))
+	}
+
+	return e, nil
+}
+
+func (e *Estimate) add(item Item) {
+	e.Items = append(e.Items, item)
+	e.Total = e.Total.add(item.Total)
+}
+
+func (t *Table) hourly(name, kind string, count int, price float64) Item {
+	monthly := float64(count) * price * t.HoursPerMonth
+	return Item{
+		Name:     name,
+		Kind:     kind,
+		MinCount: count,
+		MaxCount: count,
+		Compute:  Cost{Min: monthly, Max: monthly},
+		Total:    Cost{Min: monthly, Max: monthly},
+	}
+}
+
+func (t *Table) estimateGroup(prices *RegionPrices, g Group) (*Item, error) {
+	instance, err := prices.instance(g.InstanceType)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := prices.storage(g.Volumes)
+	if err != nil {
+		return nil, err
+	}
+	item := &Item{
+		Name:         g.Name,
+		Kind:         g.Kind,
+		InstanceType: g.InstanceType,
+		MinCount:     g.MinCount,
+		MaxCount:     g.MaxCount,
+		Compute:      Cost{Min: float64(g.MinCount) * instance * t.HoursPerMonth, Max: float64(g.MaxCount) * instance * t.HoursPerMonth},
+		Storage:      Cost{Min: float64(g.MinCount) * storage, Max: float64(g.MaxCount) * storage},
+	}
+	item.Total = item.Compute.add(item.Storage)
+	return item, nil
+}
+
+// estimateSpotFleet assumes the whole capacity is fulfilled by the cheapest launch specification for the minimum cost,
+// and by the most expensive one for the maximum cost
+func (t *Table) estimateSpotFleet(prices *RegionPrices, g Group) (*Item, error) {
+	item := &Item{
+		Name:         g.Name,
+		Kind:         g.Kind,
+		InstanceType: g.SpotFleet[0].InstanceType,
+		MinCount:     g.MinCount,
+		MaxCount:     g.MaxCount,
+	}
+	for i, s := range g.SpotFleet {
+		onDemand, err := prices.instance(s.InstanceType)
+		if err != nil {
+			return nil, err
+		}
+		price := onDemand
+		if s.SpotPrice > 0 && s.SpotPrice < onDemand {
+			price = s.SpotPrice
+		}
+		storage, err := prices.storage(s.Volumes)
+		if err != nil {
+			return nil, err
+		}
+		weight := s.WeightedCapacity
+		if weight <= 0 {
+			weight = 1
+		}
+		instances := math.Ceil(float64(g.MaxCount) / float64(weight))
+		compute := instances * price * t.HoursPerMonth
+		storage = instances * storage
+
+		if i == 0 || compute+storage < item.Total.Min {
+			item.Compute.Min, item.Storage.Min, item.Total.Min = compute, storage, compute+storage
+		}
+		if i == 0 || compute+storage > item.Total.Max {
+			item.Compute.Max, item.Storage.Max, item.Total.Max = compute, storage, compute+storage
+		}
+	}
+	if len(g.SpotFleet) > 1 {
+		item.InstanceType = fmt.Sprintf("%s (+%d)", item.InstanceType, len(g.SpotFleet)-1)
+	}
+	return item, nil
+}
+
+func (p *RegionPrices) instance(instanceType string) (float64, error) {
+	price, ok := p.Instances[instanceType]
+	if !ok {
+		return 0, fmt.Errorf("no price for instance type %s in %s: add it to the pricing table or refresh the table", instanceType, p.Location)
+	}
+	return price, nil
+}
+
+// storage returns the monthly cost of the volumes
+func (p *RegionPrices) storage(volumes []Volume) (float64, error) {
+	total := 0.0
+	for _, v := range volumes {
+		price, ok := p.EBS[v.Type]
+		if !ok {
+			return 0, fmt.Errorf("no price for volume type %s in %s", v.Type, p.Location)
+		}
+		total += float64(v.Size) * price
+		if v.Type == "io1" {
+			total += float64(v.IOPS) * p.EBSIOPS
+		}
+	}
+	return total, nil
+}
+
+// JSON returns the estimate in JSON
+func (e *Estimate) JSON() (string, error) {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cost estimate: %v", err)
+	}
+	return string(data), nil
+}
+
+func (e *Estimate) String() string {
+	buf := new(bytes.Buffer)
+
+	w := new(tabwriter.Writer)
+	w.Init(buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "NAME\tKIND\tINSTANCE TYPE\tCOUNT\tCOMPUTE\tSTORAGE\tMONTHLY (%s)\n", e.Currency)
+	for _, i := range e.Items {
+		instanceType := i.InstanceType
+		if instanceType == "" {
+			instanceType = "-"
+		}
+		count := fmt.Sprintf("%d", i.MinCount)
+		if i.MinCount != i.MaxCount {
+			count = fmt.Sprintf("%d-%d", i.MinCount, i.MaxCount)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", i.Name, i.Kind, instanceType, count, i.Compute, i.Storage, i.Total)
+	}
+	w.Flush()
+
+	fmt.Fprintf(buf, "\nTotal: %s %s per month in %s, based on the on-demand prices as of %s\n", e.Total, e.Currency, e.Region, e.PricingVersion)
+	return buf.String()
+}
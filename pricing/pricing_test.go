@@ -0,0 +1,152 @@
+package pricing
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awspricing "github.com/aws/aws-sdk-go/service/pricing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTable = `
+version: "2019-01-01"
+hoursPerMonth: 100
+regions:
+  us-east-1:
+    location: US East (N. Virginia)
+    instances:
+      t3.medium: 0.05
+      m5.large: 0.1
+      m5.xlarge: 0.2
+    ebs:
+      gp2: 0.1
+      io1: 0.2
+    ebsIops: 0.01
+    natGateway: 0.04
+    classicLoadBalancer: 0.02
+    networkLoadBalancer: 0.03
+`
+
+func TestParseTable(t *testing.T) {
+	table, err := ParseTable([]byte(testTable))
+	require.NoError(t, err)
+
+	assert.Equal(t, "2019-01-01", table.Version)
+	assert.Equal(t, "USD", table.Currency)
+	assert.Equal(t, 100.0, table.HoursPerMonth)
+
+	_, err = table.Region("eu-west-1")
+	assert.Error(t, err)
+
+	_, err = ParseTable([]byte(`currency: USD`))
+	assert.Error(t, err, "a table without version should be rejected")
+}
+
+func TestDefaultTable(t *testing.T) {
+	table, err := DefaultTable()
+	require.NoError(t, err)
+
+	r, err := table.Region("us-east-1")
+	require.NoError(t, err)
+	assert.Contains(t, r.Instances, "t3.medium")
+	assert.Contains(t, r.EBS, "gp2")
+}
+
+func TestEstimate(t *testing.T) {
+	table, err := ParseTable([]byte(testTable))
+	require.NoError(t, err)
+
+	e, err := table.Estimate(Cluster{
+		Region: "us-east-1",
+		Groups: []Group{
+			{Name: "controller", Kind: KindController, InstanceType: "t3.medium", MinCount: 1, MaxCount: 2, Volumes: []Volume{{Type: "gp2", Size: 30}}},
+			{Name: "etcd", Kind: KindEtcd, InstanceType: "t3.medium", MinCount: 3, MaxCount: 3, Volumes: []Volume{{Type: "gp2", Size: 30}, {Type: "io1", Size: 10, IOPS: 100}}},
+			{Name: "spot", Kind: KindNodePool, MinCount: 4, MaxCount: 4, SpotFleet: []SpotInstance{
+				{InstanceType: "m5.large", WeightedCapacity: 1, SpotPrice: 0.05, Volumes: []Volume{{Type: "gp2", Size: 10}}},
+				{InstanceType: "m5.xlarge", WeightedCapacity: 2, Volumes: []Volume{{Type: "gp2", Size: 20}}},
+			}},
+		},
+		NATGateways:          2,
+		ClassicLoadBalancers: 1,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, e.Items, 5)
+
+	controller := e.Items[0]
+	assert.Equal(t, Cost{Min: 5, Max: 10}, controller.Compute)
+	assert.Equal(t, Cost{Min: 3, Max: 6}, controller.Storage)
+	assert.Equal(t, Cost{Min: 8, Max: 16}, controller.Total)
+
+	etcd := e.Items[1]
+	assert.InDelta(t, 15, etcd.Compute.Max, 0.0001)
+	// (30 * 0.1 + 10 * 0.2 + 100 * 0.01) * 3
+	assert.InDelta(t, 18, etcd.Storage.Max, 0.0001)
+
+	spot := e.Items[2]
+	// 4 m5.large at the spot price vs 2 m5.xlarge at the on-demand price
+	assert.InDelta(t, 24, spot.Total.Min, 0.0001)
+	assert.InDelta(t, 44, spot.Total.Max, 0.0001)
+	assert.Equal(t, "m5.large (+1)", spot.InstanceType)
+
+	assert.Equal(t, KindNATGateway, e.Items[3].Kind)
+	assert.InDelta(t, 8, e.Items[3].Total.Max, 0.0001)
+	assert.InDelta(t, 2, e.Items[4].Total.Max, 0.0001)
+
+	assert.InDelta(t, 8+33+24+8+2, e.Total.Min, 0.0001)
+	assert.InDelta(t, 16+33+44+8+2, e.Total.Max, 0.0001)
+
+	j, err := e.JSON()
+	require.NoError(t, err)
+	decoded := Estimate{}
+	require.NoError(t, json.Unmarshal([]byte(j), &decoded))
+	assert.Equal(t, "2019-01-01", decoded.PricingVersion)
+
+	assert.Contains(t, e.String(), "Total: 75.00-103.00 USD per month in us-east-1")
+
+	_, err = table.Estimate(Cluster{Region: "us-east-1", Groups: []Group{{Name: "unknown", InstanceType: "x1.32xlarge", MinCount: 1, MaxCount: 1}}})
+	assert.Error(t, err)
+}
+
+type dummyProductsService struct {
+	prices map[string]string
+}
+
+func (s dummyProductsService) GetProducts(input *awspricing.GetProductsInput) (*awspricing.GetProductsOutput, error) {
+	var instanceType string
+	for _, f := range input.Filters {
+		if aws.StringValue(f.Field) == "instanceType" {
+			instanceType = aws.StringValue(f.Value)
+		}
+	}
+	price, ok := s.prices[instanceType]
+	if !ok {
+		return &awspricing.GetProductsOutput{}, nil
+	}
+	product := aws.JSONValue{}
+	if err := json.Unmarshal([]byte(`{"terms":{"OnDemand":{"ABC.JRTCKXETXF":{"priceDimensions":{"ABC.JRTCKXETXF.6YS6EN2CT7":{"pricePerUnit":{"USD":"`+price+`"}}}}}}}`), &product); err != nil {
+		return nil, err
+	}
+	return &awspricing.GetProductsOutput{PriceList: []aws.JSONValue{product}}, nil
+}
+
+func TestRefresh(t *testing.T) {
+	table, err := ParseTable([]byte(testTable))
+	require.NoError(t, err)
+
+	svc := dummyProductsService{prices: map[string]string{
+		"t3.medium": "0.0416000000",
+		"m5.large":  "0.0960000000",
+		"m5.xlarge": "0.1920000000",
+		"c5.large":  "0.0850000000",
+	}}
+	require.NoError(t, table.Refresh(svc, []string{"c5.large"}, time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)))
+
+	assert.Equal(t, "2019-08-01", table.Version)
+	assert.Equal(t, map[string]float64{"t3.medium": 0.0416, "m5.large": 0.096, "m5.xlarge": 0.192, "c5.large": 0.085}, table.Regions["us-east-1"].Instances)
+
+	assert.Error(t, table.Refresh(svc, []string{"x1.32xlarge"}, time.Now()))
+}
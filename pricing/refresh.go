@@ -0,0 +1,110 @@
+package pricing
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awspricing "github.com/aws/aws-sdk-go/service/pricing"
+)
+
+// ProductsService is the subset of the AWS Price List API required to refresh a pricing table
+type ProductsService interface {
+	GetProducts(input *awspricing.GetProductsInput) (*awspricing.GetProductsOutput, error)
+}
+
+// Refresh retrieves the current on-demand prices of the instance types already in the table and `instanceTypes`
+// for every region in the table, and stamps the table with the date of `now`
+func (t *Table) Refresh(svc ProductsService, instanceTypes []string, now time.Time) error {
+	names := []string{}
+	for name := range t.Regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		r := t.Regions[name]
+		if r.Location == "" {
+			return fmt.Errorf("failed to refresh prices in %s: location is missing in the pricing table", name)
+		}
+		if r.Instances == nil {
+			r.Instances = map[string]float64{}
+		}
+
+		types := append([]string{}, instanceTypes...)
+		for it := range r.Instances {
+			types = append(types, it)
+		}
+		refreshed := map[string]bool{}
+		for _, it := range types {
+			if refreshed[it] {
+				continue
+			}
+			refreshed[it] = true
+			price, err := instancePrice(svc, r.Location, it, t.Currency)
+			if err != nil {
+				return fmt.Errorf("failed to refresh the price of %s in %s: %v", it, name, err)
+			}
+			r.Instances[it] = price
+		}
+	}
+
+	t.Version = now.Format("2006-01-02")
+	return nil
+}
+
+func instancePrice(svc ProductsService, location, instanceType, currency string) (float64, error) {
+	filters := map[string]string{
+		"location":        location,
+		"instanceType":    instanceType,
+		"operatingSystem": "Linux",
+		"tenancy":         "Shared",
+		"preInstalledSw":  "NA",
+		"capacitystatus":  "Used",
+	}
+	input := &awspricing.GetProductsInput{
+		ServiceCode: aws.String("AmazonEC2"),
+		MaxResults:  aws.Int64(1),
+	}
+	for f, v := range filters {
+		input.Filters = append(input.Filters, &awspricing.Filter{
+			Field: aws.String(f),
+			Type:  aws.String(awspricing.FilterTypeTermMatch),
+			Value: aws.String(v),
+		})
+	}
+	out, err := svc.GetProducts(input)
+	if err != nil {
+		return 0, err
+	}
+	if len(out.PriceList) == 0 {
+		return 0, fmt.Errorf("no product found")
+	}
+	return onDemandPrice(out.PriceList[0], currency)
+}
+
+// onDemandPrice extracts the price from a product in the AWS Price List API, which looks like:
+//
+//	{"terms": {"OnDemand": {"<offer>": {"priceDimensions": {"<rate>": {"pricePerUnit": {"USD": "0.0960000000"}}}}}}}
+func onDemandPrice(product aws.JSONValue, currency string) (float64, error) {
+	terms, _ := product["terms"].(map[string]interface{})
+	offers, _ := terms["OnDemand"].(map[string]interface{})
+	for _, o := range offers {
+		offer, _ := o.(map[string]interface{})
+		dimensions, _ := offer["priceDimensions"].(map[string]interface{})
+		for _, d := range dimensions {
+			dimension, _ := d.(map[string]interface{})
+			perUnit, _ := dimension["pricePerUnit"].(map[string]interface{})
+			if s, ok := perUnit[currency].(string); ok {
+				price, err := strconv.ParseFloat(s, 64)
+				if err != nil {
+					return 0, fmt.Errorf("invalid price %q: %v", s, err)
+				}
+				return price, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no on-demand price in %s found", currency)
+}
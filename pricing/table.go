@@ -0,0 +1,100 @@
+package pricing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/kubernetes-incubator/kube-aws/builtin"
+	"gopkg.in/yaml.v2"
+)
+
+// Table is a versioned list of on-demand prices used to estimate the cost of a cluster without calling AWS
+type Table struct {
+	// Version identifies the snapshot of the prices, usually the date they were retrieved
+	Version       string  `yaml:"version" json:"version"`
+	Currency      string  `yaml:"currency" json:"currency"`
+	HoursPerMonth float64 `yaml:"hoursPerMonth" json:"hoursPerMonth"`
+	// Regions maps region names like us-east-1 to the prices in them
+	Regions map[string]*RegionPrices `yaml:"regions" json:"regions"`
+}
+
+// RegionPrices is the prices in a region
+type RegionPrices struct {
+	// Location is the name of the region in the AWS Price List API e.g. "US East (N. Virginia)"
+	Location string `yaml:"location" json:"location"`
+	// Instances maps instance types to their hourly prices
+	Instances map[string]float64 `yaml:"instances" json:"instances"`
+	// EBS maps volume types to their prices per GB-month
+	EBS map[string]float64 `yaml:"ebs" json:"ebs"`
+	// EBSIOPS is the price per provisioned IOPS-month of io1 volumes
+	EBSIOPS float64 `yaml:"ebsIops" json:"ebsIops"`
+	// NATGateway is the hourly price of a NAT gateway
+	NATGateway float64 `yaml:"natGateway" json:"natGateway"`
+	// ClassicLoadBalancer is the hourly price of a classic ELB
+	ClassicLoadBalancer float64 `yaml:"classicLoadBalancer" json:"classicLoadBalancer"`
+	// NetworkLoadBalancer is the hourly price of a network load balancer
+	NetworkLoadBalancer float64 `yaml:"networkLoadBalancer" json:"networkLoadBalancer"`
+}
+
+// ParseTable parses a pricing table in YAML
+func ParseTable(data []byte) (*Table, error) {
+	t := &Table{}
+	if err := yaml.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing table: %v", err)
+	}
+	if t.Version == "" {
+		return nil, fmt.Errorf("invalid pricing table: version is missing")
+	}
+	if t.HoursPerMonth <= 0 {
+		t.HoursPerMonth = 730
+	}
+	if t.Currency == "" {
+		t.Currency = "USD"
+	}
+	return t, nil
+}
+
+// DefaultTable returns the pricing table bundled with kube-aws
+func DefaultTable() (*Table, error) {
+	return ParseTable(builtin.Bytes(builtin.PricingTableFile))
+}
+
+// LoadTable reads the pricing table at `path`, or returns the bundled one when `path` is empty
+func LoadTable(path string) (*Table, error) {
+	if path == "" {
+		return DefaultTable()
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing table %s: %v", path, err)
+	}
+	return ParseTable(data)
+}
+
+// Region returns the prices in the region
+func (t *Table) Region(name string) (*RegionPrices, error) {
+	r, ok := t.Regions[name]
+	if !ok || r == nil {
+		regions := []string{}
+		for n := range t.Regions {
+			regions = append(regions, n)
+		}
+		sort.Strings(regions)
+		return nil, fmt.Errorf("no prices for region %s in pricing table %s: available regions are %v", name, t.Version, regions)
+	}
+	return r, nil
+}
+
+// WriteToFile saves the pricing table in YAML
+func (t *Table) WriteToFile(path string) error {
+	data, err := yaml.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pricing table: %v", err)
+	}
+	if err := ioutil.WriteFile(path, data, os.FileMode(0644)); err != nil {
+		return fmt.Errorf("failed to write pricing table to %s: %v", path, err)
+	}
+	return nil
+}
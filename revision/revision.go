@@ -0,0 +1,262 @@
+package revision
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/kubernetes-incubator/kube-aws/cfnstack"
+)
+
+const (
+	metadataFilename = "revision.json"
+
+	// ClusterConfigFile is the name of the archived cluster.yaml in a revision
+	ClusterConfigFile = "cluster.yaml"
+	// StacksDir contains the archived assets of stacks, at StacksDir/<stack name>/<filename>
+	StacksDir = "stacks"
+	// PluginsDir contains the archived plugins directory
+	PluginsDir = "plugins"
+)
+
+// S3Service is the subset of the S3 API required to archive and read revisions
+type S3Service interface {
+	PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error)
+	GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error)
+	ListObjectsV2(input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error)
+}
+
+// Plugin is a plugin loaded when a revision was archived
+type Plugin struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+}
+
+// Revision describes a snapshot of the cluster archived on a successful apply
+type Revision struct {
+	Number            int       `json:"number" yaml:"number"`
+	CreatedAt         time.Time `json:"createdAt" yaml:"createdAt"`
+	ClusterName       string    `json:"clusterName" yaml:"clusterName"`
+	KubeAWSVersion    string    `json:"kubeAwsVersion" yaml:"kubeAwsVersion"`
+	KubernetesVersion string    `json:"kubernetesVersion" yaml:"kubernetesVersion"`
+	Targets           []string  `json:"targets" yaml:"targets"`
+	Plugins           []Plugin  `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	// RollbackOf is the number of the revision re-applied by `kube-aws rollback` to produce this revision, if any
+	RollbackOf int `json:"rollbackOf,omitempty" yaml:"rollbackOf,omitempty"`
+	// Redacted lists the paths of the archived files whose values resolved from secrets have been replaced with the references to them
+	Redacted []string `json:"redacted,omitempty" yaml:"redacted,omitempty"`
+	// Files lists the paths of the archived files relative to the revision
+	Files []string `json:"files" yaml:"files"`
+}
+
+// Store reads and writes numbered revisions under an S3 URI like s3://BUCKET/PREFIX/<number>/
+type Store struct {
+	s3     S3Service
+	bucket string
+	prefix string
+}
+
+// NewStore returns a store for revisions under `s3URI`
+func NewStore(s3Svc S3Service, s3URI string) (*Store, error) {
+	uri, err := cfnstack.S3URIFromString(s3URI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize revision store: %v", err)
+	}
+	prefix := strings.Join(uri.KeyComponents(), "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &Store{s3: s3Svc, bucket: uri.Bucket(), prefix: prefix}, nil
+}
+
+func (s *Store) key(number int, path string) string {
+	return fmt.Sprintf("%s%d/%s", s.prefix, number, path)
+}
+
+// Numbers returns the numbers of all the revisions in ascending order
+func (s *Store) Numbers() ([]int, error) {
+	numbers := []int{}
+	input := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.bucket),
+		Prefix:    aws.String(s.prefix),
+		Delimiter: aws.String("/"),
+	}
+	for {
+		out, err := s.s3.ListObjectsV2(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list revisions in s3://%s/%s: %v", s.bucket, s.prefix, err)
+		}
+		for _, p := range out.CommonPrefixes {
+			name := strings.TrimSuffix(strings.TrimPrefix(aws.StringValue(p.Prefix), s.prefix), "/")
+			if n, err := strconv.Atoi(name); err == nil {
+				numbers = append(numbers, n)
+			}
+		}
+		if out.NextContinuationToken == nil {
+			break
+		}
+		input.ContinuationToken = out.NextContinuationToken
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
+
+// List returns all the complete revisions in ascending order.
+// Revisions whose archival has been interrupted are omitted, as their metadata is written last.
+func (s *Store) List() ([]*Revision, error) {
+	numbers, err := s.Numbers()
+	if err != nil {
+		return nil, err
+	}
+	revisions := []*Revision{}
+	for _, n := range numbers {
+		data, err := s.get(s.key(n, metadataFilename))
+		if err != nil {
+			if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == s3.ErrCodeNoSuchKey {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read revision %d: %v", n, err)
+		}
+		r, err := parseRevision(n, data)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, nil
+}
+
+// Latest returns the most recent complete revision, or nil if there is none
+func (s *Store) Latest() (*Revision, error) {
+	revisions, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	return revisions[len(revisions)-1], nil
+}
+
+// Get returns the revision numbered `number`
+func (s *Store) Get(number int) (*Revision, error) {
+	data, err := s.get(s.key(number, metadataFilename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revision %d: %v", number, err)
+	}
+	return parseRevision(number, data)
+}
+
+func parseRevision(number int, data []byte) (*Revision, error) {
+	r := &Revision{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse revision %d: %v", number, err)
+	}
+	return r, nil
+}
+
+// ReadFile returns the content of a file archived in the revision
+func (s *Store) ReadFile(r *Revision, path string) (string, error) {
+	data, err := s.get(s.key(r.Number, path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s in revision %d: %v", path, r.Number, err)
+	}
+	return string(data), nil
+}
+
+// ReadFiles returns the contents of all the files archived in the revision keyed by their paths
+func (s *Store) ReadFiles(r *Revision) (map[string]string, error) {
+	files := map[string]string{}
+	for _, path := range r.Files {
+		content, err := s.ReadFile(r, path)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = content
+	}
+	return files, nil
+}
+
+// Save archives the files as a new revision numbered after the latest one, and returns the number.
+// The metadata is written after all the files so that an interrupted archival doesn't leave a revision behind.
+func (s *Store) Save(r *Revision, files map[string]string) (int, error) {
+	numbers, err := s.Numbers()
+	if err != nil {
+		return 0, err
+	}
+	r.Number = 1
+	if len(numbers) > 0 {
+		r.Number = numbers[len(numbers)-1] + 1
+	}
+
+	r.Files = []string{}
+	for path := range files {
+		r.Files = append(r.Files, path)
+	}
+	sort.Strings(r.Files)
+
+	for _, path := range r.Files {
+		if err := s.put(s.key(r.Number, path), []byte(files[path])); err != nil {
+			return 0, fmt.Errorf("failed to archive %s in revision %d: %v", path, r.Number, err)
+		}
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal revision %d: %v", r.Number, err)
+	}
+	if err := s.put(s.key(r.Number, metadataFilename), data); err != nil {
+		return 0, fmt.Errorf("failed to archive revision %d: %v", r.Number, err)
+	}
+	return r.Number, nil
+}
+
+// StackFiles returns the archived assets of stacks keyed by stack names and then filenames
+func StackFiles(files map[string]string) map[string]map[string]string {
+	stacks := map[string]map[string]string{}
+	for path, content := range files {
+		components := strings.SplitN(path, "/", 3)
+		if len(components) != 3 || components[0] != StacksDir {
+			continue
+		}
+		stack, filename := components[1], components[2]
+		if stacks[stack] == nil {
+			stacks[stack] = map[string]string{}
+		}
+		stacks[stack][filename] = content
+	}
+	return stacks
+}
+
+// StackFilePath returns the path of an asset of a stack in a revision
+func StackFilePath(stack, filename string) string {
+	return fmt.Sprintf("%s/%s/%s", StacksDir, stack, filename)
+}
+
+func (s *Store) get(key string) ([]byte, error) {
+	out, err := s.s3.GetObject(&s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return ioutil.ReadAll(out.Body)
+}
+
+func (s *Store) put(key string, data []byte) error {
+	_, err := s.s3.PutObject(&s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	return err
+}
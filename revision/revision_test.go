@@ -0,0 +1,127 @@
+package revision
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dummyS3Service struct {
+	objects map[string][]byte
+}
+
+func (s *dummyS3Service) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	data, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	s.objects[aws.StringValue(input.Bucket)+"/"+aws.StringValue(input.Key)] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (s *dummyS3Service) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	data, ok := s.objects[aws.StringValue(input.Bucket)+"/"+aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil)
+	}
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (s *dummyS3Service) ListObjectsV2(input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	prefix := aws.StringValue(input.Bucket) + "/" + aws.StringValue(input.Prefix)
+	found := map[string]bool{}
+	for k := range s.objects {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(k, prefix)
+		if i := strings.Index(rest, "/"); i >= 0 {
+			found[aws.StringValue(input.Prefix)+rest[:i+1]] = true
+		}
+	}
+	prefixes := []string{}
+	for p := range found {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+
+	// Return common prefixes one by one to exercise pagination
+	i := 0
+	if input.ContinuationToken != nil {
+		for i < len(prefixes) && prefixes[i] != aws.StringValue(input.ContinuationToken) {
+			i++
+		}
+	}
+	out := &s3.ListObjectsV2Output{}
+	if i < len(prefixes) {
+		out.CommonPrefixes = []*s3.CommonPrefix{{Prefix: aws.String(prefixes[i])}}
+	}
+	if i+1 < len(prefixes) {
+		out.NextContinuationToken = aws.String(prefixes[i+1])
+	}
+	return out, nil
+}
+
+func TestStore(t *testing.T) {
+	svc := &dummyS3Service{objects: map[string][]byte{}}
+	store, err := NewStore(svc, "s3://mybucket/mydir/kube-aws/clusters/mycluster/backup/revisions")
+	require.NoError(t, err)
+
+	latest, err := store.Latest()
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	created := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	n, err := store.Save(&Revision{ClusterName: "mycluster", CreatedAt: created, Targets: []string{"all"}}, map[string]string{
+		ClusterConfigFile:                        "clusterName: mycluster\n",
+		StackFilePath("mycluster", "stack.json"): `{"Resources":{}}`,
+		StackFilePath("Workers", "stack.json"):   `{"Resources":{"Workers":{}}}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Contains(t, svc.objects, "mybucket/mydir/kube-aws/clusters/mycluster/backup/revisions/1/revision.json")
+	assert.Contains(t, svc.objects, "mybucket/mydir/kube-aws/clusters/mycluster/backup/revisions/1/stacks/Workers/stack.json")
+
+	// A revision whose archival has been interrupted
+	svc.objects["mybucket/mydir/kube-aws/clusters/mycluster/backup/revisions/2/cluster.yaml"] = []byte("clusterName: mycluster\n")
+
+	n, err = store.Save(&Revision{ClusterName: "mycluster", CreatedAt: created.Add(time.Hour), RollbackOf: 1}, map[string]string{
+		ClusterConfigFile: "clusterName: mycluster\n",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, n, "a revision number should never be reused")
+
+	revisions, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, 1, revisions[0].Number)
+	assert.Equal(t, created, revisions[0].CreatedAt)
+	assert.Equal(t, []string{ClusterConfigFile, "stacks/Workers/stack.json", "stacks/mycluster/stack.json"}, revisions[0].Files)
+	assert.Equal(t, 3, revisions[1].Number)
+	assert.Equal(t, 1, revisions[1].RollbackOf)
+
+	latest, err = store.Latest()
+	require.NoError(t, err)
+	assert.Equal(t, 3, latest.Number)
+
+	r, err := store.Get(1)
+	require.NoError(t, err)
+	files, err := store.ReadFiles(r)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"mycluster": {"stack.json": `{"Resources":{}}`},
+		"Workers":   {"stack.json": `{"Resources":{"Workers":{}}}`},
+	}, StackFiles(files))
+
+	_, err = store.Get(2)
+	assert.Error(t, err)
+}
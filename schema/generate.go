@@ -0,0 +1,281 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Generator generates the schema of a YAML file from the Go struct it is unmarshalled into with yaml.v2.
+// Keys are named after yaml tags, inlined structs are flattened and untagged fields are named in lowercase as yaml.v2 does.
+type Generator struct {
+	// Strict rejects unknown keys in every object, as yaml.UnmarshalStrict does
+	Strict bool
+	// UnknownKeysTypes is the types of inline maps which collect unknown keys only to reject them later
+	UnknownKeysTypes []reflect.Type
+	// Enums maps fields in the form of `<type name>.<field name>` e.g. `RootVolume.Type` to their allowed values
+	Enums map[string][]string
+	// Defaults maps types to the default values used when their values are not given by the parent e.g. items of slices
+	Defaults map[reflect.Type]interface{}
+	// Overrides maps types unmarshalled in custom ways e.g. from strings to their schemas
+	Overrides map[reflect.Type]*Schema
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// unmarshaler is implemented by types unmarshalled from YAML in custom ways
+type unmarshaler interface {
+	UnmarshalYAML(unmarshal func(interface{}) error) error
+}
+
+var unmarshalerType = reflect.TypeOf((*unmarshaler)(nil)).Elem()
+
+// Generate returns the schema of the YAML file unmarshalled into `v`.
+// The values of the fields of `v` are included as defaults.
+func (g Generator) Generate(title string, v interface{}) *Schema {
+	s := g.schemaOf(reflect.TypeOf(v), reflect.ValueOf(v), map[reflect.Type]bool{})
+	s.Schema = Version
+	s.Title = title
+	return s
+}
+
+func (g Generator) schemaOf(t reflect.Type, v reflect.Value, visiting map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if v.IsValid() {
+			v = v.Elem()
+		}
+	}
+	if !v.IsValid() {
+		if d, ok := g.Defaults[t]; ok {
+			v = reflect.ValueOf(d)
+		}
+	}
+
+	if o, ok := g.Overrides[t]; ok {
+		s := *o
+		s.Default = g.plain(v)
+		return &s
+	}
+	if t == durationType {
+		return &Schema{Type: Types{TypeString, TypeInteger}}
+	}
+
+	s := &Schema{}
+	switch t.Kind() {
+	case reflect.String:
+		s.Type = Types{TypeString}
+	case reflect.Bool:
+		s.Type = Types{TypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = Types{TypeInteger}
+	case reflect.Float32, reflect.Float64:
+		s.Type = Types{TypeNumber}
+	case reflect.Slice, reflect.Array:
+		s.Type = Types{TypeArray}
+		s.Items = g.schemaOf(t.Elem(), reflect.Value{}, visiting)
+	case reflect.Map:
+		s.Type = Types{TypeObject}
+		s.AdditionalProperties = g.schemaOf(t.Elem(), reflect.Value{}, visiting)
+	case reflect.Struct:
+		if reflect.PtrTo(t).Implements(unmarshalerType) && !hasYAMLFields(t) {
+			// The YAML representation of the type is unknown
+			return s
+		}
+		if visiting[t] {
+			// Recursive types are not described further
+			return &Schema{Type: Types{TypeObject}}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s.Type = Types{TypeObject}
+		s.Properties = map[string]*Schema{}
+		s.Closed = g.Strict
+		g.addProperties(s, t, v, visiting)
+		return s
+	case reflect.Interface:
+		// Any value is allowed
+	}
+	if v.IsValid() && t.Kind() != reflect.Interface {
+		s.Default = g.plain(v)
+	}
+	return s
+}
+
+// addProperties adds the fields of the struct type `t` including the inlined ones to `s`
+func (g Generator) addProperties(s *Schema, t reflect.Type, v reflect.Value, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, inline, ok := yamlKey(f)
+		if !ok {
+			continue
+		}
+
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+
+		if !inline {
+			fs := g.schemaOf(f.Type, fv, visiting)
+			if values, ok := g.Enums[t.Name()+"."+f.Name]; ok {
+				for _, e := range values {
+					fs.Enum = append(fs.Enum, e)
+				}
+			}
+			s.Properties[name] = fs
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			if fv.IsValid() {
+				fv = fv.Elem()
+			}
+		}
+		switch ft.Kind() {
+		case reflect.Struct:
+			g.addProperties(s, ft, fv, visiting)
+		case reflect.Map:
+			if g.isUnknownKeys(ft) {
+				s.Closed = true
+			} else {
+				s.Closed = false
+				s.AdditionalProperties = g.schemaOf(ft.Elem(), reflect.Value{}, visiting)
+			}
+		}
+	}
+}
+
+func (g Generator) isUnknownKeys(t reflect.Type) bool {
+	for _, u := range g.UnknownKeysTypes {
+		if t == u {
+			return true
+		}
+	}
+	return false
+}
+
+// hasYAMLFields returns true when the struct type has any field unmarshalled from YAML
+func hasYAMLFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if _, _, ok := yamlKey(t.Field(i)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlKey returns the key of the field in YAML and whether the field is inlined, following the rules of yaml.v2
+func yamlKey(f reflect.StructField) (string, bool, bool) {
+	if f.PkgPath != "" && !f.Anonymous {
+		return "", false, false
+	}
+	tag := f.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	for _, flag := range parts[1:] {
+		if flag == "inline" {
+			return "", true, true
+		}
+	}
+	if f.PkgPath != "" {
+		return "", false, false
+	}
+	if parts[0] != "" {
+		return parts[0], false, true
+	}
+	return strings.ToLower(f.Name), false, true
+}
+
+// plain converts a Go value to the JSON value it is written as in YAML, or nil when it is empty
+func (g Generator) plain(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if _, ok := g.Overrides[v.Type()]; ok {
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+		return nil
+	}
+	if v.Type() == durationType {
+		if v.Int() == 0 {
+			return nil
+		}
+		return v.Interface().(time.Duration).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		m := map[string]interface{}{}
+		g.addPlainFields(m, v)
+		if len(m) == 0 {
+			return nil
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return nil
+		}
+		items := []interface{}{}
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, g.plain(v.Index(i)))
+		}
+		return items
+	case reflect.Map:
+		if v.Len() == 0 {
+			return nil
+		}
+		m := map[string]interface{}{}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface()) })
+		for _, k := range keys {
+			if pv := g.plain(v.MapIndex(k)); pv != nil {
+				m[fmt.Sprint(k.Interface())] = pv
+			}
+		}
+		return m
+	default:
+		if v.IsZero() {
+			return nil
+		}
+		return v.Interface()
+	}
+}
+
+func (g Generator) addPlainFields(m map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, inline, ok := yamlKey(t.Field(i))
+		if !ok {
+			continue
+		}
+		fv := v.Field(i)
+		if inline {
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				g.addPlainFields(m, fv)
+			}
+			continue
+		}
+		if pv := g.plain(fv); pv != nil {
+			m[name] = pv
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"encoding/json"
+)
+
+// Version is the JSON Schema draft the generated schemas conform to
+const Version = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a subset of JSON Schema enough to describe YAML files unmarshalled into Go structs
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        Types  `json:"type,omitempty"`
+	// Properties maps keys of an object to their schemas
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// AdditionalProperties is the schema of the values of the keys not in Properties, or nil when any value is allowed
+	AdditionalProperties *Schema `json:"-"`
+	// Closed is true when no keys other than Properties are allowed
+	Closed  bool          `json:"-"`
+	Items   *Schema       `json:"items,omitempty"`
+	Enum    []interface{} `json:"enum,omitempty"`
+	Default interface{}   `json:"default,omitempty"`
+}
+
+// Types is the list of the JSON types allowed for a value. A value of any type is allowed when empty
+type Types []string
+
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeObject  = "object"
+	TypeArray   = "array"
+)
+
+// MarshalJSON writes a single type as a string as most JSON Schemas do
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// MarshalJSON writes `additionalProperties` which is either false or a schema
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type t Schema
+	out := struct {
+		*t
+		AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	}{t: (*t)(s)}
+	if s.Closed {
+		out.AdditionalProperties = false
+	} else if s.AdditionalProperties != nil {
+		out.AdditionalProperties = s.AdditionalProperties
+	}
+	return json.Marshal(out)
+}
+
+// JSON returns the schema in indented JSON
+func (s *Schema) JSON() (string, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unknownKeys map[string]interface{}
+
+type cidr struct {
+	str string
+}
+
+func (c cidr) String() string {
+	return c.str
+}
+
+type opaque struct {
+	value string
+}
+
+func (o *opaque) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshal(&o.value)
+}
+
+type volume struct {
+	Type        string `yaml:"type,omitempty"`
+	Size        int    `yaml:"size,omitempty"`
+	unknownKeys `yaml:",inline"`
+}
+
+type pool struct {
+	Name    string            `yaml:"name"`
+	Volume  volume            `yaml:"volume,omitempty"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+	Timeout time.Duration     `yaml:"timeout"`
+}
+
+type common struct {
+	Region string `yaml:"region,omitempty"`
+}
+
+type config struct {
+	common      `yaml:",inline"`
+	Name        string  `yaml:"clusterName"`
+	Private     bool    `yaml:"private,omitempty"`
+	Ratio       float64 `yaml:"ratio,omitempty"`
+	Pools       []pool  `yaml:"pools,omitempty"`
+	CIDRs       []cidr  `yaml:"cidrs,omitempty"`
+	Count       *int    `yaml:"count,omitempty"`
+	Opaque      opaque  `yaml:"opaque,omitempty"`
+	Computed    string
+	Ignored     string `yaml:"-"`
+	unexported  string
+	unknownKeys `yaml:",inline"`
+}
+
+func testGenerator() Generator {
+	return Generator{
+		UnknownKeysTypes: []reflect.Type{reflect.TypeOf(unknownKeys{})},
+		Enums:            map[string][]string{"volume.Type": {"gp2", "io1"}},
+		Defaults:         map[reflect.Type]interface{}{reflect.TypeOf(pool{}): pool{Volume: volume{Type: "gp2", Size: 30}}},
+		Overrides:        map[reflect.Type]*Schema{reflect.TypeOf(cidr{}): {Type: Types{TypeString}}},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	s := testGenerator().Generate("cluster.yaml", config{common: common{Region: "us-west-1"}, CIDRs: []cidr{{"0.0.0.0/0"}}})
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	expected := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "cluster.yaml",
+  "type": "object",
+  "properties": {
+    "cidrs": {"type": "array", "items": {"type": "string"}, "default": ["0.0.0.0/0"]},
+    "clusterName": {"type": "string"},
+    "computed": {"type": "string"},
+    "count": {"type": "integer"},
+    "opaque": {},
+    "pools": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+          "name": {"type": "string"},
+          "timeout": {"type": ["string", "integer"]},
+          "volume": {
+            "type": "object",
+            "properties": {
+              "size": {"type": "integer", "default": 30},
+              "type": {"type": "string", "enum": ["gp2", "io1"], "default": "gp2"}
+            },
+            "additionalProperties": false
+          }
+        }
+      }
+    },
+    "private": {"type": "boolean"},
+    "ratio": {"type": "number"},
+    "region": {"type": "string", "default": "us-west-1"}
+  },
+  "additionalProperties": false
+}`
+	assert.JSONEq(t, expected, string(data))
+}
+
+func TestGenerateStrict(t *testing.T) {
+	g := testGenerator()
+	g.Strict = true
+	s := g.Generate("pool", pool{})
+
+	assert.True(t, s.Closed)
+	assert.True(t, s.Properties["volume"].Closed)
+	assert.False(t, s.Properties["labels"].Closed)
+}
+
+func TestValidateYAML(t *testing.T) {
+	s := testGenerator().Generate("cluster.yaml", config{})
+
+	violations, err := s.ValidateYAML([]byte(`
+clusterName: 123
+private: "yes"
+ratio: 1
+count: 1.5
+opaque: [1]
+region:
+pools:
+- name: pool1
+  timeout: 1h
+  labels:
+    role: worker
+    100: hundred
+  volume:
+    type: io2
+    iops: 100
+- name: pool2
+  labels: [a]
+typo: true
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"count: expected integer but was number",
+		"pools[0].volume.iops: unknown key",
+		`pools[0].volume.type: "io2" is not one of "gp2", "io1"`,
+		"pools[1].labels: expected object but was array",
+		"private: expected boolean but was string",
+		"typo: unknown key",
+	}, violations)
+
+	_, err = s.ValidateYAML([]byte("clusterName: [a"))
+	assert.Error(t, err)
+}
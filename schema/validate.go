@@ -0,0 +1,151 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ValidateYAML validates the YAML document against the schema, and returns the violations found with the paths to them.
+// Scalars are checked as leniently as yaml.v2 unmarshals them, so that e.g. numbers are accepted as strings and
+// null is accepted for any type.
+func (s *Schema) ValidateYAML(data []byte) ([]string, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %v", err)
+	}
+	violations := []string{}
+	s.validate("", doc, &violations)
+	return violations, nil
+}
+
+func (s *Schema) validate(path string, v interface{}, violations *[]string) {
+	if v == nil {
+		return
+	}
+
+	if len(s.Type) > 0 && !s.Type.allow(v) {
+		*violations = append(*violations, fmt.Sprintf("%s: expected %s but was %s", displayPath(path), strings.Join(s.Type, " or "), typeOf(v)))
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allowed := []string{}
+			for _, e := range s.Enum {
+				allowed = append(allowed, fmt.Sprintf("%q", e))
+			}
+			*violations = append(*violations, fmt.Sprintf("%s: %q is not one of %s", displayPath(path), fmt.Sprint(v), strings.Join(allowed, ", ")))
+		}
+	}
+
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		keys := map[string]interface{}{}
+		names := []string{}
+		for k := range value {
+			keys[fmt.Sprint(k)] = k
+			names = append(names, fmt.Sprint(k))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := value[keys[name]]
+			childPath := joinPath(path, name)
+			if p, ok := s.Properties[name]; ok {
+				p.validate(childPath, child, violations)
+			} else if s.Closed {
+				*violations = append(*violations, fmt.Sprintf("%s: unknown key", displayPath(childPath)))
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(childPath, child, violations)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range value {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+	}
+}
+
+// allow returns true when yaml.v2 can unmarshal the value into a field of any of the types
+func (t Types) allow(v interface{}) bool {
+	for _, typ := range t {
+		switch typ {
+		case TypeString:
+			switch v.(type) {
+			case string, int, int64, uint64, float64, bool:
+				return true
+			}
+		case TypeInteger:
+			switch n := v.(type) {
+			case int, int64, uint64:
+				return true
+			case float64:
+				if n == math.Trunc(n) {
+					return true
+				}
+			}
+		case TypeNumber:
+			switch v.(type) {
+			case int, int64, uint64, float64:
+				return true
+			}
+		case TypeBoolean:
+			if _, ok := v.(bool); ok {
+				return true
+			}
+		case TypeObject:
+			if _, ok := v.(map[interface{}]interface{}); ok {
+				return true
+			}
+		case TypeArray:
+			if _, ok := v.([]interface{}); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return TypeString
+	case int, int64, uint64:
+		return TypeInteger
+	case float64:
+		return TypeNumber
+	case bool:
+		return TypeBoolean
+	case map[interface{}]interface{}:
+		return TypeObject
+	case []interface{}:
+		return TypeArray
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
@@ -0,0 +1,198 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Store looks up the values of the secrets referenced from cluster.yaml
+type Store interface {
+	// Parameter returns the decrypted value of the SSM parameter named `name`
+	Parameter(name string) (string, error)
+	// Secret returns the secret string of the Secrets Manager secret identified by `id`
+	Secret(id string) (string, error)
+}
+
+// Resolved is a value in cluster.yaml which has been resolved from secrets
+type Resolved struct {
+	// Path is the path to the value in cluster.yaml e.g. `worker.nodePools[0].customFiles[1].content`
+	Path string
+	// Value is the resolved value
+	Value string
+	// References are the references resolved to produce the value e.g. `{{ssm "/mycluster/token"}}`
+	References []string
+}
+
+// reference matches `{{ssm "NAME"}}`, `{{secretsmanager "ID"}}` and `{{secretsmanager "ID" "KEY"}}`
+var reference = regexp.MustCompile(`\{\{\s*(ssm|secretsmanager)((?:\s+"(?:[^"\\]|\\.)*")+)\s*\}\}`)
+
+var referenceArg = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// referenceStart matches the start of a reference, which may be escaped in a double-quoted YAML string
+var referenceStart = regexp.MustCompile(`\{\{\s*(ssm|secretsmanager)\s`)
+
+// HasReferences returns true when `data` may contain references to secrets
+func HasReferences(data []byte) bool {
+	return referenceStart.Match(data)
+}
+
+// PlaceholderValue replaces every reference to secrets in ResolveWithPlaceholders
+const PlaceholderValue = "placeholder"
+
+// Resolve replaces every reference to secrets in the string values of the YAML document with the values looked up from `store`,
+// and returns the resolved document along with the values resolved. `data` is returned as-is when it contains no references.
+func Resolve(data []byte, store Store) ([]byte, []Resolved, error) {
+	r := &resolver{store: store, cache: map[string]string{}}
+	return r.resolve(data)
+}
+
+// ResolveWithPlaceholders replaces every reference to secrets with PlaceholderValue, so that cluster.yaml can be loaded without accessing AWS
+func ResolveWithPlaceholders(data []byte) ([]byte, []Resolved, error) {
+	r := &resolver{placeholder: true}
+	return r.resolve(data)
+}
+
+type resolver struct {
+	store       Store
+	cache       map[string]string
+	placeholder bool
+	resolved    []Resolved
+}
+
+func (r *resolver) resolve(data []byte) ([]byte, []Resolved, error) {
+	r.resolved = []Resolved{}
+	if !HasReferences(data) {
+		return data, r.resolved, nil
+	}
+
+	doc := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(data, doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse yaml: %v", err)
+	}
+
+	if err := r.walk(doc, ""); err != nil {
+		return nil, nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	enc := yamlv3.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode yaml: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode yaml: %v", err)
+	}
+	return buf.Bytes(), r.resolved, nil
+}
+
+func (r *resolver) walk(n *yamlv3.Node, path string) error {
+	switch n.Kind {
+	case yamlv3.DocumentNode:
+		for _, c := range n.Content {
+			if err := r.walk(c, path); err != nil {
+				return err
+			}
+		}
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			p := n.Content[i].Value
+			if path != "" {
+				p = path + "." + p
+			}
+			if err := r.walk(n.Content[i+1], p); err != nil {
+				return err
+			}
+		}
+	case yamlv3.SequenceNode:
+		for i, c := range n.Content {
+			if err := r.walk(c, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case yamlv3.ScalarNode:
+		if !reference.MatchString(n.Value) {
+			return nil
+		}
+		refs := reference.FindAllString(n.Value, -1)
+		var err error
+		value := reference.ReplaceAllStringFunc(n.Value, func(ref string) string {
+			if err != nil {
+				return ""
+			}
+			var v string
+			v, err = r.lookup(ref)
+			return v
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		n.Value = value
+		n.Tag = "!!str"
+		n.Style = yamlv3.DoubleQuotedStyle
+		r.resolved = append(r.resolved, Resolved{Path: path, Value: value, References: refs})
+	}
+	return nil
+}
+
+// lookup returns the value of the reference `ref`, looking up each secret at most once
+func (r *resolver) lookup(ref string) (string, error) {
+	m := reference.FindStringSubmatch(ref)
+	source := m[1]
+	args := []string{}
+	for _, a := range referenceArg.FindAllString(m[2], -1) {
+		s, err := strconv.Unquote(a)
+		if err != nil {
+			return "", fmt.Errorf("invalid argument %s in %s: %v", a, ref, err)
+		}
+		args = append(args, s)
+	}
+
+	switch {
+	case source == "ssm" && len(args) != 1:
+		return "", fmt.Errorf("invalid reference %s: ssm takes the name of a parameter", ref)
+	case source == "secretsmanager" && len(args) > 2:
+		return "", fmt.Errorf("invalid reference %s: secretsmanager takes the id of a secret and optionally a key in it", ref)
+	}
+
+	if r.placeholder {
+		return PlaceholderValue, nil
+	}
+
+	cacheKey := source + "\x00" + args[0]
+	v, ok := r.cache[cacheKey]
+	if !ok {
+		var err error
+		if source == "ssm" {
+			v, err = r.store.Parameter(args[0])
+		} else {
+			v, err = r.store.Secret(args[0])
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %v", ref, err)
+		}
+		r.cache[cacheKey] = v
+	}
+
+	if source == "secretsmanager" && len(args) == 2 {
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(v), &fields); err != nil {
+			return "", fmt.Errorf("failed to resolve %s: the secret is not a JSON object: %v", ref, err)
+		}
+		f, ok := fields[args[1]]
+		if !ok {
+			return "", fmt.Errorf("failed to resolve %s: key %q not found in the secret", ref, args[1])
+		}
+		s, ok := f.(string)
+		if !ok {
+			return "", fmt.Errorf("failed to resolve %s: the value of key %q is not a string", ref, args[1])
+		}
+		return s, nil
+	}
+	return v, nil
+}
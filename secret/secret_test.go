@@ -0,0 +1,117 @@
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	store := &MemoryStore{
+		Parameters: map[string]string{
+			"/mycluster/oidc/client-id": "kube-aws",
+			"/mycluster/token":          "s3cr3t'\n",
+		},
+		Secrets: map[string]string{
+			"mycluster/webhook": `{"config": "YXBpVmVyc2lvbjogdjEK", "port": 443}`,
+		},
+	}
+
+	testCases := []struct {
+		context  string
+		input    string
+		expected string
+		resolved []Resolved
+		err      string
+	}{
+		{
+			context: "WithoutReferences",
+			input: `clusterName: mycluster # {{ssm}}
+customFiles:
+- template: "{{.ClusterName}}"
+`,
+			expected: `clusterName: mycluster # {{ssm}}
+customFiles:
+- template: "{{.ClusterName}}"
+`,
+			resolved: []Resolved{},
+		},
+		{
+			context: "WithReferences",
+			input: `clusterName: mycluster
+oidc:
+  clientId: '{{ssm "/mycluster/oidc/client-id"}}'
+  issuerUrl: "https://{{ ssm \"/mycluster/oidc/client-id\" }}.example.com"
+experimental:
+  authentication:
+    webhook:
+      configBase64: '{{secretsmanager "mycluster/webhook" "config"}}'
+controller:
+  customFiles:
+  - path: /etc/token
+    content: '{{ssm "/mycluster/token"}}'
+`,
+			expected: `clusterName: mycluster
+oidc:
+  clientId: "kube-aws"
+  issuerUrl: "https://kube-aws.example.com"
+experimental:
+  authentication:
+    webhook:
+      configBase64: "YXBpVmVyc2lvbjogdjEK"
+controller:
+  customFiles:
+    - path: /etc/token
+      content: "s3cr3t'\n"
+`,
+			resolved: []Resolved{
+				{Path: "oidc.clientId", Value: "kube-aws", References: []string{`{{ssm "/mycluster/oidc/client-id"}}`}},
+				{Path: "oidc.issuerUrl", Value: "https://kube-aws.example.com", References: []string{`{{ ssm "/mycluster/oidc/client-id" }}`}},
+				{Path: "experimental.authentication.webhook.configBase64", Value: "YXBpVmVyc2lvbjogdjEK", References: []string{`{{secretsmanager "mycluster/webhook" "config"}}`}},
+				{Path: "controller.customFiles[0].content", Value: "s3cr3t'\n", References: []string{`{{ssm "/mycluster/token"}}`}},
+			},
+		},
+		{
+			context: "MissingParameter",
+			input:   `clusterName: '{{ssm "/missing"}}'`,
+			err:     `clusterName: failed to resolve {{ssm "/missing"}}: parameter /missing not found`,
+		},
+		{
+			context: "MissingKey",
+			input:   `clusterName: '{{secretsmanager "mycluster/webhook" "user"}}'`,
+			err:     `clusterName: failed to resolve {{secretsmanager "mycluster/webhook" "user"}}: key "user" not found in the secret`,
+		},
+		{
+			context: "NonStringKey",
+			input:   `clusterName: '{{secretsmanager "mycluster/webhook" "port"}}'`,
+			err:     `clusterName: failed to resolve {{secretsmanager "mycluster/webhook" "port"}}: the value of key "port" is not a string`,
+		},
+		{
+			context: "TooManyArguments",
+			input:   `clusterName: '{{ssm "/mycluster/token" "key"}}'`,
+			err:     `clusterName: invalid reference {{ssm "/mycluster/token" "key"}}: ssm takes the name of a parameter`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.context, func(t *testing.T) {
+			out, resolved, err := Resolve([]byte(tc.input), store)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+			assert.Equal(t, tc.resolved, resolved)
+		})
+	}
+}
+
+func TestResolveWithPlaceholders(t *testing.T) {
+	out, resolved, err := ResolveWithPlaceholders([]byte(`clientId: '{{secretsmanager "mycluster/oidc" "clientId"}}'` + "\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "clientId: \"placeholder\"\n", string(out))
+	assert.Equal(t, []Resolved{{Path: "clientId", Value: PlaceholderValue, References: []string{`{{secretsmanager "mycluster/oidc" "clientId"}}`}}}, resolved)
+}
@@ -0,0 +1,77 @@
+package secret
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// SSMService is the subset of the SSM API required to look up parameters
+type SSMService interface {
+	GetParameter(input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error)
+}
+
+// SecretsManagerService is the subset of the Secrets Manager API required to look up secrets
+type SecretsManagerService interface {
+	GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// AWSStore looks up secrets from SSM Parameter Store and Secrets Manager
+type AWSStore struct {
+	SSM            SSMService
+	SecretsManager SecretsManagerService
+}
+
+// NewAWSStore returns the store looking up secrets with the session
+func NewAWSStore(sess *session.Session) *AWSStore {
+	return &AWSStore{SSM: ssm.New(sess), SecretsManager: secretsmanager.New(sess)}
+}
+
+func (s *AWSStore) Parameter(name string) (string, error) {
+	out, err := s.SSM.GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get parameter %s: %v", name, err)
+	}
+	return aws.StringValue(out.Parameter.Value), nil
+}
+
+func (s *AWSStore) Secret(id string) (string, error) {
+	out, err := s.SecretsManager.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(id),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %v", id, err)
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("secret %s is binary. Only secret strings can be referenced", id)
+	}
+	return aws.StringValue(out.SecretString), nil
+}
+
+// MemoryStore is the store holding secrets in memory, mainly for testing
+type MemoryStore struct {
+	Parameters map[string]string
+	Secrets    map[string]string
+}
+
+func (s *MemoryStore) Parameter(name string) (string, error) {
+	v, ok := s.Parameters[name]
+	if !ok {
+		return "", fmt.Errorf("parameter %s not found", name)
+	}
+	return v, nil
+}
+
+func (s *MemoryStore) Secret(id string) (string, error) {
+	v, ok := s.Secrets[id]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", id)
+	}
+	return v, nil
+}
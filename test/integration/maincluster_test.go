@@ -1727,6 +1727,22 @@ worker:
 				hasWorkerAndNodePoolStrategy("Sequential", "Parallel"),
 			},
 		},
+		{
+			context: "WithCanaryAndBatchedNodePoolStrategy",
+			configYaml: minimalValidConfigYaml + `
+worker:
+  nodePoolRollingStrategy: Canary
+  nodePoolRollingCanary: pool1
+  nodePoolRollingMaxParallel: 2
+  nodePools:
+  - name: pool1
+  - name: pool2
+    nodePoolRollingStrategy: Batched
+`,
+			assertConfig: []ConfigTester{
+				hasWorkerAndNodePoolStrategy("Canary", "Batched"),
+			},
+		},
 		{
 			context:    "WithMinimalValidConfig",
 			configYaml: minimalValidConfigYaml,
@@ -0,0 +1,61 @@
+package upgrade
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver"
+)
+
+// minimumEtcdVersions lists the oldest etcd version supported by each Kubernetes minor version, oldest first.
+// A Kubernetes version requires the etcd version of the last entry not newer than it.
+var minimumEtcdVersions = []struct {
+	kubernetes string
+	etcd       string
+}{
+	// etcd2 is no longer supported as a storage backend
+	{kubernetes: "1.13.0", etcd: "3.0.0"},
+	{kubernetes: "1.14.0", etcd: "3.2.18"},
+}
+
+// ValidateSkew checks that upgrading Kubernetes from `from` to `to` on top of etcd `etcdVersion` conforms to the version skew policy.
+// Kubernetes supports upgrading a minor version at a time, and never downgrading.
+func ValidateSkew(from, to, etcdVersion string) error {
+	current, err := semver.NewVersion(from)
+	if err != nil {
+		return fmt.Errorf("invalid current kubernetes version %s: %v", from, err)
+	}
+	desired, err := semver.NewVersion(to)
+	if err != nil {
+		return fmt.Errorf("invalid kubernetes version %s: %v", to, err)
+	}
+	etcd, err := semver.NewVersion(etcdVersion)
+	if err != nil {
+		return fmt.Errorf("invalid etcd version %s: %v", etcdVersion, err)
+	}
+
+	if desired.Equal(current) {
+		return fmt.Errorf("the cluster is already running kubernetes %s", from)
+	}
+	if desired.LessThan(current) {
+		return fmt.Errorf("downgrading kubernetes from %s to %s is not supported", from, to)
+	}
+	if desired.Major() != current.Major() {
+		return fmt.Errorf("upgrading kubernetes across major versions from %s to %s is not supported", from, to)
+	}
+	if desired.Minor() > current.Minor()+1 {
+		return fmt.Errorf("kubernetes supports upgrading a minor version at a time: upgrade from %s to v%d.%d.x first", from, current.Major(), current.Minor()+1)
+	}
+
+	required := ""
+	for _, v := range minimumEtcdVersions {
+		if desired.LessThan(semver.MustParse(v.kubernetes)) {
+			break
+		}
+		required = v.etcd
+	}
+	if required != "" && etcd.LessThan(semver.MustParse(required)) {
+		return fmt.Errorf("kubernetes %s requires etcd %s or later but the cluster is running etcd %s: upgrade etcd first", to, required, etcdVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"time"
+)
+
+// StateFile is the file recording the progress of an upgrade, from which a paused or failed upgrade is resumed
+const StateFile = "upgrade-state.json"
+
+// Phase is a set of sub-stacks updated at once, e.g. `etcd`, `control-plane` or a node pool
+type Phase struct {
+	Name    string   `json:"name"`
+	Targets []string `json:"targets"`
+}
+
+// Phases returns the phases to upgrade a cluster in order.
+// Etcd is updated first and the control plane next so that kubelets never run a version newer than the apiservers.
+func Phases(etcdStackName, controlPlaneStackName string, nodePoolNames []string) []Phase {
+	phases := []Phase{
+		{Name: etcdStackName, Targets: []string{etcdStackName}},
+		{Name: controlPlaneStackName, Targets: []string{controlPlaneStackName}},
+	}
+	for _, np := range nodePoolNames {
+		phases = append(phases, Phase{Name: np, Targets: []string{np}})
+	}
+	return phases
+}
+
+// State is the progress of an upgrade
+type State struct {
+	ClusterName string    `json:"clusterName"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	StartedAt   time.Time `json:"startedAt"`
+	Phases      []Phase   `json:"phases"`
+	// Completed is the names of the phases which have been applied and passed the health check
+	Completed []string `json:"completed"`
+}
+
+// NewState returns the state of an upgrade which is about to start
+func NewState(clusterName, from, to string, phases []Phase, now time.Time) *State {
+	return &State{
+		ClusterName: clusterName,
+		From:        from,
+		To:          to,
+		StartedAt:   now,
+		Phases:      phases,
+		Completed:   []string{},
+	}
+}
+
+// ReadState reads the state of the upgrade in progress, or returns nil when there's none
+func ReadState(path string) (*State, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upgrade state %s: %v", path, err)
+	}
+	s := &State{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse upgrade state %s: %v", path, err)
+	}
+	return s, nil
+}
+
+// WriteToFile saves the state so that the upgrade can be resumed later
+func (s *State) WriteToFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal upgrade state: %v", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write upgrade state %s: %v", path, err)
+	}
+	return nil
+}
+
+// Next returns the first phase not completed yet, or nil when all the phases have been completed
+func (s *State) Next() *Phase {
+	done := map[string]bool{}
+	for _, name := range s.Completed {
+		done[name] = true
+	}
+	for i := range s.Phases {
+		if !done[s.Phases[i].Name] {
+			return &s.Phases[i]
+		}
+	}
+	return nil
+}
+
+// Complete marks the phase as completed
+func (s *State) Complete(name string) {
+	s.Completed = append(s.Completed, name)
+}
+
+var kubernetesVersionLine = regexp.MustCompile(`(?m)^kubernetesVersion:.*$`)
+
+// SetKubernetesVersion rewrites `kubernetesVersion` in cluster.yaml without touching the rest of the file including comments
+func SetKubernetesVersion(clusterYaml []byte, version string) []byte {
+	line := []byte("kubernetesVersion: " + version)
+	if kubernetesVersionLine.Match(clusterYaml) {
+		return kubernetesVersionLine.ReplaceAllLiteral(clusterYaml, line)
+	}
+	out := append([]byte{}, clusterYaml...)
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		out = append(out, '\n')
+	}
+	return append(append(out, line...), '\n')
+}
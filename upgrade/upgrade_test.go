@@ -0,0 +1,84 @@
+package upgrade
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSkew(t *testing.T) {
+	testCases := []struct {
+		from, to, etcd string
+		err            string
+	}{
+		{from: "v1.13.5", to: "v1.14.1", etcd: "3.3.10"},
+		{from: "v1.14.1", to: "v1.14.3", etcd: "3.3.10"},
+		{from: "1.12.3", to: "v1.13.0", etcd: "3.2.24"},
+		{from: "v1.14.1", to: "v1.14.1", etcd: "3.3.10", err: "already running"},
+		{from: "v1.14.1", to: "v1.13.5", etcd: "3.3.10", err: "downgrading"},
+		{from: "v1.13.5", to: "v2.0.0", etcd: "3.3.10", err: "across major versions"},
+		{from: "v1.13.5", to: "v1.15.0", etcd: "3.3.10", err: "upgrade from v1.13.5 to v1.14.x first"},
+		{from: "v1.13.5", to: "v1.14.1", etcd: "3.2.11", err: "requires etcd 3.2.18 or later"},
+		{from: "v1.13.5", to: "latest", etcd: "3.3.10", err: "invalid kubernetes version"},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateSkew(tc.from, tc.to, tc.etcd)
+		if tc.err == "" {
+			assert.NoError(t, err, "%s to %s with etcd %s", tc.from, tc.to, tc.etcd)
+			continue
+		}
+		if assert.Error(t, err, "%s to %s with etcd %s", tc.from, tc.to, tc.etcd) {
+			assert.Contains(t, err.Error(), tc.err)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube-aws-upgrade")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, StateFile)
+
+	s, err := ReadState(path)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	phases := Phases("etcd", "control-plane", []string{"pool1", "pool2"})
+	assert.Equal(t, []Phase{
+		{Name: "etcd", Targets: []string{"etcd"}},
+		{Name: "control-plane", Targets: []string{"control-plane"}},
+		{Name: "pool1", Targets: []string{"pool1"}},
+		{Name: "pool2", Targets: []string{"pool2"}},
+	}, phases)
+
+	s = NewState("mycluster", "v1.13.5", "v1.14.1", phases, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.Equal(t, "etcd", s.Next().Name)
+	s.Complete("etcd")
+	s.Complete("control-plane")
+	require.NoError(t, s.WriteToFile(path))
+
+	s, err = ReadState(path)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.14.1", s.To)
+	assert.Equal(t, "pool1", s.Next().Name)
+	s.Complete("pool1")
+	s.Complete("pool2")
+	assert.Nil(t, s.Next())
+}
+
+func TestSetKubernetesVersion(t *testing.T) {
+	assert.Equal(t,
+		"clusterName: mycluster\n# kubernetesVersion: v1.12.0\nkubernetesVersion: v1.14.1\nworker:\n  nodePools:\n  - name: pool1\n",
+		string(SetKubernetesVersion([]byte("clusterName: mycluster\n# kubernetesVersion: v1.12.0\nkubernetesVersion: v1.13.5\nworker:\n  nodePools:\n  - name: pool1\n"), "v1.14.1")),
+	)
+	assert.Equal(t,
+		"clusterName: mycluster\nkubernetesVersion: v1.14.1\n",
+		string(SetKubernetesVersion([]byte("clusterName: mycluster"), "v1.14.1")),
+	)
+}